@@ -1,5 +1,29 @@
 // Service Worker for Web Push Notifications
 
+// Report delivered/displayed/clicked/closed events to the server.
+// The server adds { jobId, subscriptionId } to every payload as "tracking".
+const trackEvent = (tracking, eventName, url) => {
+  if (!tracking || !tracking.jobId) {
+    return Promise.resolve();
+  }
+
+  return fetch('/api/push/events', {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({
+      event: eventName,
+      jobId: tracking.jobId,
+      subscriptionId: tracking.subscriptionId,
+      url: url,
+      timestamp: Date.now()
+    })
+  }).catch(error => {
+    console.error(`Failed to track ${eventName}:`, error);
+  });
+};
+
 // Push event handler
 self.addEventListener('push', (event) => {
   const options = {
@@ -28,7 +52,10 @@ self.addEventListener('push', (event) => {
         body: payload.body || options.body,
         icon: payload.icon || options.icon,
         tag: payload.tag || options.tag,
-        data: payload.data || options.data,
+        data: Object.assign({}, payload.data || options.data, {
+          url: payload.url || payload.data?.url,
+          tracking: payload.tracking
+        }),
         actions: payload.actions || options.actions
       });
     } catch (error) {
@@ -36,14 +63,24 @@ self.addEventListener('push', (event) => {
     }
   }
 
+  const tracking = payload?.tracking;
   event.waitUntil(
-    self.registration.showNotification(
-      payload?.title || 'New Notification',
-      options
-    )
+    Promise.all([
+      trackEvent(tracking, 'delivered'),
+      self.registration.showNotification(
+        payload?.title || 'New Notification',
+        options
+      ).then(() => trackEvent(tracking, 'displayed'))
+    ])
   );
 });
 
+// Notification close handler
+self.addEventListener('notificationclose', (event) => {
+  const notificationData = event.notification.data || {};
+  event.waitUntil(trackEvent(notificationData.tracking, 'closed'));
+});
+
 // Notification click handler  
 self.addEventListener('notificationclick', (event) => {
   event.notification.close();
@@ -52,12 +89,14 @@ self.addEventListener('notificationclick', (event) => {
   const notificationData = event.notification.data || {};
   
   if (clickAction === 'close') {
+    event.waitUntil(trackEvent(notificationData.tracking, 'closed'));
     return;
   }
 
   const urlToOpen = notificationData.url || '/';
   
-  event.waitUntil(
+  event.waitUntil(Promise.all([
+    trackEvent(notificationData.tracking, 'clicked', urlToOpen),
     clients.matchAll({ type: 'window', includeUncontrolled: true })
       .then((clientList) => {
         // Try to focus existing tab
@@ -72,24 +111,7 @@ self.addEventListener('notificationclick', (event) => {
           return clients.openWindow(urlToOpen);
         }
       })
-  );
-
-  // Track notification click
-  if (notificationData.trackingId) {
-    fetch('/api/push/click', {
-      method: 'POST',
-      headers: {
-        'Content-Type': 'application/json',
-      },
-      body: JSON.stringify({
-        trackingId: notificationData.trackingId,
-        action: clickAction,
-        timestamp: new Date().toISOString()
-      })
-    }).catch(error => {
-      console.error('Failed to track click:', error);
-    });
-  }
+  ]));
 });
 
 // Install event handler
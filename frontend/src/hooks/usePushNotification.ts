@@ -122,7 +122,7 @@ export function usePushNotification() {
       await state.subscription.unsubscribe();
 
       // Notify server
-      await pushAPI.unsubscribe(state.subscriptionId);
+      await pushAPI.unsubscribe(state.subscriptionId, state.subscription.endpoint);
 
       // Clear storage
       storage.removePushSubscriptionId();
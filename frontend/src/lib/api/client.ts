@@ -34,12 +34,13 @@ export const apiClient = {
     return response.json();
   },
 
-  async delete<T>(endpoint: string): Promise<T> {
+  async delete<T>(endpoint: string, data?: unknown): Promise<T> {
     const response = await fetch(`${API_BASE_URL}${endpoint}`, {
       method: 'DELETE',
       headers: {
         'Content-Type': 'application/json',
       },
+      body: data === undefined ? undefined : JSON.stringify(data),
     });
     
     if (!response.ok) {
@@ -1,11 +1,11 @@
-import { apiClient } from './client';
+import { apiClient, API_BASE_URL } from './client';
 import { 
   SubscribeRequest, 
   SubscribeResponse, 
   VAPIDResponse, 
   UnsubscribeResponse,
   SendNotificationRequest,
-  NotificationClickRequest
+  NotificationEventRequest
 } from '@/types/api';
 
 export const pushAPI = {
@@ -23,8 +23,9 @@ export const pushAPI = {
     return apiClient.post<SubscribeResponse>('/api/push/subscribe', subscriptionData);
   },
 
-  async unsubscribe(subscriptionId: string): Promise<UnsubscribeResponse> {
-    return apiClient.delete<UnsubscribeResponse>(`/api/push/subscriptions/${subscriptionId}`);
+  // endpoint は購読の所有者であることの証明としてサーバーへ送る
+  async unsubscribe(subscriptionId: string, endpoint: string): Promise<UnsubscribeResponse> {
+    return apiClient.delete<UnsubscribeResponse>(`/api/push/subscriptions/${subscriptionId}`, { endpoint });
   },
 
   // テスト用の通知送信（管理者用）
@@ -37,8 +38,18 @@ export const pushAPI = {
     return apiClient.post('/api/push/send/batch', payload);
   },
 
-  // 通知クリックトラッキング
-  async trackNotificationClick(clickData: NotificationClickRequest): Promise<{ success: boolean; message: string }> {
-    return apiClient.post('/api/push/click', clickData);
+  // 通知イベントトラッキング（204 No Content）
+  async trackNotificationEvent(eventData: NotificationEventRequest): Promise<void> {
+    const response = await fetch(`${API_BASE_URL}/api/push/events`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(eventData),
+    });
+
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
   }
 };
\ No newline at end of file
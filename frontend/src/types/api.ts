@@ -42,8 +42,10 @@ export interface NotificationAction {
   icon?: string;
 }
 
-export interface NotificationClickRequest {
-  trackingId: string;
-  action: string;
-  timestamp: string;
+export interface NotificationEventRequest {
+  event: 'delivered' | 'displayed' | 'clicked' | 'closed';
+  jobId: string;
+  subscriptionId?: string;
+  url?: string;
+  timestamp: number; // ミリ秒
 }
\ No newline at end of file
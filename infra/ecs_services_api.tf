@@ -4,6 +4,40 @@ resource "aws_cloudwatch_log_group" "api" {
   tags              = local.tags
 }
 
+# API コンテナに渡す秘密情報。SecureString として SSM に置き、タスク起動時に
+# 実行ロールが読み出して環境変数に展開する (タスク定義には平文で残らない)。
+locals {
+  api_secrets = {
+    ADMIN_API_TOKEN = var.admin_api_token
+    AUTH_JWT_SECRET = var.auth_jwt_secret
+    DATABASE_URL    = var.database_url
+  }
+}
+
+resource "aws_ssm_parameter" "api_secret" {
+  # 値が sensitive なので for_each にはキーだけを渡す
+  for_each = toset(keys(local.api_secrets))
+
+  name  = "/${var.name_prefix}/api/${each.key}"
+  type  = "SecureString"
+  value = local.api_secrets[each.key]
+  tags  = local.tags
+}
+
+data "aws_iam_policy_document" "api_secrets_read" {
+  statement {
+    effect    = "Allow"
+    actions   = ["ssm:GetParameters"]
+    resources = [for p in aws_ssm_parameter.api_secret : p.arn]
+  }
+}
+
+resource "aws_iam_role_policy" "api_secrets_read" {
+  name   = "${var.name_prefix}-api-secrets-read"
+  role   = aws_iam_role.task_execution.id
+  policy = data.aws_iam_policy_document.api_secrets_read.json
+}
+
 resource "aws_ecs_task_definition" "api" {
   family                   = "${var.name_prefix}-api"
   network_mode             = "awsvpc"
@@ -25,6 +59,16 @@ resource "aws_ecs_task_definition" "api" {
         {
           name  = "IMAGE_RECOGNITION_GRPC_ADDR"
           value = "microservice.${var.name_prefix}.local:${var.microservice_container_port}"
+        },
+        {
+          name  = "VAPID_SUBJECT"
+          value = var.vapid_subject
+        }
+      ]
+      secrets = [
+        for name, p in aws_ssm_parameter.api_secret : {
+          name      = name
+          valueFrom = p.arn
         }
       ]
       logConfiguration = {
@@ -59,6 +103,6 @@ resource "aws_ecs_service" "api" {
     container_port   = var.api_container_port
   }
 
-  depends_on = [aws_lb_listener_rule.api]
+  depends_on = [aws_lb_listener_rule.api, aws_iam_role_policy.api_secrets_read]
   tags       = local.tags
 }
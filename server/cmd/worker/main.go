@@ -0,0 +1,9 @@
+package main
+
+import "github.com/K-Kizuku/kotti-he-oide/internal/app"
+
+// The worker delivers push jobs, spawns scheduled jobs and runs maintenance
+// without serving HTTP, so it can be scaled separately from the API.
+func main() {
+	app.Main(app.ModeWorker)
+}
@@ -0,0 +1,196 @@
+// Package app is the composition root shared by the server and worker
+// commands. It wires repositories, services and use cases from the
+// configuration so every entry point runs the same application.
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/service"
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	domainService "github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/config"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/migration"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+)
+
+type App struct {
+	cfg   *config.Config
+	pool  *pgxpool.Pool
+	redis *redis.Client
+
+	vapidKeyService *domainService.VAPIDKeyService
+
+	// Background work
+	pushSenderService  *service.PushSenderService
+	jobDispatcher      *service.JobDispatcher
+	maintenanceService *service.MaintenanceService
+
+	// Use cases
+	userUseCase             *usecase.UserUseCase
+	vapidUseCase            *usecase.VAPIDUseCase
+	apiKeyUseCase           *usecase.APIKeyUseCase
+	pushSubscriptionUseCase *usecase.PushSubscriptionUseCase
+	pushNotificationUseCase *usecase.PushNotificationUseCase
+	templateUseCase         *usecase.NotificationTemplateUseCase
+	prefsUseCase            *usecase.NotificationPrefsUseCase
+	pushJobUseCase          *usecase.PushJobUseCase
+	pushLogUseCase          *usecase.PushLogUseCase
+	eventUseCase            *usecase.NotificationEventUseCase
+	scheduleUseCase         *usecase.PushScheduleUseCase
+}
+
+// New connects to the database (or falls back to in-memory repositories when
+// DATABASE_URL is not set) and wires the application. Call Close when done.
+func New(ctx context.Context, cfg *config.Config) (*App, error) {
+	a := &App{cfg: cfg}
+
+	var (
+		userRepo         repository.UserRepository
+		subscriptionRepo repository.PushSubscriptionRepository
+		jobRepo          repository.PushJobRepository
+		deliveryRepo     repository.PushDeliveryRepository
+		logRepo          repository.PushLogRepository
+		vapidKeyRepo     repository.VAPIDKeyRepository
+		templateRepo     repository.NotificationTemplateRepository
+		prefsRepo        repository.NotificationPrefsRepository
+		eventRepo        repository.NotificationEventRepository
+		apiKeyRepo       repository.APIKeyRepository
+		scheduleRepo     repository.PushScheduleRepository
+		locker           repository.Locker
+		notifier         repository.JobNotifier
+	)
+
+	if cfg.UsePostgres() {
+		pool, err := persistence.NewPostgresPool(ctx, cfg.DatabaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		a.pool = pool
+
+		if cfg.MigrateOnStart {
+			migrator, err := migration.NewMigrator(pool)
+			if err != nil {
+				pool.Close()
+				return nil, fmt.Errorf("failed to load migrations: %w", err)
+			}
+			if _, err := migrator.Up(ctx); err != nil {
+				pool.Close()
+				return nil, fmt.Errorf("failed to run migrations: %w", err)
+			}
+		}
+
+		userRepo = persistence.NewPostgresUserRepository(pool)
+		subscriptionRepo = persistence.NewPostgresPushSubscriptionRepository(pool)
+		jobRepo = persistence.NewPostgresPushJobRepository(pool)
+		deliveryRepo = persistence.NewPostgresPushDeliveryRepository(pool)
+		logRepo = persistence.NewPostgresPushLogRepository(pool)
+		vapidKeyRepo = persistence.NewPostgresVAPIDKeyRepository(pool)
+		templateRepo = persistence.NewPostgresNotificationTemplateRepository(pool)
+		prefsRepo = persistence.NewPostgresNotificationPrefsRepository(pool)
+		eventRepo = persistence.NewPostgresNotificationEventRepository(pool)
+		apiKeyRepo = persistence.NewPostgresAPIKeyRepository(pool)
+		scheduleRepo = persistence.NewPostgresPushScheduleRepository(pool)
+		locker = persistence.NewPostgresLocker(pool)
+		notifier = persistence.NewPostgresJobNotifier(pool)
+		log.Printf("Using PostgreSQL repositories")
+	} else {
+		userRepo = persistence.NewMemoryUserRepository()
+		subscriptionRepo = persistence.NewMemoryPushSubscriptionRepository()
+		memoryJobRepo := persistence.NewMemoryPushJobRepository()
+		memoryScheduleRepo := persistence.NewMemoryPushScheduleRepository(memoryJobRepo)
+		jobRepo = memoryJobRepo
+		deliveryRepo = persistence.NewMemoryPushDeliveryRepository()
+		logRepo = persistence.NewMemoryPushLogRepository()
+		templateRepo = persistence.NewMemoryNotificationTemplateRepository(memoryJobRepo, memoryScheduleRepo)
+		prefsRepo = persistence.NewMemoryNotificationPrefsRepository()
+		eventRepo = persistence.NewMemoryNotificationEventRepository()
+		apiKeyRepo = persistence.NewMemoryAPIKeyRepository()
+		scheduleRepo = memoryScheduleRepo
+		locker = persistence.NewMemoryLocker()
+		notifier = persistence.NewMemoryJobNotifier()
+		log.Printf("DATABASE_URL is not set; using in-memory repositories")
+
+		if cfg.VAPIDKeyFile != "" {
+			var err error
+			vapidKeyRepo, err = persistence.NewFileVAPIDKeyRepository(cfg.VAPIDKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load VAPID key file: %w", err)
+			}
+		} else {
+			vapidKeyRepo = persistence.NewMemoryVAPIDKeyRepository()
+			log.Printf("VAPID_KEY_FILE is not set; VAPID keys will not survive a restart")
+		}
+	}
+
+	// User dependencies
+	userService := domainService.NewUserService(userRepo)
+	a.userUseCase = usecase.NewUserUseCase(userRepo, userService)
+
+	// VAPID keys
+	a.vapidKeyService = domainService.NewVAPIDKeyService(vapidKeyRepo)
+	a.vapidUseCase = usecase.NewVAPIDUseCase(a.vapidKeyService)
+
+	// API keys
+	a.apiKeyUseCase = usecase.NewAPIKeyUseCase(apiKeyRepo, cfg.AdminAPIToken)
+
+	// Push services
+	pushService := domainService.NewPushService(subscriptionRepo, jobRepo, prefsRepo)
+	audienceService := domainService.NewAudienceService(subscriptionRepo, userRepo, prefsRepo)
+	deliveryQueue, err := a.newDeliveryQueue(ctx)
+	if err != nil {
+		a.Close()
+		return nil, err
+	}
+	a.pushSenderService = service.NewPushSenderServiceWithConfig(subscriptionRepo, jobRepo, logRepo, deliveryRepo, templateRepo, prefsRepo, audienceService, a.vapidKeyService, notifier, service.PushSenderConfig{
+		Workers:             cfg.SenderWorkers,
+		PerHostConcurrency:  cfg.SenderPerHostConcurrency,
+		JobConcurrency:      cfg.SenderJobConcurrency,
+		LeaseDuration:       cfg.SenderLeaseDuration,
+		MaxDeliveryAttempts: cfg.MaxDeliveryAttempts,
+		DrainTimeout:        cfg.SenderDrainTimeout,
+		BackoffBase:         cfg.BackoffBase,
+		BackoffMax:          cfg.BackoffMax,
+		BackoffJitter:       cfg.BackoffJitter,
+		ThrottleDelay:       cfg.ThrottleDelay,
+		MaxRetryAfter:       cfg.MaxRetryAfter,
+		VAPIDSubject:        cfg.VAPIDSubject,
+		Queue:               deliveryQueue,
+		QueueHold:           cfg.QueueHold,
+	})
+	scheduleSpawner := service.NewPushScheduleSpawner(scheduleRepo, jobRepo, notifier, cfg.ScheduleMisfireGrace)
+	a.jobDispatcher = service.NewJobDispatcher(a.pushSenderService, scheduleSpawner, jobRepo, notifier, pollInterval, dispatchBatchSize)
+	a.maintenanceService = service.NewMaintenanceService(pushService, jobRepo, logRepo, locker, service.MaintenanceConfig{
+		LogRetentionDays:                 cfg.LogRetentionDays,
+		JobRetentionDays:                 cfg.JobRetentionDays,
+		InvalidSubscriptionRetentionDays: cfg.InvalidSubscriptionRetentionDays,
+	})
+
+	// Use cases
+	a.pushSubscriptionUseCase = usecase.NewPushSubscriptionUseCase(subscriptionRepo, userRepo, pushService, a.vapidKeyService)
+	a.pushNotificationUseCase = usecase.NewPushNotificationUseCase(jobRepo, subscriptionRepo, templateRepo, pushService, audienceService, notifier)
+	a.templateUseCase = usecase.NewNotificationTemplateUseCase(templateRepo)
+	a.prefsUseCase = usecase.NewNotificationPrefsUseCase(userRepo, prefsRepo)
+	a.pushJobUseCase = usecase.NewPushJobUseCase(jobRepo, deliveryRepo, logRepo)
+	a.pushLogUseCase = usecase.NewPushLogUseCase(logRepo, subscriptionRepo)
+	a.eventUseCase = usecase.NewNotificationEventUseCase(eventRepo, jobRepo, subscriptionRepo, deliveryRepo)
+	a.scheduleUseCase = usecase.NewPushScheduleUseCase(scheduleRepo, templateRepo, notifier)
+
+	return a, nil
+}
+
+// Close releases the database and Redis connections.
+func (a *App) Close() {
+	if a.pool != nil {
+		a.pool.Close()
+	}
+	if a.redis != nil {
+		a.redis.Close()
+	}
+}
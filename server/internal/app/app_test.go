@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/config"
+)
+
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	return newTestAppWithQueue(t, "")
+}
+
+func newTestAppWithQueue(t *testing.T, deliveryQueue string) *App {
+	t.Helper()
+	subject, err := valueobject.NewVAPIDSubject("mailto:push@example.com")
+	if err != nil {
+		t.Fatalf("NewVAPIDSubject: %v", err)
+	}
+	a, err := New(context.Background(), &config.Config{
+		Port:                   "0",
+		ShutdownTimeout:        5 * time.Second,
+		SenderDrainTimeout:     time.Second,
+		MaintenanceInterval:    time.Hour,
+		VAPIDSubject:           subject,
+		DeliveryQueue:          deliveryQueue,
+		QueueVisibilityTimeout: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(a.Close)
+	return a
+}
+
+func TestParseMode(t *testing.T) {
+	for _, value := range []string{"all", "api", "worker"} {
+		if mode, err := ParseMode(value); err != nil || string(mode) != value {
+			t.Errorf("ParseMode(%q) = %q, %v", value, mode, err)
+		}
+	}
+	if _, err := ParseMode("both"); err == nil {
+		t.Error("ParseMode(\"both\") should fail")
+	}
+}
+
+func TestHandlerRegistersRoutes(t *testing.T) {
+	handler := newTestApp(t).Handler()
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/healthz", http.StatusOK},
+		{http.MethodGet, "/api/push/jobs", http.StatusUnauthorized},
+		{http.MethodPost, "/api/ml/recognize", http.StatusBadRequest},
+		{http.MethodGet, "/api/unknown", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestRunWorkerModeStopsOnCancel(t *testing.T) {
+	for _, deliveryQueue := range []string{"", config.DeliveryQueueMemory} {
+		t.Run("queue="+deliveryQueue, func(t *testing.T) {
+			a := newTestAppWithQueue(t, deliveryQueue)
+			ctx, cancel := context.WithCancel(context.Background())
+
+			done := make(chan error, 1)
+			go func() { done <- a.Run(ctx, ModeWorker) }()
+
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("Run: %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Run did not return after cancellation")
+			}
+		})
+	}
+}
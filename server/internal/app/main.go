@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/config"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/migration"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/cli"
+)
+
+// Main is the body of every command's main function. It runs the migrate,
+// vapid and apikey subcommands, or the application in the mode selected by
+// the -mode flag (defaultMode when the flag is not given).
+func Main(defaultMode Mode) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "migrate" {
+		runMigrate(cfg, args[1:])
+		return
+	}
+
+	// SIGINT/SIGTERM (e.g. an ECS task stopping) cancels ctx and starts a
+	// graceful shutdown; a second signal exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	a, err := New(ctx, cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize:", err)
+	}
+	defer a.Close()
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "vapid":
+			a.runVAPID(args[1:])
+			return
+		case "apikey":
+			a.runAPIKey(args[1:])
+			return
+		}
+	}
+
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	modeFlag := flags.String("mode", string(defaultMode), "what to run: all (API and worker), api or worker")
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		log.Fatalf("Unknown command %q (want migrate, vapid or apikey)", flags.Arg(0))
+	}
+	mode, err := ParseMode(*modeFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := a.Run(ctx, mode); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runMigrate(cfg *config.Config, args []string) {
+	if !cfg.UsePostgres() {
+		log.Fatal("DATABASE_URL is required to run migrations")
+	}
+
+	ctx := context.Background()
+	pool, err := persistence.NewPostgresPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	if err := migration.RunCommand(ctx, pool, args, os.Stdout); err != nil {
+		log.Fatal("Migration failed: ", err)
+	}
+}
+
+func (a *App) runVAPID(args []string) {
+	if !a.cfg.UsePostgres() && a.cfg.VAPIDKeyFile == "" {
+		log.Fatal("DATABASE_URL or VAPID_KEY_FILE is required to manage VAPID keys")
+	}
+
+	if err := cli.RunVAPIDCommand(context.Background(), a.vapidUseCase, args, os.Stdout); err != nil {
+		log.Fatal("VAPID command failed: ", err)
+	}
+}
+
+func (a *App) runAPIKey(args []string) {
+	if !a.cfg.UsePostgres() {
+		log.Fatal("DATABASE_URL is required to manage API keys")
+	}
+
+	if err := cli.RunAPIKeyCommand(context.Background(), a.apiKeyUseCase, args, os.Stdout); err != nil {
+		log.Fatal("API key command failed: ", err)
+	}
+}
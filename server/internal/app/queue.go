@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/queue"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/config"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/messaging"
+)
+
+// The Redis stream carrying deliveries and the consumer group every send
+// worker reads it through.
+const (
+	deliveryStream = "push:deliveries"
+	deliveryGroup  = "push-senders"
+)
+
+// newDeliveryQueue returns the queue selected by PUSH_DELIVERY_QUEUE, or nil
+// when deliveries are sent while their job is claimed.
+func (a *App) newDeliveryQueue(ctx context.Context) (queue.Queue, error) {
+	switch a.cfg.DeliveryQueue {
+	case config.DeliveryQueueMemory:
+		return messaging.NewMemoryQueue(a.cfg.QueueVisibilityTimeout), nil
+	case config.DeliveryQueueRedis:
+		options, err := redis.ParseURL(a.cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		a.redis = redis.NewClient(options)
+		if err := a.redis.Ping(ctx).Err(); err != nil {
+			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		streamQueue, err := messaging.NewRedisStreamQueue(ctx, a.redis, deliveryStream, deliveryGroup, consumerName(), a.cfg.QueueVisibilityTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return streamQueue, nil
+	default:
+		return nil, nil
+	}
+}
+
+// consumerName identifies this process within the consumer group.
+func consumerName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/handler"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/middleware"
+)
+
+// Handler returns the HTTP API with every route registered.
+func (a *App) Handler() http.Handler {
+	// Handlers
+	healthHandler := handler.NewHealthHandler()
+	userHandler := handler.NewUserHandler(a.userUseCase)
+	pushSubscriptionHandler := handler.NewPushSubscriptionHandler(a.pushSubscriptionUseCase)
+	pushNotificationHandler := handler.NewPushNotificationHandler(a.pushNotificationUseCase)
+	vapidHandler := handler.NewVAPIDHandler(a.vapidUseCase)
+	templateHandler := handler.NewNotificationTemplateHandler(a.templateUseCase)
+	prefsHandler := handler.NewNotificationPrefsHandler(a.prefsUseCase)
+	pushJobHandler := handler.NewPushJobHandler(a.pushJobUseCase)
+	pushLogHandler := handler.NewPushLogHandler(a.pushLogUseCase)
+	eventHandler := handler.NewNotificationEventHandler(a.eventUseCase)
+	apiKeyHandler := handler.NewAPIKeyHandler(a.apiKeyUseCase)
+	scheduleHandler := handler.NewPushScheduleHandler(a.scheduleUseCase)
+	mlHandler := handler.NewMLHandler()
+
+	auth := middleware.NewAuthenticator(a.apiKeyUseCase, a.cfg.AuthJWTSecret)
+	send := func(next http.HandlerFunc) http.HandlerFunc {
+		return auth.RequireScope(model.APIKeyScopeSend, next)
+	}
+	readLogs := func(next http.HandlerFunc) http.HandlerFunc {
+		return auth.RequireScope(model.APIKeyScopeReadLogs, next)
+	}
+	admin := func(next http.HandlerFunc) http.HandlerFunc {
+		return auth.RequireScope(model.APIKeyScopeAdmin, next)
+	}
+	selfOrAdmin := func(next http.HandlerFunc) http.HandlerFunc {
+		return auth.RequireSelfOrScope(model.APIKeyScopeAdmin, next)
+	}
+
+	mux := http.NewServeMux()
+
+	// Health check
+	mux.HandleFunc("GET /api/healthz", healthHandler.HealthCheck)
+
+	// User API
+	mux.HandleFunc("GET /api/users", admin(userHandler.GetUsers))
+	mux.HandleFunc("POST /api/users", admin(userHandler.CreateUser))
+	mux.HandleFunc("GET /api/users/{id}", selfOrAdmin(userHandler.GetUser))
+	mux.HandleFunc("PATCH /api/users/{id}", admin(userHandler.UpdateUser))
+	mux.HandleFunc("DELETE /api/users/{id}", admin(userHandler.DeleteUser))
+	mux.HandleFunc("GET /api/users/{id}/notification-prefs", selfOrAdmin(prefsHandler.GetPrefs))
+	mux.HandleFunc("PUT /api/users/{id}/notification-prefs", selfOrAdmin(prefsHandler.UpdatePrefs))
+	mux.HandleFunc("GET /api/users/{id}/subscriptions", selfOrAdmin(pushSubscriptionHandler.ListUserSubscriptions))
+	mux.HandleFunc("DELETE /api/users/{id}/subscriptions/{subscriptionId}", selfOrAdmin(pushSubscriptionHandler.RevokeUserSubscription))
+
+	// Web Push API
+	mux.HandleFunc("GET /api/push/vapid-public-key", vapidHandler.GetPublicKey)
+	mux.HandleFunc("GET /api/push/vapid-keys", admin(vapidHandler.ListKeys))
+	mux.HandleFunc("POST /api/push/vapid-keys", admin(vapidHandler.GenerateKey))
+	mux.HandleFunc("POST /api/push/vapid-keys/{id}/activate", admin(vapidHandler.ActivateKey))
+	mux.HandleFunc("POST /api/push/subscribe", auth.OptionalUser(pushSubscriptionHandler.Subscribe))
+	mux.HandleFunc("DELETE /api/push/subscriptions/{id}", auth.OptionalUser(pushSubscriptionHandler.Unsubscribe))
+	mux.HandleFunc("POST /api/push/send", send(pushNotificationHandler.SendNotification))
+	mux.HandleFunc("POST /api/push/send/batch", send(pushNotificationHandler.SendBatchNotification))
+	mux.HandleFunc("POST /api/push/send/dry-run", send(pushNotificationHandler.PreviewAudience))
+	mux.HandleFunc("POST /api/push/events", eventHandler.RecordEvent)
+	mux.HandleFunc("GET /api/push/jobs", send(pushJobHandler.ListJobs))
+	mux.HandleFunc("GET /api/push/jobs/{id}", send(pushJobHandler.GetJob))
+	mux.HandleFunc("POST /api/push/jobs/{id}/cancel", send(pushJobHandler.CancelJob))
+	mux.HandleFunc("GET /api/push/jobs/{id}/engagement", send(eventHandler.GetEngagement))
+	mux.HandleFunc("GET /api/push/jobs/{id}/logs", readLogs(pushLogHandler.JobLogs))
+	mux.HandleFunc("GET /api/push/subscriptions/{id}/logs", readLogs(pushLogHandler.SubscriptionLogs))
+	mux.HandleFunc("GET /api/push/schedules", send(scheduleHandler.ListSchedules))
+	mux.HandleFunc("POST /api/push/schedules", send(scheduleHandler.CreateSchedule))
+	mux.HandleFunc("GET /api/push/schedules/{id}", send(scheduleHandler.GetSchedule))
+	mux.HandleFunc("POST /api/push/schedules/{id}/pause", send(scheduleHandler.PauseSchedule))
+	mux.HandleFunc("POST /api/push/schedules/{id}/resume", send(scheduleHandler.ResumeSchedule))
+	mux.HandleFunc("DELETE /api/push/schedules/{id}", send(scheduleHandler.DeleteSchedule))
+	mux.HandleFunc("GET /api/push/templates", send(templateHandler.ListTemplates))
+	mux.HandleFunc("POST /api/push/templates", admin(templateHandler.CreateTemplate))
+	mux.HandleFunc("GET /api/push/templates/{key}", send(templateHandler.GetTemplate))
+	mux.HandleFunc("PUT /api/push/templates/{key}", admin(templateHandler.UpdateTemplate))
+	mux.HandleFunc("DELETE /api/push/templates/{key}", admin(templateHandler.DeleteTemplate))
+
+	// Admin API
+	mux.HandleFunc("GET /api/admin/api-keys", admin(apiKeyHandler.ListKeys))
+	mux.HandleFunc("POST /api/admin/api-keys", admin(apiKeyHandler.CreateKey))
+	mux.HandleFunc("DELETE /api/admin/api-keys/{id}", admin(apiKeyHandler.RevokeKey))
+
+	// ML (gRPC 経由) API プロキシ
+	mux.HandleFunc("GET /api/ml/hello", mlHandler.HelloProxy)
+	mux.HandleFunc("POST /api/ml/recognize", mlHandler.RecognizeImageProxy)
+
+	return mux
+}
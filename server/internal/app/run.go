@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	domainService "github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+)
+
+// Mode selects which parts of the application a process runs.
+type Mode string
+
+const (
+	// ModeAll serves the HTTP API and runs the worker in one process.
+	ModeAll Mode = "all"
+	// ModeAPI serves the HTTP API only; jobs are delivered by workers.
+	ModeAPI Mode = "api"
+	// ModeWorker delivers push jobs and runs maintenance without HTTP.
+	ModeWorker Mode = "worker"
+)
+
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(value); mode {
+	case ModeAll, ModeAPI, ModeWorker:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid mode %q (want all, api or worker)", value)
+	}
+}
+
+func (m Mode) servesAPI() bool {
+	return m == ModeAll || m == ModeAPI
+}
+
+func (m Mode) runsWorker() bool {
+	return m == ModeAll || m == ModeWorker
+}
+
+// Run starts what mode selects and blocks until ctx is cancelled, then shuts
+// down gracefully: the HTTP server stops accepting requests and finishes
+// those in flight, and the worker drains its deliveries, all within
+// ShutdownTimeout.
+func (a *App) Run(ctx context.Context, mode Mode) error {
+	if err := a.prepare(ctx, mode); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		server    *http.Server
+		serverErr = make(chan error, 1)
+	)
+	if mode.servesAPI() {
+		server = &http.Server{
+			Addr:    ":" + a.cfg.Port,
+			Handler: a.Handler(),
+		}
+		go func() {
+			log.Printf("Server starting on port %s", a.cfg.Port)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- err
+			}
+		}()
+	}
+
+	var background sync.WaitGroup
+	if mode.runsWorker() {
+		log.Printf("Push worker started")
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			a.RunWorker(ctx)
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+	case err := <-serverErr:
+		runErr = fmt.Errorf("server failed: %w", err)
+		cancel()
+	}
+	log.Printf("Shutting down; waiting up to %s for requests and deliveries in flight", a.cfg.ShutdownTimeout)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	if server != nil {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("HTTP server did not shut down cleanly: %v", err)
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		log.Printf("Stopped")
+	case <-shutdownCtx.Done():
+		log.Printf("Timed out waiting for background work; unfinished jobs will be reclaimed when their leases expire")
+	}
+	return runErr
+}
+
+// prepare checks the configuration mode needs and makes sure a VAPID key is
+// active before anything is served or sent.
+func (a *App) prepare(ctx context.Context, mode Mode) error {
+	if a.cfg.VAPIDSubject.IsZero() {
+		return fmt.Errorf("VAPID_SUBJECT is required (e.g. mailto:push@example.com or https://example.com/contact)")
+	}
+	if mode != ModeAll && !a.cfg.UsePostgres() {
+		log.Printf("DATABASE_URL is not set; in-memory data is not shared between API and worker processes")
+	}
+	if mode.servesAPI() {
+		if a.cfg.AdminAPIToken == "" {
+			log.Printf("ADMIN_API_TOKEN is not set; only stored API keys can call protected endpoints")
+		}
+		if a.cfg.AuthJWTSecret == "" {
+			log.Printf("AUTH_JWT_SECRET is not set; user session tokens will be rejected")
+		}
+	}
+
+	var configuredKey *domainService.VAPIDService
+	if a.cfg.HasVAPIDKeyPair() {
+		configuredKey = domainService.NewVAPIDServiceWithKeys(a.cfg.VAPIDPrivateKey, a.cfg.VAPIDPublicKey)
+	}
+	activeKey, err := a.vapidKeyService.EnsureActiveKey(ctx, configuredKey)
+	if err != nil {
+		return fmt.Errorf("failed to initialize VAPID keys: %w", err)
+	}
+	log.Printf("Active VAPID key: %d", activeKey.ID())
+	return nil
+}
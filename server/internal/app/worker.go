@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// pollInterval is how often the worker looks for due schedules and ready
+	// jobs it was not notified about.
+	pollInterval = 30 * time.Second
+	// dispatchBatchSize is how many schedules and jobs one dispatch claims.
+	dispatchBatchSize = 100
+)
+
+// RunWorker spawns scheduled jobs, delivers push jobs and runs maintenance
+// until ctx is cancelled. With a delivery queue configured it also sends the
+// queued deliveries. It returns once deliveries in flight have drained and
+// unfinished jobs have been released.
+func (a *App) RunWorker(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a.jobDispatcher.Run(ctx)
+	}()
+	if a.cfg.DeliveryQueue != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.pushSenderService.ConsumeDeliveries(ctx)
+		}()
+	}
+	go func() {
+		defer wg.Done()
+		a.runMaintenance(ctx)
+	}()
+	wg.Wait()
+}
+
+// runMaintenance removes expired subscriptions and data past retention every
+// MaintenanceInterval.
+func (a *App) runMaintenance(ctx context.Context) {
+	for {
+		report, err := a.maintenanceService.RunMaintenance(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error running maintenance: %v", err)
+		}
+		if report != nil {
+			log.Printf("Maintenance removed %s", report)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(a.cfg.MaintenanceInterval):
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// Message asks a send worker to attempt one delivery of a push job.
+type Message struct {
+	JobID          valueobject.JobID
+	DeliveryID     int64
+	SubscriptionID valueobject.SubscriptionID
+}
+
+// Received is a message handed to a worker. It stays invisible to other
+// workers until it is acknowledged with its Receipt or the queue's
+// visibility timeout passes, after which it is received again.
+type Received struct {
+	Message
+	Receipt string
+}
+
+// Queue carries deliveries from the job dispatcher to the send workers with
+// at-least-once semantics: a message is redelivered unless it is
+// acknowledged, e.g. because its worker crashed while sending.
+type Queue interface {
+	Enqueue(ctx context.Context, messages ...Message) error
+	// Receive waits until at least one message is available, then returns
+	// up to max of them. It returns ctx.Err() once ctx is cancelled.
+	Receive(ctx context.Context, max int) ([]Received, error)
+	// Ack removes handled messages for good.
+	Ack(ctx context.Context, receipts ...string) error
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/queue"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type PushSenderConfig struct {
+	// Workers is the number of goroutines fanning out deliveries for a job.
+	Workers int
+	// PerHostConcurrency caps in-flight requests to a single push service
+	// host (fcm.googleapis.com, web.push.apple.com, ...) across all jobs.
+	PerHostConcurrency int
+	// JobConcurrency is the number of jobs processed at the same time.
+	JobConcurrency int
+	// LeaseDuration is how long a claimed job stays reserved for this
+	// sender without a renewal before other instances may reclaim it.
+	LeaseDuration time.Duration
+	// MaxDeliveryAttempts is how many times a single subscription is tried
+	// before its delivery is given up as failed.
+	MaxDeliveryAttempts int
+	// BackoffBase and BackoffMax bound the exponential delay between
+	// attempts of a failed delivery.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// BackoffJitter spreads each backoff delay by up to this fraction
+	// (0.2 = ±20%). Zero disables jitter.
+	BackoffJitter float64
+	// ThrottleDelay is how long a host is paused after a 429/503 response
+	// without a usable Retry-After header.
+	ThrottleDelay time.Duration
+	// MaxRetryAfter caps the pause requested by a push service.
+	MaxRetryAfter time.Duration
+	// VAPIDSubject is the contact URI sent to push services for keys that do
+	// not set their own subject.
+	VAPIDSubject valueobject.VAPIDSubject
+	// DrainTimeout is how long deliveries in flight may keep running after
+	// processing is cancelled, e.g. on shutdown.
+	DrainTimeout time.Duration
+	// InstanceID identifies this sender as the lease owner. A random ID is
+	// generated when empty.
+	InstanceID string
+	// Queue, when set, carries due deliveries to the send workers running
+	// ConsumeDeliveries instead of sending them while the job is claimed.
+	Queue queue.Queue
+	// QueueHold is how long a delivery handed to the queue is left to it
+	// before the job's next round enqueues it again, in case the message
+	// was lost with the queue itself. It must be longer than the queue's
+	// visibility timeout, after which abandoned messages are redelivered.
+	QueueHold time.Duration
+}
+
+func DefaultPushSenderConfig() PushSenderConfig {
+	return PushSenderConfig{
+		Workers:             32,
+		PerHostConcurrency:  16,
+		JobConcurrency:      4,
+		LeaseDuration:       5 * time.Minute,
+		MaxDeliveryAttempts: 5,
+		BackoffBase:         30 * time.Second,
+		BackoffMax:          6 * time.Hour,
+		BackoffJitter:       0.2,
+		ThrottleDelay:       time.Minute,
+		MaxRetryAfter:       6 * time.Hour,
+		DrainTimeout:        20 * time.Second,
+		QueueHold:           10 * time.Minute,
+	}
+}
+
+func (c PushSenderConfig) withDefaults() PushSenderConfig {
+	defaults := DefaultPushSenderConfig()
+	if c.Workers <= 0 {
+		c.Workers = defaults.Workers
+	}
+	if c.PerHostConcurrency <= 0 {
+		c.PerHostConcurrency = defaults.PerHostConcurrency
+	}
+	if c.JobConcurrency <= 0 {
+		c.JobConcurrency = defaults.JobConcurrency
+	}
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = defaults.LeaseDuration
+	}
+	if c.MaxDeliveryAttempts <= 0 {
+		c.MaxDeliveryAttempts = defaults.MaxDeliveryAttempts
+	}
+	if c.BackoffBase <= 0 {
+		c.BackoffBase = defaults.BackoffBase
+	}
+	if c.BackoffMax <= 0 {
+		c.BackoffMax = defaults.BackoffMax
+	}
+	c.BackoffJitter = max(0, min(c.BackoffJitter, 1))
+	if c.ThrottleDelay <= 0 {
+		c.ThrottleDelay = defaults.ThrottleDelay
+	}
+	if c.MaxRetryAfter <= 0 {
+		c.MaxRetryAfter = defaults.MaxRetryAfter
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = defaults.DrainTimeout
+	}
+	if c.QueueHold <= 0 {
+		c.QueueHold = defaults.QueueHold
+	}
+	if c.InstanceID == "" {
+		c.InstanceID = newInstanceID()
+	}
+	return c
+}
+
+// hostLimiter hands out per-host semaphores so a single push service never
+// sees more than limit concurrent requests from this process.
+type hostLimiter struct {
+	mu    sync.Mutex
+	limit int
+	slots map[string]chan struct{}
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{
+		limit: limit,
+		slots: make(map[string]chan struct{}),
+	}
+}
+
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	slot, exists := l.slots[host]
+	if !exists {
+		slot = make(chan struct{}, l.limit)
+		l.slots[host] = slot
+	}
+	l.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func endpointHost(endpoint string) string {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/queue"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// receiveRetryDelay is how long ConsumeDeliveries waits after the queue
+// failed before receiving again.
+const receiveRetryDelay = 5 * time.Second
+
+// errDeliveryInFlight is returned for a message whose delivery another
+// worker is sending. The message is left unacknowledged, so the queue
+// redelivers it in case that worker dies.
+var errDeliveryInFlight = stderrors.New("delivery is being sent by another worker")
+
+// enqueueDeliveries hands the due deliveries of a claimed job to the queue,
+// one message per subscription, and puts the job back to pending until the
+// workers have sent them. Like any round that leaves deliveries pending,
+// this does not count as a retry of the job.
+func (pss *PushSenderService) enqueueDeliveries(ctx context.Context, job *model.PushJob, targets []deliveryTarget) error {
+	until := time.Now().Add(pss.config.QueueHold)
+	held := make([]deliveryTarget, 0, len(targets))
+	messages := make([]queue.Message, 0, len(targets))
+	for _, target := range targets {
+		// Held before it is enqueued, so the job's next round does not
+		// enqueue it again while it waits in the queue. A delivery that
+		// can no longer be held is already queued or being sent.
+		ok, err := pss.deliveryRepo.Hold(ctx, target.delivery.ID(), until, "queued for sending")
+		if err != nil {
+			pss.releaseHeld(ctx, held)
+			return pss.rescheduleJob(ctx, job, fmt.Errorf("failed to hold delivery %d: %w", target.delivery.ID(), err))
+		}
+		if !ok {
+			continue
+		}
+		held = append(held, target)
+		messages = append(messages, queue.Message{
+			JobID:          job.ID(),
+			DeliveryID:     target.delivery.ID(),
+			SubscriptionID: target.subscription.ID(),
+		})
+	}
+
+	if len(messages) > 0 {
+		if err := pss.config.Queue.Enqueue(ctx, messages...); err != nil {
+			pss.releaseHeld(ctx, held)
+			return pss.rescheduleJob(ctx, job, fmt.Errorf("failed to enqueue deliveries: %w", err))
+		}
+	}
+
+	if err := pss.finishJob(ctx, job); err != nil {
+		return err
+	}
+	// Workers that sent the last delivery before the job was saved could
+	// not wake it, so check once more now that it is pending.
+	pss.settleQueuedJob(ctx, job.ID())
+	return nil
+}
+
+// releaseHeld makes deliveries that could not be enqueued due again, so the
+// job's next round retries them rather than waiting out the hold.
+func (pss *PushSenderService) releaseHeld(ctx context.Context, targets []deliveryTarget) {
+	for _, target := range targets {
+		target.delivery.Defer(time.Now(), "failed to enqueue")
+		if err := pss.deliveryRepo.Save(ctx, target.delivery); err != nil {
+			log.Printf("Failed to save delivery %d: %v", target.delivery.ID(), err)
+		}
+	}
+}
+
+// ConsumeDeliveries sends the deliveries handed out by the queue until ctx is
+// cancelled, with up to Workers in flight. Deliveries in flight get up to
+// DrainTimeout to finish; messages left unacknowledged are redelivered by the
+// queue to another worker.
+func (pss *PushSenderService) ConsumeDeliveries(ctx context.Context) {
+	workCtx, cancel := drainContext(ctx, pss.config.DrainTimeout)
+	defer cancel()
+
+	slots := make(chan struct{}, pss.config.Workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		// Only take as many messages as there are idle workers, so the rest
+		// stay available to other senders.
+		received, err := pss.config.Queue.Receive(ctx, cap(slots)-len(slots)+1)
+		if err != nil {
+			<-slots
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to receive deliveries: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(receiveRetryDelay):
+			}
+			continue
+		}
+
+		for i, message := range received {
+			if i > 0 {
+				slots <- struct{}{}
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				pss.handleQueued(workCtx, message)
+			}()
+		}
+		if len(received) == 0 {
+			<-slots
+		}
+	}
+}
+
+// handleQueued sends one queued delivery and acknowledges it, unless sending
+// was interrupted or another worker is sending it, and the message should be
+// redelivered.
+func (pss *PushSenderService) handleQueued(ctx context.Context, received queue.Received) {
+	if err := pss.sendQueued(ctx, received.Message); err != nil {
+		if ctx.Err() == nil && err != errDeliveryInFlight {
+			log.Printf("Failed to send delivery %d: %v", received.DeliveryID, err)
+		}
+		return
+	}
+	if err := pss.config.Queue.Ack(ctx, received.Receipt); err != nil {
+		log.Printf("Failed to acknowledge delivery %d: %v", received.DeliveryID, err)
+	}
+	pss.settleQueuedJob(ctx, received.JobID)
+}
+
+// sendQueued claims a queued delivery and attempts it. Messages for
+// deliveries that are already settled, e.g. duplicates after a redelivery,
+// are ignored.
+func (pss *PushSenderService) sendQueued(ctx context.Context, message queue.Message) error {
+	delivery, err := pss.deliveryRepo.FindByID(ctx, message.DeliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery == nil || delivery.Status() != model.DeliveryStatusPending {
+		return nil
+	}
+
+	job, err := pss.jobRepo.FindByID(ctx, message.JobID)
+	if err != nil {
+		return err
+	}
+	if job == nil || (job.Status() != model.JobStatusPending && job.Status() != model.JobStatusSending) {
+		// Cancelled or given up while the message was queued.
+		return nil
+	}
+
+	subscription, err := pss.subscriptionRepo.FindByID(ctx, message.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	// Only the worker holding the lease sends the delivery and saves its
+	// outcome, even if the message was handed to several workers.
+	owner := fmt.Sprintf("%s/%d", pss.instanceID, pss.deliveryClaims.Add(1))
+	delivery, err = pss.deliveryRepo.Claim(ctx, message.DeliveryID, owner, pss.config.LeaseDuration)
+	if err != nil {
+		return err
+	}
+	if delivery == nil {
+		return errDeliveryInFlight
+	}
+	target := deliveryTarget{delivery: delivery, subscription: subscription, leaseOwner: owner}
+
+	if subscription == nil || !subscription.IsValid() {
+		delivery.MarkAsGone(nil, "subscription is no longer valid")
+		return pss.saveDelivery(ctx, target)
+	}
+
+	payload, _, err := pss.buildPayload(ctx, job)
+	if err != nil {
+		// The job's next round fails or retries the job, so leave the
+		// delivery due for it.
+		delivery.Defer(time.Now(), err.Error())
+		return pss.saveDelivery(ctx, target)
+	}
+
+	pss.deliver(ctx, job, payload, target)
+	return ctx.Err()
+}
+
+// settleQueuedJob wakes the job as soon as its deliveries need it: right
+// away once none are pending so it completes, or when the earliest one is
+// due for a retry.
+func (pss *PushSenderService) settleQueuedJob(ctx context.Context, jobID valueobject.JobID) {
+	next, err := pss.deliveryRepo.NextAttemptAt(ctx, jobID)
+	if err != nil {
+		log.Printf("Failed to settle job %s: %v", jobID.String(), err)
+		return
+	}
+
+	at := time.Now()
+	if next != nil && next.After(at) {
+		at = *next
+	}
+	advanced, err := pss.jobRepo.AdvanceNextAttempt(ctx, jobID, at)
+	if err != nil {
+		log.Printf("Failed to settle job %s: %v", jobID.String(), err)
+		return
+	}
+	if advanced {
+		if err := pss.notifier.NotifyJobReady(ctx, at); err != nil {
+			log.Printf("Failed to notify dispatchers of job %s: %v", jobID.String(), err)
+		}
+	}
+}
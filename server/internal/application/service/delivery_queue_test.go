@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/messaging"
+)
+
+func TestQueuedDeliveries(t *testing.T) {
+	ctx := context.Background()
+	var sent atomic.Int32
+	deliveryQueue := messaging.NewMemoryQueue(200 * time.Millisecond)
+	f := newSenderFixture(t, PushSenderConfig{Queue: deliveryQueue}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	for i := 0; i < 3; i++ {
+		f.addSubscription(t, fmt.Sprintf("https://fcm.googleapis.com/fcm/send/%d", i))
+	}
+	job := f.addJob(t)
+
+	// The job round only enqueues, one message per subscription.
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+	if n := sent.Load(); n != 0 {
+		t.Fatalf("%d pushes were sent while enqueuing", n)
+	}
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusPending || saved.NextAttemptAt() == nil || saved.NextAttemptAt().Before(time.Now()) {
+		t.Fatalf("job after enqueuing = %s, next attempt %v", saved.Status(), saved.NextAttemptAt())
+	}
+
+	// A round while the deliveries wait in the queue enqueues nothing more,
+	// and neither round counts against the job's retries.
+	saved.AwaitDeliveries(time.Now().Add(-time.Second))
+	f.jobRepo.Save(ctx, saved)
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+	saved, _ = f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusPending || saved.RetryCount() != 0 {
+		t.Fatalf("job after another round = %s with %d retries, want pending without retries", saved.Status(), saved.RetryCount())
+	}
+
+	// A worker takes one message and crashes before acknowledging it.
+	crashed, err := deliveryQueue.Receive(ctx, 1)
+	if err != nil || len(crashed) != 1 {
+		t.Fatalf("Receive = %v, %v", crashed, err)
+	}
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		f.sender.ConsumeDeliveries(consumeCtx)
+		close(done)
+	}()
+	deadline := time.Now().Add(3 * time.Second)
+	for sent.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if n := sent.Load(); n != 3 {
+		t.Fatalf("sent %d pushes, want 3 including the redelivered one", n)
+	}
+
+	// The last delivery woke the job, which now completes.
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+	counts, _ := f.deliveryRepo.CountByJobID(ctx, job.ID())
+	saved, _ = f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusSucceeded || counts.Succeeded != 3 || saved.RetryCount() != 0 {
+		t.Errorf("job = %s with %+v deliveries and %d retries, want succeeded with 3", saved.Status(), counts, saved.RetryCount())
+	}
+	if n := sent.Load(); n != 3 {
+		t.Errorf("completing the job sent %d more pushes", n-3)
+	}
+}
+
+func TestQueuedDeliveryIsSentOnce(t *testing.T) {
+	ctx := context.Background()
+	var sent atomic.Int32
+	started := make(chan struct{}, 10)
+	unblock := make(chan struct{})
+	// The message is redelivered to other workers while the first one is
+	// still sending it.
+	deliveryQueue := messaging.NewMemoryQueue(50 * time.Millisecond)
+	f := newSenderFixture(t, PushSenderConfig{Queue: deliveryQueue, QueueHold: time.Hour}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		started <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusCreated)
+	}))
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/device")
+	job := f.addJob(t)
+
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.NextAttemptAt() == nil || saved.NextAttemptAt().Before(time.Now().Add(50*time.Minute)) {
+		t.Fatalf("job next attempt = %v, want held for the configured hour", saved.NextAttemptAt())
+	}
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		f.sender.ConsumeDeliveries(consumeCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	<-started
+	time.Sleep(300 * time.Millisecond)
+	close(unblock)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if counts, _ := f.deliveryRepo.CountByJobID(ctx, job.ID()); counts.Succeeded == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Let the remaining redeliveries be acknowledged.
+	time.Sleep(200 * time.Millisecond)
+
+	if n := sent.Load(); n != 1 {
+		t.Errorf("sent %d pushes for a single delivery, want 1", n)
+	}
+	counts, _ := f.deliveryRepo.CountByJobID(ctx, job.ID())
+	if counts.Succeeded != 1 {
+		t.Errorf("delivery counts = %+v, want succeeded", counts)
+	}
+}
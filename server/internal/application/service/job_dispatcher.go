@@ -0,0 +1,239 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+)
+
+// maxWakeups caps the pending wake-up times a dispatcher remembers. Dropping
+// one only delays that job until the next poll.
+const maxWakeups = 10000
+
+// JobDispatcher runs the schedule spawner and the sender whenever jobs
+// become ready rather than on a fixed poll: notifications for jobs that are
+// ready now dispatch immediately, and future ready times (scheduleAt,
+// retries, lease expiries, schedule runs) are kept in a min-heap and
+// dispatched when they arrive. Polling every pollInterval remains as a
+// fallback for notifications that were lost.
+//
+// Claimed jobs are sent in the background, at most the sender's
+// JobConcurrency at a time, so a slow job never holds up newly ready ones:
+// the dispatcher keeps receiving notifications and claims again as soon as
+// a job slot frees up.
+type JobDispatcher struct {
+	sender       *PushSenderService
+	spawner      *PushScheduleSpawner
+	jobRepo      repository.PushJobRepository
+	notifier     repository.JobNotifier
+	pollInterval time.Duration
+	batchSize    int
+
+	// slots holds a token per job being sent; freed is signalled when one
+	// is returned.
+	slots   chan struct{}
+	freed   chan struct{}
+	running sync.WaitGroup
+}
+
+func NewJobDispatcher(
+	sender *PushSenderService,
+	spawner *PushScheduleSpawner,
+	jobRepo repository.PushJobRepository,
+	notifier repository.JobNotifier,
+	pollInterval time.Duration,
+	batchSize int,
+) *JobDispatcher {
+	return &JobDispatcher{
+		sender:       sender,
+		spawner:      spawner,
+		jobRepo:      jobRepo,
+		notifier:     notifier,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		slots:        make(chan struct{}, sender.config.JobConcurrency),
+		freed:        make(chan struct{}, 1),
+	}
+}
+
+// Run dispatches until ctx is cancelled. It returns once the jobs in
+// flight have drained: they get up to the sender's DrainTimeout to finish
+// and are released to pending otherwise.
+func (d *JobDispatcher) Run(ctx context.Context) {
+	workCtx, cancel := drainContext(ctx, d.sender.config.DrainTimeout)
+	defer cancel()
+	defer d.running.Wait()
+
+	events, err := d.notifier.Subscribe(ctx)
+	if err != nil {
+		log.Printf("Failed to subscribe to job notifications, falling back to polling: %v", err)
+	}
+
+	var wakeups wakeupQueue
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	nextPoll := time.Now()
+	// backlog is set while ready jobs may be waiting for a free slot.
+	backlog := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case readyAt, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if readyAt.After(time.Now()) {
+				wakeups.add(readyAt)
+				resetTimer(timer, wakeups.earliest(nextPoll))
+				continue
+			}
+		case <-d.freed:
+			if !backlog {
+				continue
+			}
+		case <-timer.C:
+		}
+
+		backlog = d.dispatch(ctx, workCtx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := time.Now()
+		nextPoll = now.Add(d.pollInterval)
+		wakeups.drainDue(now)
+		d.drainEvents(events, &wakeups, now)
+		if next, err := d.jobRepo.NextClaimableAt(ctx); err != nil {
+			log.Printf("Error finding the next ready job: %v", err)
+		} else if next != nil {
+			at := *next
+			if !at.After(now) {
+				// The database clock is behind ours; back off rather than
+				// spin until it catches up.
+				at = now.Add(time.Second)
+			}
+			wakeups.add(at)
+		}
+		resetTimer(timer, wakeups.earliest(nextPoll))
+	}
+}
+
+// dispatch spawns due schedules and starts sending as many ready jobs as
+// there are free slots, repeating while full batches show that more jobs
+// are waiting. It reports whether ready jobs may be left for when a slot
+// frees up.
+func (d *JobDispatcher) dispatch(ctx, workCtx context.Context) bool {
+	for ctx.Err() == nil {
+		if _, err := d.spawner.SpawnDueSchedules(ctx, d.batchSize); err != nil && ctx.Err() == nil {
+			log.Printf("Error spawning scheduled jobs: %v", err)
+		}
+
+		free := min(cap(d.slots)-len(d.slots), d.batchSize)
+		if free == 0 {
+			return true
+		}
+		jobs, err := d.sender.claimReadyJobs(ctx, free)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error processing pending jobs: %v", err)
+			}
+			return false
+		}
+		for _, job := range jobs {
+			d.start(ctx, workCtx, job)
+		}
+		if len(jobs) < free {
+			return false
+		}
+	}
+	return false
+}
+
+// start sends a claimed job in the background. Only Run's goroutine takes
+// slots, so the one taken here is always free.
+func (d *JobDispatcher) start(ctx, workCtx context.Context, job *model.PushJob) {
+	d.slots <- struct{}{}
+	d.running.Add(1)
+	go func() {
+		defer d.running.Done()
+		defer func() {
+			<-d.slots
+			select {
+			case d.freed <- struct{}{}:
+			default:
+			}
+		}()
+
+		if err := d.sender.processJob(workCtx, ctx.Done(), job); err != nil {
+			log.Printf("Failed to process job %s: %v", job.ID().String(), err)
+		}
+	}()
+}
+
+// drainEvents consumes the notifications that arrived during a dispatch.
+// Those already due were covered by it; future ones are remembered.
+func (d *JobDispatcher) drainEvents(events <-chan time.Time, wakeups *wakeupQueue, now time.Time) {
+	for {
+		select {
+		case readyAt, ok := <-events:
+			if !ok {
+				return
+			}
+			if readyAt.After(now) {
+				wakeups.add(readyAt)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func resetTimer(timer *time.Timer, at time.Time) {
+	timer.Stop()
+	timer.Reset(max(time.Until(at), 0))
+}
+
+// wakeupQueue is a min-heap of the times at which jobs become ready.
+type wakeupQueue []time.Time
+
+func (q wakeupQueue) Len() int           { return len(q) }
+func (q wakeupQueue) Less(i, j int) bool { return q[i].Before(q[j]) }
+func (q wakeupQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *wakeupQueue) Push(x any)        { *q = append(*q, x.(time.Time)) }
+
+func (q *wakeupQueue) Pop() any {
+	old := *q
+	t := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return t
+}
+
+func (q *wakeupQueue) add(t time.Time) {
+	if q.Len() >= maxWakeups {
+		return
+	}
+	heap.Push(q, t)
+}
+
+// drainDue drops the wake-ups at or before now.
+func (q *wakeupQueue) drainDue(now time.Time) {
+	for q.Len() > 0 && !(*q)[0].After(now) {
+		heap.Pop(q)
+	}
+}
+
+// earliest returns the first wake-up, or fallback if it is sooner.
+func (q wakeupQueue) earliest(fallback time.Time) time.Time {
+	if len(q) > 0 && q[0].Before(fallback) {
+		return q[0]
+	}
+	return fallback
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+)
+
+// nextSend waits for the push service to receive the next request and
+// returns when it did.
+func nextSend(t *testing.T, sends <-chan time.Time, timeout time.Duration) time.Time {
+	t.Helper()
+	select {
+	case sentAt := <-sends:
+		return sentAt
+	case <-time.After(timeout):
+		t.Fatalf("nothing was sent within %s", timeout)
+		return time.Time{}
+	}
+}
+
+func TestJobDispatcherRun(t *testing.T) {
+	ctx := context.Background()
+	sends := make(chan time.Time, 10)
+	f := newSenderFixture(t, PushSenderConfig{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sends <- time.Now()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/device")
+
+	spawner := NewPushScheduleSpawner(persistence.NewMemoryPushScheduleRepository(f.jobRepo), f.jobRepo, f.notifier, time.Hour)
+	// With an hour between polls, every send below is driven by a
+	// notification or a remembered ready time.
+	dispatcher := NewJobDispatcher(f.sender, spawner, f.jobRepo, f.notifier, time.Hour, 10)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(runCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Saved without a notification: found after the next dispatch.
+	unannouncedAt := time.Now().Add(400 * time.Millisecond)
+	f.addScheduledJob(t, &unannouncedAt)
+
+	f.addJob(t)
+	f.notifier.NotifyJobReady(ctx, time.Now())
+	nextSend(t, sends, 2*time.Second)
+
+	scheduledAt := time.Now().Add(200 * time.Millisecond)
+	f.addScheduledJob(t, &scheduledAt)
+	f.notifier.NotifyJobReady(ctx, scheduledAt)
+
+	// Each job has a single delivery, so sends arrive in ready order.
+	for _, tt := range []struct {
+		name    string
+		readyAt time.Time
+	}{
+		{"notified", scheduledAt},
+		{"unannounced", unannouncedAt},
+	} {
+		sentAt := nextSend(t, sends, 3*time.Second)
+		if sentAt.Before(tt.readyAt) {
+			t.Errorf("%s job was sent %s before its scheduled time", tt.name, tt.readyAt.Sub(sentAt))
+		}
+		if late := sentAt.Sub(tt.readyAt); late > 1500*time.Millisecond {
+			t.Errorf("%s job was sent %s late", tt.name, late)
+		}
+	}
+}
+
+func TestJobDispatcherDoesNotWaitForSlowJobs(t *testing.T) {
+	ctx := context.Background()
+	slowStarted := make(chan struct{}, 10)
+	unblock := make(chan struct{})
+	fastSent := make(chan time.Time, 10)
+	f := newSenderFixture(t, PushSenderConfig{JobConcurrency: 2}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/slow") {
+			slowStarted <- struct{}{}
+			select {
+			case <-unblock:
+			case <-r.Context().Done():
+				return
+			}
+		} else {
+			fastSent <- time.Now()
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	f.addSubscription(t, "https://updates.push.services.mozilla.com/wpush/v2/slow")
+
+	spawner := NewPushScheduleSpawner(persistence.NewMemoryPushScheduleRepository(f.jobRepo), f.jobRepo, f.notifier, time.Hour)
+	dispatcher := NewJobDispatcher(f.sender, spawner, f.jobRepo, f.notifier, time.Hour, 10)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(runCtx)
+		close(done)
+	}()
+	defer func() {
+		close(unblock)
+		cancel()
+		<-done
+	}()
+
+	f.addJob(t)
+	f.notifier.NotifyJobReady(ctx, time.Now())
+	select {
+	case <-slowStarted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow job was not started")
+	}
+
+	// The slow job is still being sent; a job notified now must not wait
+	// for it to finish.
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/fast")
+	f.addJob(t)
+	f.notifier.NotifyJobReady(ctx, time.Now())
+	nextSend(t, fastSent, 2*time.Second)
+}
+
+func TestJobDispatcherStopsOnCancel(t *testing.T) {
+	f := newSenderFixture(t, PushSenderConfig{}, http.NotFoundHandler())
+	spawner := NewPushScheduleSpawner(persistence.NewMemoryPushScheduleRepository(f.jobRepo), f.jobRepo, f.notifier, time.Hour)
+	dispatcher := NewJobDispatcher(f.sender, spawner, f.jobRepo, f.notifier, time.Hour, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+}
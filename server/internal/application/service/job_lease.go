@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+// releaseTimeout bounds releaseJob, which runs after processing was cancelled.
+const releaseTimeout = 5 * time.Second
+
+// newInstanceID returns an identifier that is unique per sender process and
+// readable enough to tell replicas apart in the push_jobs.lease_owner column.
+func newInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "sender"
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// keepLeaseAlive renews the job's lease every third of the lease duration
+// until ctx is cancelled. If the lease is lost, cancel is called so the
+// fan-out stops rather than racing with whoever reclaimed the job.
+func (pss *PushSenderService) keepLeaseAlive(ctx context.Context, job *model.PushJob, cancel context.CancelFunc) {
+	interval := pss.config.LeaseDuration / 3
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := pss.jobRepo.RenewLease(ctx, job.ID(), pss.instanceID, pss.config.LeaseDuration)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("Lost lease on job %s: %v", job.ID().String(), err)
+				cancel()
+				return
+			}
+			job.ExtendLease(time.Now().Add(pss.config.LeaseDuration))
+		}
+	}
+}
+
+// releaseJob hands a claimed job back to pending without using up a retry,
+// so another sender picks it up right away rather than after the lease
+// expires. ctx may already be cancelled.
+func (pss *PushSenderService) releaseJob(ctx context.Context, job *model.PushJob) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+
+	if err := pss.jobRepo.ReleaseLease(ctx, job.ID(), pss.instanceID); err != nil {
+		return fmt.Errorf("failed to release job lease: %w", err)
+	}
+	job.Release()
+	log.Printf("Released job %s back to pending", job.ID().String())
+	pss.notifyJobReady(ctx, job, time.Now())
+	return nil
+}
+
+// notifyJobReady wakes the dispatchers, including those of other replicas,
+// for a job that becomes ready again at readyAt. A missed notification only
+// delays the job until the next poll, so failures are logged.
+func (pss *PushSenderService) notifyJobReady(ctx context.Context, job *model.PushJob, readyAt time.Time) {
+	if err := pss.notifier.NotifyJobReady(ctx, readyAt); err != nil {
+		log.Printf("Failed to notify dispatchers of job %s: %v", job.ID().String(), err)
+	}
+}
+
+// drainContext returns a context that is cancelled grace after ctx is, so
+// work started before ctx was cancelled can finish within a deadline.
+func drainContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(grace, cancel)
+	})
+	return drainCtx, func() {
+		stop()
+		cancel()
+	}
+}
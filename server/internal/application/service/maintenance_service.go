@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+)
+
+// maintenanceLockName is the Locker name held while maintenance runs.
+const maintenanceLockName = "maintenance"
+
+// MaintenanceConfig holds the retention policy, in days, for each kind of
+// data that maintenance removes.
+type MaintenanceConfig struct {
+	LogRetentionDays int
+	// JobRetentionDays applies to succeeded, failed and cancelled jobs; their
+	// deliveries and events are removed with them.
+	JobRetentionDays int
+	// InvalidSubscriptionRetentionDays is how long a subscription rejected by
+	// its push service is kept, e.g. for the logs that reference it.
+	InvalidSubscriptionRetentionDays int
+}
+
+// MaintenanceReport counts what one maintenance run removed.
+type MaintenanceReport struct {
+	ExpiredSubscriptions int
+	InvalidSubscriptions int
+	CompletedJobs        int
+	Logs                 int
+}
+
+func (r MaintenanceReport) String() string {
+	return fmt.Sprintf("%d expired subscriptions, %d invalid subscriptions, %d completed jobs, %d logs",
+		r.ExpiredSubscriptions, r.InvalidSubscriptions, r.CompletedJobs, r.Logs)
+}
+
+// MaintenanceService removes expired and old data according to the retention
+// policy. Every replica may run it: a shared lock makes concurrent runs skip,
+// and each deletion is idempotent, so a run shortly after another is cheap.
+type MaintenanceService struct {
+	pushService *service.PushService
+	jobRepo     repository.PushJobRepository
+	logRepo     repository.PushLogRepository
+	locker      repository.Locker
+	config      MaintenanceConfig
+}
+
+func NewMaintenanceService(
+	pushService *service.PushService,
+	jobRepo repository.PushJobRepository,
+	logRepo repository.PushLogRepository,
+	locker repository.Locker,
+	config MaintenanceConfig,
+) *MaintenanceService {
+	return &MaintenanceService{
+		pushService: pushService,
+		jobRepo:     jobRepo,
+		logRepo:     logRepo,
+		locker:      locker,
+		config:      config,
+	}
+}
+
+// RunMaintenance runs every cleanup task and reports what was removed. It
+// returns a nil report when another replica is already running maintenance.
+// A failing task does not stop the others; their errors are joined.
+func (ms *MaintenanceService) RunMaintenance(ctx context.Context) (*MaintenanceReport, error) {
+	release, acquired, err := ms.locker.TryLock(ctx, maintenanceLockName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire maintenance lock: %w", err)
+	}
+	if !acquired {
+		return nil, nil
+	}
+	defer release()
+
+	report := &MaintenanceReport{}
+	var errs []error
+
+	report.ExpiredSubscriptions, err = ms.pushService.CleanupExpiredSubscriptions(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	report.InvalidSubscriptions, err = ms.pushService.CleanupInvalidSubscriptions(ctx, ms.config.InvalidSubscriptionRetentionDays)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	report.CompletedJobs, err = ms.jobRepo.DeleteOldCompletedJobs(ctx, ms.config.JobRetentionDays)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	report.Logs, err = ms.logRepo.DeleteOldLogs(ctx, ms.config.LogRetentionDays)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return report, errors.Join(errs...)
+}
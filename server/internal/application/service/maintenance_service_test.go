@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	domainService "github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+)
+
+type maintenanceFixture struct {
+	subscriptionRepo *persistence.MemoryPushSubscriptionRepository
+	jobRepo          *persistence.MemoryPushJobRepository
+	logRepo          *persistence.MemoryPushLogRepository
+	locker           *persistence.MemoryLocker
+	maintenance      *MaintenanceService
+}
+
+func newMaintenanceFixture() *maintenanceFixture {
+	f := &maintenanceFixture{
+		subscriptionRepo: persistence.NewMemoryPushSubscriptionRepository(),
+		jobRepo:          persistence.NewMemoryPushJobRepository(),
+		logRepo:          persistence.NewMemoryPushLogRepository(),
+		locker:           persistence.NewMemoryLocker(),
+	}
+	pushService := domainService.NewPushService(f.subscriptionRepo, f.jobRepo, persistence.NewMemoryNotificationPrefsRepository())
+	f.maintenance = NewMaintenanceService(pushService, f.jobRepo, f.logRepo, f.locker, MaintenanceConfig{
+		LogRetentionDays:                 30,
+		JobRetentionDays:                 7,
+		InvalidSubscriptionRetentionDays: 14,
+	})
+	return f
+}
+
+func (f *maintenanceFixture) addSubscription(t *testing.T, expiration *time.Time, valid bool, updatedAt time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	id, _ := f.subscriptionRepo.NextIdentity(ctx)
+	endpoint, _ := valueobject.NewPushEndpoint(fmt.Sprintf("https://fcm.googleapis.com/fcm/send/%d", id.Value()))
+	p256dh, _ := valueobject.NewP256dhKey("BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM")
+	auth, _ := valueobject.NewAuthKey("tBHItJI5svbpez7KI4CCXg")
+	subscription := model.ReconstructPushSubscription(id, valueobject.NewPublicSubscriptionID(), nil, endpoint,
+		valueobject.NewPushKeys(p256dh, auth), "", expiration, nil, valid, updatedAt, updatedAt, updatedAt)
+	if err := f.subscriptionRepo.Save(ctx, subscription); err != nil {
+		t.Fatalf("Save subscription: %v", err)
+	}
+}
+
+func (f *maintenanceFixture) addJob(t *testing.T, status model.JobStatus, updatedAt time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	id, _ := f.jobRepo.NextIdentity(ctx)
+	job := model.ReconstructPushJob(id, "", nil, "", model.UrgencyNormal, 60, model.PushPayload{"title": "hi"},
+		"", nil, nil, 0, nil, status, 0, "", nil, "", nil, updatedAt, updatedAt)
+	if err := f.jobRepo.Save(ctx, job); err != nil {
+		t.Fatalf("Save job: %v", err)
+	}
+}
+
+func (f *maintenanceFixture) addLog(t *testing.T, createdAt time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	id, _ := f.logRepo.NextIdentity(ctx)
+	if err := f.logRepo.Save(ctx, model.ReconstructPushLog(id, nil, nil, nil, nil, "", createdAt)); err != nil {
+		t.Fatalf("Save log: %v", err)
+	}
+}
+
+func TestRunMaintenance(t *testing.T) {
+	ctx := context.Background()
+	f := newMaintenanceFixture()
+	now := time.Now()
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+	expired := now.Add(-time.Hour)
+
+	f.addSubscription(t, nil, true, daysAgo(100))
+	f.addSubscription(t, &expired, true, now)
+	f.addSubscription(t, nil, false, daysAgo(20))
+	f.addSubscription(t, nil, false, daysAgo(3))
+
+	f.addJob(t, model.JobStatusSucceeded, daysAgo(10))
+	f.addJob(t, model.JobStatusFailed, daysAgo(10))
+	f.addJob(t, model.JobStatusCancelled, daysAgo(10))
+	f.addJob(t, model.JobStatusSucceeded, daysAgo(1))
+	f.addJob(t, model.JobStatusPending, daysAgo(10))
+
+	f.addLog(t, daysAgo(40))
+	f.addLog(t, daysAgo(10))
+
+	report, err := f.maintenance.RunMaintenance(ctx)
+	if err != nil {
+		t.Fatalf("RunMaintenance: %v", err)
+	}
+	want := MaintenanceReport{ExpiredSubscriptions: 1, InvalidSubscriptions: 1, CompletedJobs: 3, Logs: 1}
+	if report == nil || *report != want {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+
+	valid, _ := f.subscriptionRepo.FindValidSubscriptions(ctx)
+	if len(valid) != 1 {
+		t.Errorf("%d valid subscriptions remain, want 1", len(valid))
+	}
+	pending, _ := f.jobRepo.FindPendingJobs(ctx, 10)
+	if len(pending) != 1 {
+		t.Errorf("the pending job was removed")
+	}
+
+	report, err = f.maintenance.RunMaintenance(ctx)
+	if err != nil || report == nil || *report != (MaintenanceReport{}) {
+		t.Errorf("second run = %+v, %v; want an empty report", report, err)
+	}
+}
+
+func TestRunMaintenanceSkipsWhileLocked(t *testing.T) {
+	ctx := context.Background()
+	f := newMaintenanceFixture()
+	f.addLog(t, time.Now().Add(-60*24*time.Hour))
+
+	release, acquired, err := f.locker.TryLock(ctx, maintenanceLockName)
+	if err != nil || !acquired {
+		t.Fatalf("TryLock = %v, %v", acquired, err)
+	}
+
+	report, err := f.maintenance.RunMaintenance(ctx)
+	if err != nil || report != nil {
+		t.Fatalf("RunMaintenance while locked = %+v, %v; want nil report", report, err)
+	}
+
+	release()
+	report, err = f.maintenance.RunMaintenance(ctx)
+	if err != nil || report == nil || report.Logs != 1 {
+		t.Fatalf("RunMaintenance after release = %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// PushScheduleSpawner turns due schedule occurrences into push jobs. Every
+// replica may run it: each occurrence is recorded atomically with its job,
+// so only one replica spawns it.
+type PushScheduleSpawner struct {
+	scheduleRepo repository.PushScheduleRepository
+	jobRepo      repository.PushJobRepository
+	notifier     repository.JobNotifier
+	// misfireGrace is how late an occurrence may be spawned, e.g. after
+	// downtime. Older occurrences are skipped so a daily digest is not sent
+	// in the middle of the night.
+	misfireGrace time.Duration
+}
+
+func NewPushScheduleSpawner(
+	scheduleRepo repository.PushScheduleRepository,
+	jobRepo repository.PushJobRepository,
+	notifier repository.JobNotifier,
+	misfireGrace time.Duration,
+) *PushScheduleSpawner {
+	return &PushScheduleSpawner{
+		scheduleRepo: scheduleRepo,
+		jobRepo:      jobRepo,
+		notifier:     notifier,
+		misfireGrace: misfireGrace,
+	}
+}
+
+// SpawnDueSchedules spawns a job for up to limit due schedules and returns
+// how many jobs it created.
+func (s *PushScheduleSpawner) SpawnDueSchedules(ctx context.Context, limit int) (int, error) {
+	now := time.Now()
+	schedules, err := s.scheduleRepo.FindDueSchedules(ctx, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due schedules: %w", err)
+	}
+
+	spawned := 0
+	for _, schedule := range schedules {
+		ok, err := s.spawn(ctx, schedule, now)
+		if err != nil {
+			log.Printf("Failed to spawn schedule %s: %v", schedule.ID().String(), err)
+			continue
+		}
+		if ok {
+			spawned++
+		}
+	}
+	return spawned, nil
+}
+
+func (s *PushScheduleSpawner) spawn(ctx context.Context, schedule *model.PushSchedule, now time.Time) (bool, error) {
+	occurrence := *schedule.NextRunAt()
+
+	var job *model.PushJob
+	if lateness := now.Sub(occurrence); lateness > s.misfireGrace {
+		log.Printf("Skipping occurrence %s of schedule %s: %s late", occurrence.Format(time.RFC3339), schedule.ID().String(), lateness.Round(time.Second))
+	} else {
+		jobID, err := s.jobRepo.NextIdentity(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to generate job ID: %w", err)
+		}
+		job, err = schedule.NewJob(jobID)
+		if err != nil {
+			// The schedule was validated when it was created, so this
+			// occurrence is skipped rather than retried forever.
+			log.Printf("Skipping occurrence %s of schedule %s: %v", occurrence.Format(time.RFC3339), schedule.ID().String(), err)
+		}
+	}
+
+	var jobID *valueobject.JobID
+	if job != nil {
+		id := job.ID()
+		jobID = &id
+	}
+	schedule.Advance(now, jobID)
+
+	recorded, err := s.scheduleRepo.RecordRun(ctx, schedule, occurrence, job)
+	if err != nil {
+		return false, fmt.Errorf("failed to record schedule run: %w", err)
+	}
+	if recorded && schedule.NextRunAt() != nil {
+		if err := s.notifier.NotifyJobReady(ctx, *schedule.NextRunAt()); err != nil {
+			log.Printf("Failed to notify dispatchers of schedule %s: %v", schedule.ID().String(), err)
+		}
+	}
+	return recorded && job != nil, nil
+}
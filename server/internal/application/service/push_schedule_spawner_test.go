@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+)
+
+func saveDueSchedule(t *testing.T, repo *persistence.MemoryPushScheduleRepository, status model.ScheduleStatus, nextRunAt time.Time) *model.PushSchedule {
+	t.Helper()
+	ctx := context.Background()
+	id, _ := repo.NextIdentity(ctx)
+	cron, err := valueobject.NewCronSchedule("*/5 * * * *", "UTC")
+	if err != nil {
+		t.Fatalf("NewCronSchedule: %v", err)
+	}
+	now := time.Now()
+	schedule := model.ReconstructPushSchedule(id, "digest", cron, nil, "digest", model.UrgencyNormal, 3600,
+		model.PushPayload{"title": "Digest"}, "", nil, nil, status, &nextRunAt, nil, nil, now, now)
+	if err := repo.Save(ctx, schedule); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return schedule
+}
+
+func TestSpawnDueSchedules(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	scheduleRepo := persistence.NewMemoryPushScheduleRepository(jobRepo)
+	spawner := NewPushScheduleSpawner(scheduleRepo, jobRepo, persistence.NewMemoryJobNotifier(), time.Hour)
+
+	occurrence := time.Now().Add(-time.Minute).Truncate(time.Second)
+	due := saveDueSchedule(t, scheduleRepo, model.ScheduleStatusActive, occurrence)
+	missed := saveDueSchedule(t, scheduleRepo, model.ScheduleStatusActive, occurrence.Add(-3*time.Hour))
+	notYet := saveDueSchedule(t, scheduleRepo, model.ScheduleStatusActive, time.Now().Add(time.Hour))
+
+	spawned, err := spawner.SpawnDueSchedules(ctx, 10)
+	if err != nil {
+		t.Fatalf("SpawnDueSchedules: %v", err)
+	}
+	if spawned != 1 {
+		t.Fatalf("spawned %d jobs, want 1", spawned)
+	}
+
+	stored, _ := scheduleRepo.FindByID(ctx, due.ID())
+	if stored.LastJobID() == nil || !stored.LastRunAt().Equal(occurrence) || !stored.NextRunAt().After(time.Now()) {
+		t.Fatalf("due schedule: last job %v, last run %v, next %v", stored.LastJobID(), stored.LastRunAt(), stored.NextRunAt())
+	}
+	job, _ := jobRepo.FindByID(ctx, *stored.LastJobID())
+	if job == nil || job.IdempotencyKey() != due.OccurrenceKey() || !job.IsReadyToSend() {
+		t.Fatalf("spawned job = %+v", job)
+	}
+
+	// Occurrences older than the misfire grace are skipped, not sent late.
+	skipped, _ := scheduleRepo.FindByID(ctx, missed.ID())
+	if skipped.LastJobID() != nil || skipped.LastRunAt() == nil || !skipped.NextRunAt().After(time.Now()) {
+		t.Errorf("missed schedule: last job %v, last run %v, next %v", skipped.LastJobID(), skipped.LastRunAt(), skipped.NextRunAt())
+	}
+
+	untouched, _ := scheduleRepo.FindByID(ctx, notYet.ID())
+	if untouched.LastRunAt() != nil {
+		t.Error("a schedule that is not due was run")
+	}
+
+	if spawned, _ := spawner.SpawnDueSchedules(ctx, 10); spawned != 0 {
+		t.Errorf("second pass spawned %d jobs", spawned)
+	}
+}
+
+func TestSpawnDueSchedulesAcrossReplicas(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	scheduleRepo := persistence.NewMemoryPushScheduleRepository(jobRepo)
+	for i := 0; i < 5; i++ {
+		saveDueSchedule(t, scheduleRepo, model.ScheduleStatusActive, time.Now().Add(-time.Minute))
+	}
+	saveDueSchedule(t, scheduleRepo, model.ScheduleStatusPaused, time.Now().Add(-time.Minute))
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spawner := NewPushScheduleSpawner(scheduleRepo, jobRepo, persistence.NewMemoryJobNotifier(), time.Hour)
+			spawned, err := spawner.SpawnDueSchedules(ctx, 10)
+			if err != nil {
+				t.Errorf("SpawnDueSchedules: %v", err)
+			}
+			mu.Lock()
+			total += spawned
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if total != 5 {
+		t.Errorf("replicas spawned %d jobs in total, want 5", total)
+	}
+	jobs, _ := jobRepo.FindPendingJobs(ctx, 100)
+	if len(jobs) != 5 {
+		t.Errorf("%d jobs were saved, want 5", len(jobs))
+	}
+}
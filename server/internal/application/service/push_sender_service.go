@@ -2,10 +2,11 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	webpush "github.com/SherClockHolmes/webpush-go"
@@ -13,123 +14,580 @@ import (
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
 )
 
 type PushSenderService struct {
 	subscriptionRepo repository.PushSubscriptionRepository
 	jobRepo          repository.PushJobRepository
 	logRepo          repository.PushLogRepository
-	vapidService     *service.VAPIDService
+	deliveryRepo     repository.PushDeliveryRepository
+	templateRepo     repository.NotificationTemplateRepository
+	prefsRepo        repository.NotificationPrefsRepository
+	audience         *service.AudienceService
+	vapidKeys        *service.VAPIDKeyService
+	notifier         repository.JobNotifier
 	httpClient       *http.Client
+	config           PushSenderConfig
+	hostLimiter      *hostLimiter
+	throttle         *hostThrottle
+	instanceID       string
+	// deliveryClaims numbers the queued deliveries this sender claims, so
+	// each claim has its own lease owner.
+	deliveryClaims atomic.Uint64
 }
 
 func NewPushSenderService(
 	subscriptionRepo repository.PushSubscriptionRepository,
 	jobRepo repository.PushJobRepository,
 	logRepo repository.PushLogRepository,
-	vapidService *service.VAPIDService,
+	deliveryRepo repository.PushDeliveryRepository,
+	templateRepo repository.NotificationTemplateRepository,
+	prefsRepo repository.NotificationPrefsRepository,
+	audience *service.AudienceService,
+	vapidKeys *service.VAPIDKeyService,
+	notifier repository.JobNotifier,
 ) *PushSenderService {
+	return NewPushSenderServiceWithConfig(subscriptionRepo, jobRepo, logRepo, deliveryRepo, templateRepo, prefsRepo, audience, vapidKeys, notifier, DefaultPushSenderConfig())
+}
+
+func NewPushSenderServiceWithConfig(
+	subscriptionRepo repository.PushSubscriptionRepository,
+	jobRepo repository.PushJobRepository,
+	logRepo repository.PushLogRepository,
+	deliveryRepo repository.PushDeliveryRepository,
+	templateRepo repository.NotificationTemplateRepository,
+	prefsRepo repository.NotificationPrefsRepository,
+	audience *service.AudienceService,
+	vapidKeys *service.VAPIDKeyService,
+	notifier repository.JobNotifier,
+	config PushSenderConfig,
+) *PushSenderService {
+	config = config.withDefaults()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = config.PerHostConcurrency
+
 	return &PushSenderService{
 		subscriptionRepo: subscriptionRepo,
 		jobRepo:          jobRepo,
 		logRepo:          logRepo,
-		vapidService:     vapidService,
+		deliveryRepo:     deliveryRepo,
+		templateRepo:     templateRepo,
+		prefsRepo:        prefsRepo,
+		audience:         audience,
+		vapidKeys:        vapidKeys,
+		notifier:         notifier,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
+		config:      config,
+		hostLimiter: newHostLimiter(config.PerHostConcurrency),
+		throttle:    newHostThrottle(),
+		instanceID:  config.InstanceID,
+	}
+}
+
+// ProcessPendingJobs claims up to batchSize ready jobs, delivers them and
+// returns how many it claimed. Cancelling ctx stops it from starting new
+// jobs or deliveries; deliveries already in flight get up to DrainTimeout to
+// finish, and jobs left unfinished are returned to pending for the next
+// sender.
+func (pss *PushSenderService) ProcessPendingJobs(ctx context.Context, batchSize int) (int, error) {
+	jobs, err := pss.claimReadyJobs(ctx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	pss.processJobs(ctx, jobs, "process")
+
+	return len(jobs), nil
+}
+
+// claimReadyJobs leases up to limit ready jobs to this sender.
+func (pss *PushSenderService) claimReadyJobs(ctx context.Context, limit int) ([]*model.PushJob, error) {
+	jobs, err := pss.jobRepo.ClaimReadyJobs(ctx, pss.instanceID, pss.config.LeaseDuration, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim ready jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// processJobs runs processJob for each job with at most JobConcurrency jobs in
+// flight at once. Once ctx is cancelled the jobs not started yet are released
+// and the started ones run on a context that outlives ctx by DrainTimeout.
+func (pss *PushSenderService) processJobs(ctx context.Context, jobs []*model.PushJob, action string) {
+	workCtx, cancel := drainContext(ctx, pss.config.DrainTimeout)
+	defer cancel()
+
+	sem := make(chan struct{}, pss.config.JobConcurrency)
+	var wg sync.WaitGroup
+
+	for i, job := range jobs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			for _, job := range jobs[i:] {
+				if err := pss.releaseJob(workCtx, job); err != nil {
+					log.Printf("Failed to release job %s: %v", job.ID().String(), err)
+				}
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(job *model.PushJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := pss.processJob(workCtx, ctx.Done(), job); err != nil {
+				log.Printf("Failed to %s job %s: %v", action, job.ID().String(), err)
+			}
+		}(job)
 	}
+
+	wg.Wait()
 }
 
-func (pss *PushSenderService) ProcessPendingJobs(ctx context.Context, batchSize int) error {
-	jobs, err := pss.jobRepo.FindReadyToSendJobs(ctx, batchSize)
+// processJob delivers a job that has already been claimed by this sender.
+// Deliveries are expanded once per job; later runs only retry the ones that
+// are still pending and due. When stopping is closed no further deliveries
+// are started and the job is released once those in flight have finished.
+func (pss *PushSenderService) processJob(ctx context.Context, stopping <-chan struct{}, job *model.PushJob) error {
+	counts, err := pss.deliveryRepo.CountByJobID(ctx, job.ID())
+	if err != nil {
+		return pss.rescheduleJob(ctx, job, fmt.Errorf("failed to count deliveries: %w", err))
+	}
+
+	subscriptions, err := pss.targetSubscriptions(ctx, job, counts.Total() == 0)
+	if err != nil {
+		return pss.rescheduleJob(ctx, job, err)
+	}
+
+	if counts.Total() == 0 {
+		subscriptionIDs := make([]valueobject.SubscriptionID, len(subscriptions))
+		for i, subscription := range subscriptions {
+			subscriptionIDs[i] = subscription.ID()
+		}
+		if err := pss.deliveryRepo.CreateForJob(ctx, job.ID(), subscriptionIDs); err != nil {
+			return pss.rescheduleJob(ctx, job, fmt.Errorf("failed to create deliveries: %w", err))
+		}
+	}
+
+	due, err := pss.deliveryRepo.FindDueByJobID(ctx, job.ID(), time.Now())
+	if err != nil {
+		return pss.rescheduleJob(ctx, job, fmt.Errorf("failed to find due deliveries: %w", err))
+	}
+
+	byID := make(map[valueobject.SubscriptionID]*model.PushSubscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		byID[subscription.ID()] = subscription
+	}
+
+	targets := make([]deliveryTarget, 0, len(due))
+	for _, delivery := range due {
+		subscription, ok := byID[delivery.SubscriptionID()]
+		if !ok {
+			delivery.MarkAsGone(nil, "subscription is no longer valid")
+			if err := pss.deliveryRepo.Save(ctx, delivery); err != nil {
+				log.Printf("Failed to save delivery %d: %v", delivery.ID(), err)
+			}
+			continue
+		}
+		targets = append(targets, deliveryTarget{delivery: delivery, subscription: subscription})
+	}
+
+	targets, err = pss.applyPreferences(ctx, job, targets)
 	if err != nil {
-		return fmt.Errorf("failed to fetch ready jobs: %w", err)
+		return pss.rescheduleJob(ctx, job, err)
 	}
 
-	for _, job := range jobs {
-		err := pss.processJob(ctx, job)
+	if len(targets) > 0 {
+		payload, err := pss.renderPayload(ctx, job)
 		if err != nil {
-			log.Printf("Failed to process job %s: %v", job.ID().String(), err)
+			return err
+		}
+
+		if pss.config.Queue != nil {
+			return pss.enqueueDeliveries(ctx, job, targets)
+		}
+
+		leaseCtx, cancel := context.WithCancel(ctx)
+		go pss.keepLeaseAlive(leaseCtx, job, cancel)
+		dispatched := pss.fanOut(leaseCtx, stopping, job, payload, targets)
+		leaseLost := leaseCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+
+		if leaseLost {
+			return fmt.Errorf("lease lost during delivery; leaving job to its new owner")
+		}
+		if !dispatched || ctx.Err() != nil {
+			return pss.releaseJob(ctx, job)
 		}
 	}
 
-	return nil
+	return pss.finishJob(ctx, job)
 }
 
-func (pss *PushSenderService) processJob(ctx context.Context, job *model.PushJob) error {
-	job.MarkAsSending()
-	err := pss.jobRepo.Save(ctx, job)
+// applyPreferences drops deliveries to users who opted out of the job's
+// topic and defers those inside the user's quiet hours to the end of the
+// window. It returns the targets that can be sent now.
+func (pss *PushSenderService) applyPreferences(ctx context.Context, job *model.PushJob, targets []deliveryTarget) ([]deliveryTarget, error) {
+	seen := make(map[valueobject.UserID]bool)
+	var userIDs []valueobject.UserID
+	for _, target := range targets {
+		if userID := target.subscription.UserID(); userID != nil && !seen[*userID] {
+			seen[*userID] = true
+			userIDs = append(userIDs, *userID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return targets, nil
+	}
+
+	prefsByUser, err := pss.prefsRepo.FindByUserIDs(ctx, userIDs)
 	if err != nil {
-		return fmt.Errorf("failed to mark job as sending: %w", err)
+		return nil, fmt.Errorf("failed to get notification prefs: %w", err)
+	}
+
+	now := time.Now()
+	allowed := targets[:0]
+	for _, target := range targets {
+		var prefs *model.NotificationPrefs
+		if userID := target.subscription.UserID(); userID != nil {
+			prefs = prefsByUser[*userID]
+		}
+
+		switch until, quiet := quietUntil(prefs, now); {
+		case prefs != nil && !prefs.AllowsTopic(job.Topic()):
+			target.delivery.MarkAsSkipped("user opted out of this notification topic")
+		case quiet:
+			target.delivery.Defer(until, "quiet hours")
+		default:
+			allowed = append(allowed, target)
+			continue
+		}
+
+		if err := pss.deliveryRepo.Save(ctx, target.delivery); err != nil {
+			log.Printf("Failed to save delivery %d: %v", target.delivery.ID(), err)
+		}
 	}
+	return allowed, nil
+}
 
-	var subscriptions []*model.PushSubscription
+func quietUntil(prefs *model.NotificationPrefs, now time.Time) (time.Time, bool) {
+	if prefs == nil {
+		return time.Time{}, false
+	}
+	return prefs.QuietUntil(now)
+}
+
+// renderPayload builds the payload once per job run. Templates are rendered
+// at send time so edits apply to jobs that have not gone out yet. A missing
+// template or variable fails the job for good; a lookup error is retried
+// later.
+func (pss *PushSenderService) renderPayload(ctx context.Context, job *model.PushJob) (model.PushPayload, error) {
+	payload, permanent, err := pss.buildPayload(ctx, job)
+	switch {
+	case err == nil:
+		return payload, nil
+	case permanent:
+		return nil, pss.failJob(ctx, job, err)
+	default:
+		return nil, pss.rescheduleJob(ctx, job, err)
+	}
+}
 
-	if job.UserID() != nil {
-		userSubs, err := pss.subscriptionRepo.FindValidSubscriptionsByUserID(ctx, *job.UserID())
+// buildPayload renders the job's payload and reports whether a failure is
+// permanent, i.e. retrying cannot fix it.
+func (pss *PushSenderService) buildPayload(ctx context.Context, job *model.PushJob) (model.PushPayload, bool, error) {
+	payload := job.Payload()
+
+	if job.TemplateKey() != "" {
+		template, err := pss.templateRepo.FindByKey(ctx, job.TemplateKey())
 		if err != nil {
-			job.MarkAsFailed(fmt.Sprintf("Failed to get user subscriptions: %v", err))
-			pss.jobRepo.Save(ctx, job)
-			return fmt.Errorf("failed to get user subscriptions: %w", err)
+			return nil, false, fmt.Errorf("failed to find notification template: %w", err)
 		}
-		subscriptions = userSubs
-	} else {
-		allSubs, err := pss.subscriptionRepo.FindValidSubscriptions(ctx)
+		if template == nil {
+			return nil, true, fmt.Errorf("notification template %q not found", job.TemplateKey())
+		}
+
+		payload, err = job.RenderPayload(template)
 		if err != nil {
-			job.MarkAsFailed(fmt.Sprintf("Failed to get all subscriptions: %v", err))
-			pss.jobRepo.Save(ctx, job)
-			return fmt.Errorf("failed to get all subscriptions: %w", err)
+			return nil, true, fmt.Errorf("failed to render template %q: %w", job.TemplateKey(), err)
 		}
-		subscriptions = allSubs
 	}
 
-	if len(subscriptions) == 0 {
-		job.MarkAsSucceeded()
-		pss.jobRepo.Save(ctx, job)
-		return nil
+	if _, err := payload.ToJSON(); err != nil {
+		return nil, true, fmt.Errorf("failed to marshal payload: %w", err)
 	}
+	return payload, false, nil
+}
 
-	successCount := 0
-	failureCount := 0
+// failJob marks the job failed without retrying; used when retrying cannot
+// help (e.g. its template was deleted).
+func (pss *PushSenderService) failJob(ctx context.Context, job *model.PushJob, cause error) error {
+	job.MarkAsFailed(cause.Error())
+	if err := pss.jobRepo.SaveClaimed(ctx, job, pss.instanceID); err != nil {
+		log.Printf("Failed to save job %s: %v", job.ID().String(), err)
+	}
+	return cause
+}
 
-	for _, subscription := range subscriptions {
-		success, err := pss.sendToSubscription(ctx, job, subscription)
+// targetSubscriptions returns the valid subscriptions the job is for. The
+// segment is only evaluated when the deliveries are expanded; later runs
+// retry the original audience even if it no longer matches.
+func (pss *PushSenderService) targetSubscriptions(ctx context.Context, job *model.PushJob, expanding bool) ([]*model.PushSubscription, error) {
+	target := service.AudienceTarget{UserID: job.UserID()}
+	if expanding {
+		target.Segment = job.Segment()
+	}
+	if job.IsBatch() {
+		// Recipients are removed with their users; with none left the job
+		// finishes without deliveries.
+		recipients, err := pss.jobRepo.FindRecipients(ctx, job.ID())
 		if err != nil {
-			log.Printf("Failed to send to subscription %s: %v", subscription.ID().String(), err)
-			failureCount++
-		} else if success {
-			successCount++
-		} else {
-			failureCount++
+			return nil, fmt.Errorf("failed to get batch recipients: %w", err)
 		}
+		target.Batch = true
+		target.Recipients = recipients
+	}
+	return pss.audience.Resolve(ctx, target)
+}
+
+// finishJob derives the job status from its deliveries: pending deliveries
+// put the job back to pending until the earliest retry, otherwise the job
+// succeeded when at least one delivery did (or there was nobody to send to).
+func (pss *PushSenderService) finishJob(ctx context.Context, job *model.PushJob) error {
+	counts, err := pss.deliveryRepo.CountByJobID(ctx, job.ID())
+	if err != nil {
+		return pss.rescheduleJob(ctx, job, fmt.Errorf("failed to count deliveries: %w", err))
 	}
 
-	if successCount > 0 && failureCount == 0 {
+	switch {
+	case counts.Pending > 0:
+		next, err := pss.deliveryRepo.NextAttemptAt(ctx, job.ID())
+		if err != nil {
+			return pss.rescheduleJob(ctx, job, fmt.Errorf("failed to find next delivery attempt: %w", err))
+		}
+		nextAttemptAt := time.Now()
+		if next != nil {
+			nextAttemptAt = *next
+		}
+		job.AwaitDeliveries(nextAttemptAt)
+	case counts.Succeeded > 0 || counts.Total() == counts.Skipped:
 		job.MarkAsSucceeded()
-	} else if successCount == 0 && failureCount > 0 {
-		job.MarkAsFailed(fmt.Sprintf("All %d deliveries failed", failureCount))
+		if counts.Failed+counts.Gone > 0 {
+			log.Printf("Job %s completed with partial success: %d succeeded, %d failed, %d gone, %d skipped",
+				job.ID().String(), counts.Succeeded, counts.Failed, counts.Gone, counts.Skipped)
+		}
+	default:
+		job.MarkAsFailed(fmt.Sprintf("All %d deliveries failed", counts.Total()))
+	}
+
+	if err := pss.jobRepo.SaveClaimed(ctx, job, pss.instanceID); err != nil {
+		return err
+	}
+	if job.Status() == model.JobStatusPending {
+		pss.notifyJobReady(ctx, job, *job.NextAttemptAt())
+	}
+	return nil
+}
+
+// rescheduleJob handles errors that prevented the job from being processed
+// at all (e.g. the database was unavailable). The job is retried later and
+// fails for good after MaxDeliveryAttempts such failures; rounds that only
+// left deliveries pending do not count.
+func (pss *PushSenderService) rescheduleJob(ctx context.Context, job *model.PushJob, cause error) error {
+	if ctx.Err() != nil {
+		// Interrupted by shutdown rather than a real failure.
+		if err := pss.releaseJob(ctx, job); err != nil {
+			log.Printf("Failed to release job %s: %v", job.ID().String(), err)
+		}
+		return cause
+	}
+
+	if job.RetryCount()+1 >= pss.config.MaxDeliveryAttempts {
+		job.MarkAsFailed(cause.Error())
 	} else {
-		job.MarkAsSucceeded()
-		log.Printf("Job %s completed with partial success: %d succeeded, %d failed",
-			job.ID().String(), successCount, failureCount)
+		job.ScheduleRetry(time.Now().Add(pss.calculateBackoffDelay(job.RetryCount())), cause.Error())
+	}
+
+	if err := pss.jobRepo.SaveClaimed(ctx, job, pss.instanceID); err != nil {
+		log.Printf("Failed to save job %s: %v", job.ID().String(), err)
+	} else if job.Status() == model.JobStatusPending {
+		pss.notifyJobReady(ctx, job, *job.NextAttemptAt())
 	}
+	return cause
+}
 
-	return pss.jobRepo.Save(ctx, job)
+type deliveryTarget struct {
+	delivery     *model.PushDelivery
+	subscription *model.PushSubscription
+	// leaseOwner is set when a queue worker claimed the delivery; its
+	// outcome is then only saved while the claim still holds.
+	leaseOwner string
 }
 
+// fanOut sends the due deliveries using a bounded pool of workers,
+// respecting the per-host concurrency limit. It stops handing out deliveries
+// when stopping is closed or ctx is cancelled, and reports whether every
+// target was handed out.
+func (pss *PushSenderService) fanOut(ctx context.Context, stopping <-chan struct{}, job *model.PushJob, payload model.PushPayload, targets []deliveryTarget) bool {
+	queue := make(chan deliveryTarget)
+	workers := min(pss.config.Workers, len(targets))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range queue {
+				pss.deliver(ctx, job, payload, target)
+			}
+		}()
+	}
+
+	dispatched := true
+dispatch:
+	for _, target := range targets {
+		select {
+		case queue <- target:
+		case <-stopping:
+			dispatched = false
+			break dispatch
+		case <-ctx.Done():
+			dispatched = false
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return dispatched
+}
+
+func (pss *PushSenderService) deliver(ctx context.Context, job *model.PushJob, payload model.PushPayload, target deliveryTarget) {
+	delivery, subscription := target.delivery, target.subscription
+	host := endpointHost(subscription.Endpoint().Value())
+
+	if pss.deferIfThrottled(ctx, target, host) {
+		return
+	}
+
+	release, err := pss.hostLimiter.acquire(ctx, host)
+	if err != nil {
+		log.Printf("Failed to send to subscription %s: %v", subscription.ID().String(), err)
+		return
+	}
+	defer release()
+
+	// The host may have started throttling while we waited for a slot.
+	if pss.deferIfThrottled(ctx, target, host) {
+		return
+	}
+
+	result, err := pss.sendToSubscription(ctx, job, payload, subscription)
+	if ctx.Err() != nil {
+		// Interrupted (lease lost or shutting down); the delivery stays
+		// pending without consuming an attempt.
+		return
+	}
+
+	statusCode := result.statusCode
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		delivery.MarkAsSucceeded(statusCode)
+	case statusCode == http.StatusNotFound || statusCode == http.StatusGone:
+		delivery.MarkAsGone(&statusCode, fmt.Sprintf("push service responded with status %d", statusCode))
+	case isThrottleStatus(statusCode):
+		now := time.Now()
+		until := now.Add(pss.config.throttleDelay(result.retryAfter, now))
+		pss.throttle.pause(host, until)
+		log.Printf("Push service %s throttled delivery (status %d); pausing until %s",
+			host, statusCode, until.Format(time.RFC3339))
+		delivery.MarkAttemptFailed(&statusCode, fmt.Sprintf("push service responded with status %d", statusCode),
+			until, pss.config.MaxDeliveryAttempts)
+	default:
+		var code *int
+		if statusCode != 0 {
+			code = &statusCode
+		}
+		reason := fmt.Sprintf("push service responded with status %d", statusCode)
+		if err != nil {
+			reason = err.Error()
+			log.Printf("Failed to send to subscription %s: %v", subscription.ID().String(), err)
+		}
+		nextAttemptAt := time.Now().Add(pss.calculateBackoffDelay(delivery.AttemptCount()))
+		delivery.MarkAttemptFailed(code, reason, nextAttemptAt, pss.config.MaxDeliveryAttempts)
+	}
+
+	if err := pss.saveDelivery(ctx, target); err != nil {
+		log.Printf("Failed to save delivery %d: %v", delivery.ID(), err)
+	}
+}
+
+// deferIfThrottled reschedules the delivery to the end of the host's pause
+// and reports whether it did.
+func (pss *PushSenderService) deferIfThrottled(ctx context.Context, target deliveryTarget, host string) bool {
+	until, paused := pss.throttle.until(host, time.Now())
+	if !paused {
+		return false
+	}
+
+	target.delivery.Defer(until, fmt.Sprintf("push service %s is throttling", host))
+	if err := pss.saveDelivery(ctx, target); err != nil {
+		log.Printf("Failed to save delivery %d: %v", target.delivery.ID(), err)
+	}
+	return true
+}
+
+// saveDelivery saves the target's delivery, fenced on its lease when a queue
+// worker claimed it.
+func (pss *PushSenderService) saveDelivery(ctx context.Context, target deliveryTarget) error {
+	if target.leaseOwner != "" {
+		return pss.deliveryRepo.SaveClaimed(ctx, target.delivery, target.leaseOwner)
+	}
+	return pss.deliveryRepo.Save(ctx, target.delivery)
+}
+
+type sendResult struct {
+	// statusCode is 0 when no response was received.
+	statusCode int
+	retryAfter string
+}
+
+// sendToSubscription sends the payload, tagged with the IDs the Service
+// Worker reports engagement events with, and returns what the push service
+// answered.
 func (pss *PushSenderService) sendToSubscription(
 	ctx context.Context,
 	job *model.PushJob,
+	payload model.PushPayload,
 	subscription *model.PushSubscription,
-) (bool, error) {
-	payload, err := json.Marshal(job.Payload())
+) (sendResult, error) {
+	encoded, err := payload.WithTracking(job.ID(), subscription.PublicID()).ToJSON()
 	if err != nil {
-		return false, fmt.Errorf("failed to marshal payload: %w", err)
+		return sendResult{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	vapidKey, err := pss.vapidKeys.KeyForSubscription(ctx, subscription)
+	if err != nil {
+		return sendResult{}, fmt.Errorf("failed to resolve VAPID key: %w", err)
+	}
+
+	subject := vapidKey.Subject()
+	if subject.IsZero() {
+		subject = pss.config.VAPIDSubject
 	}
 
 	options := &webpush.Options{
-		Subscriber:      "mailto:support@example.com",
-		VAPIDPrivateKey: pss.vapidService.GetPrivateKey(),
+		Subscriber:      subject.Subscriber(),
+		HTTPClient:      pss.httpClient,
+		VAPIDPublicKey:  vapidKey.PublicKey(),
+		VAPIDPrivateKey: vapidKey.PrivateKey(),
 		TTL:             job.TTLSeconds(),
 		Urgency:         webpush.Urgency(job.Urgency()),
 	}
@@ -146,7 +604,7 @@ func (pss *PushSenderService) sendToSubscription(
 		},
 	}
 
-	resp, err := webpush.SendNotificationWithContext(ctx, payload, webpushSubscription, options)
+	resp, err := webpush.SendNotificationWithContext(ctx, encoded, webpushSubscription, options)
 
 	logID, _ := pss.logRepo.NextIdentity(ctx)
 
@@ -162,7 +620,7 @@ func (pss *PushSenderService) sendToSubscription(
 			fmt.Sprintf("Send error: %v", err),
 		)
 		pss.logRepo.Save(ctx, pushLog)
-		return false, err
+		return sendResult{}, err
 	}
 
 	defer resp.Body.Close()
@@ -187,55 +645,26 @@ func (pss *PushSenderService) sendToSubscription(
 
 	pss.logRepo.Save(ctx, pushLog)
 
+	result := sendResult{
+		statusCode: resp.StatusCode,
+		retryAfter: resp.Header.Get("Retry-After"),
+	}
+
 	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
 		subscription.MarkAsInvalid()
 		pss.subscriptionRepo.Save(ctx, subscription)
 		log.Printf("Marked subscription %s as invalid due to %d response",
 			subscription.ID().String(), resp.StatusCode)
-		return false, nil
+		return result, nil
 	}
 
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		return true, nil
-	}
-
-	return false, fmt.Errorf("push service responded with status %d", resp.StatusCode)
-}
-
-func (pss *PushSenderService) ProcessRetries(ctx context.Context, maxRetries, batchSize int) error {
-	jobs, err := pss.jobRepo.FindFailedJobsForRetry(ctx, maxRetries, batchSize)
-	if err != nil {
-		return fmt.Errorf("failed to fetch failed jobs for retry: %w", err)
-	}
-
-	for _, job := range jobs {
-		backoffDelay := pss.calculateBackoffDelay(job.RetryCount())
-
-		if time.Since(job.UpdatedAt()) < backoffDelay {
-			continue
-		}
-
-		err := pss.processJob(ctx, job)
-		if err != nil {
-			log.Printf("Failed to retry job %s: %v", job.ID().String(), err)
-		}
+		return result, nil
 	}
 
-	return nil
+	return result, fmt.Errorf("push service responded with status %d", resp.StatusCode)
 }
 
 func (pss *PushSenderService) calculateBackoffDelay(retryCount int) time.Duration {
-	baseDelay := 30 * time.Second
-	maxDelay := 6 * time.Hour
-
-	delay := baseDelay
-	for i := 0; i < retryCount; i++ {
-		delay *= 2
-		if delay > maxDelay {
-			delay = maxDelay
-			break
-		}
-	}
-
-	return delay
+	return pss.config.backoffDelay(retryCount, nil)
 }
@@ -0,0 +1,722 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+)
+
+// rewriteTransport sends every request to target while keeping the original
+// Host, so subscriptions can use real push service endpoints in tests.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Host = req.URL.Host
+	out.URL.Scheme = t.target.Scheme
+	out.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(out)
+}
+
+type senderFixture struct {
+	sender           *PushSenderService
+	subscriptionRepo *persistence.MemoryPushSubscriptionRepository
+	jobRepo          *persistence.MemoryPushJobRepository
+	logRepo          *persistence.MemoryPushLogRepository
+	deliveryRepo     *persistence.MemoryPushDeliveryRepository
+	templateRepo     *persistence.MemoryNotificationTemplateRepository
+	prefsRepo        *persistence.MemoryNotificationPrefsRepository
+	userRepo         *persistence.MemoryUserRepository
+	vapidKeys        *service.VAPIDKeyService
+	notifier         *persistence.MemoryJobNotifier
+}
+
+func newSenderFixture(t *testing.T, config PushSenderConfig, handler http.Handler) *senderFixture {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+
+	vapidKeys := service.NewVAPIDKeyService(persistence.NewMemoryVAPIDKeyRepository())
+	if _, err := vapidKeys.EnsureActiveKey(context.Background(), nil); err != nil {
+		t.Fatalf("EnsureActiveKey: %v", err)
+	}
+
+	f := &senderFixture{
+		subscriptionRepo: persistence.NewMemoryPushSubscriptionRepository(),
+		jobRepo:          persistence.NewMemoryPushJobRepository(),
+		logRepo:          persistence.NewMemoryPushLogRepository(),
+		deliveryRepo:     persistence.NewMemoryPushDeliveryRepository(),
+		prefsRepo:        persistence.NewMemoryNotificationPrefsRepository(),
+		userRepo:         persistence.NewMemoryUserRepository(),
+		vapidKeys:        vapidKeys,
+		notifier:         persistence.NewMemoryJobNotifier(),
+	}
+	f.templateRepo = persistence.NewMemoryNotificationTemplateRepository(f.jobRepo, persistence.NewMemoryPushScheduleRepository(f.jobRepo))
+	audience := service.NewAudienceService(f.subscriptionRepo, f.userRepo, f.prefsRepo)
+	f.sender = NewPushSenderServiceWithConfig(f.subscriptionRepo, f.jobRepo, f.logRepo, f.deliveryRepo, f.templateRepo, f.prefsRepo, audience, vapidKeys, f.notifier, config)
+	f.sender.httpClient = &http.Client{Transport: rewriteTransport{target: target}}
+	return f
+}
+
+func (f *senderFixture) addSubscription(t *testing.T, endpoint string) *model.PushSubscription {
+	t.Helper()
+	return f.addUserSubscription(t, endpoint, nil)
+}
+
+func (f *senderFixture) addUserSubscription(t *testing.T, endpoint string, userID *valueobject.UserID) *model.PushSubscription {
+	t.Helper()
+	ctx := context.Background()
+
+	browserKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	ep, err := valueobject.NewPushEndpoint(endpoint)
+	if err != nil {
+		t.Fatalf("NewPushEndpoint: %v", err)
+	}
+	p256dh, _ := valueobject.NewP256dhKey(base64.RawURLEncoding.EncodeToString(browserKey.PublicKey().Bytes()))
+	auth, _ := valueobject.NewAuthKey(base64.RawURLEncoding.EncodeToString(authSecret))
+
+	activeKey, err := f.vapidKeys.ActiveKey(ctx)
+	if err != nil {
+		t.Fatalf("ActiveKey: %v", err)
+	}
+
+	id, _ := f.subscriptionRepo.NextIdentity(ctx)
+	subscription := model.NewPushSubscription(id, userID, ep, valueobject.NewPushKeys(p256dh, auth), "", nil)
+	subscription.AssignVAPIDKey(activeKey.ID())
+	if err := f.subscriptionRepo.Save(ctx, subscription); err != nil {
+		t.Fatalf("Save subscription: %v", err)
+	}
+	return subscription
+}
+
+func (f *senderFixture) addJob(t *testing.T) *model.PushJob {
+	t.Helper()
+	return f.addScheduledJob(t, nil)
+}
+
+func (f *senderFixture) addScheduledJob(t *testing.T, scheduleAt *time.Time) *model.PushJob {
+	t.Helper()
+	ctx := context.Background()
+
+	id, _ := f.jobRepo.NextIdentity(ctx)
+	job, err := model.NewPushJob(id, "", nil, "", model.UrgencyNormal, 60, model.PushPayload{"title": "hi"}, scheduleAt)
+	if err != nil {
+		t.Fatalf("NewPushJob: %v", err)
+	}
+	if err := f.jobRepo.Save(ctx, job); err != nil {
+		t.Fatalf("Save job: %v", err)
+	}
+	return job
+}
+
+func TestProcessPendingJobsFanOut(t *testing.T) {
+	const perHost = 2
+
+	var (
+		mu       sync.Mutex
+		inFlight = map[string]int{}
+		maxSeen  = map[string]int{}
+		hits     = map[string]int{}
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight[r.Host]++
+		hits[r.Host]++
+		if inFlight[r.Host] > maxSeen[r.Host] {
+			maxSeen[r.Host] = inFlight[r.Host]
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight[r.Host]--
+		mu.Unlock()
+
+		if r.Host == "updates.push.services.mozilla.com" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{Workers: 8, PerHostConcurrency: perHost, JobConcurrency: 2}, handler)
+
+	for i := 0; i < 10; i++ {
+		f.addSubscription(t, fmt.Sprintf("https://fcm.googleapis.com/fcm/send/%d", i))
+	}
+	for i := 0; i < 3; i++ {
+		f.addSubscription(t, fmt.Sprintf("https://updates.push.services.mozilla.com/wpush/v2/%d", i))
+	}
+	job := f.addJob(t)
+
+	if _, err := f.sender.ProcessPendingJobs(context.Background(), 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	if hits["fcm.googleapis.com"] != 10 || hits["updates.push.services.mozilla.com"] != 3 {
+		t.Fatalf("unexpected hits: %v", hits)
+	}
+	for host, n := range maxSeen {
+		if n > perHost {
+			t.Errorf("host %s saw %d concurrent requests, limit %d", host, n, perHost)
+		}
+	}
+
+	successes, err := f.logRepo.CountSuccessByJobID(context.Background(), job.ID())
+	if err != nil {
+		t.Fatalf("CountSuccessByJobID: %v", err)
+	}
+	if successes != 10 {
+		t.Fatalf("successes = %d, want 10", successes)
+	}
+
+	valid, _ := f.subscriptionRepo.FindValidSubscriptions(context.Background())
+	if len(valid) != 10 {
+		t.Fatalf("valid subscriptions = %d, want 10 after 410 responses", len(valid))
+	}
+
+	saved, _ := f.jobRepo.FindByID(context.Background(), job.ID())
+	if saved.Status() != model.JobStatusSucceeded {
+		t.Fatalf("job status = %s, want succeeded", saved.Status())
+	}
+}
+
+func TestProcessPendingJobsRetriesOnlyFailedDeliveries(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		hits = map[string]int{}
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		n := hits[r.URL.Path]
+		mu.Unlock()
+
+		if r.URL.Path == "/fcm/send/flaky" && n == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{Workers: 4, PerHostConcurrency: 4, JobConcurrency: 1}, handler)
+	ctx := context.Background()
+
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/ok")
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/flaky")
+	job := f.addJob(t)
+
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusPending || saved.NextAttemptAt() == nil {
+		t.Fatalf("job status = %s, want pending with a next attempt", saved.Status())
+	}
+	counts, _ := f.deliveryRepo.CountByJobID(ctx, job.ID())
+	if counts.Succeeded != 1 || counts.Pending != 1 {
+		t.Fatalf("counts after first run = %+v", counts)
+	}
+
+	// Make the retry due now instead of waiting for the backoff.
+	past := time.Now().Add(-time.Second)
+	deliveries, _ := f.deliveryRepo.FindByJobID(ctx, job.ID())
+	for _, d := range deliveries {
+		if d.Status() == model.DeliveryStatusPending {
+			due := model.ReconstructPushDelivery(d.ID(), d.JobID(), d.SubscriptionID(), d.Status(), d.AttemptCount(),
+				&past, d.LastStatus(), d.LastError(), d.CreatedAt(), d.UpdatedAt())
+			f.deliveryRepo.Save(ctx, due)
+		}
+	}
+	saved.ScheduleRetry(past, saved.LastError())
+	f.jobRepo.Save(ctx, saved)
+
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	if hits["/fcm/send/ok"] != 1 || hits["/fcm/send/flaky"] != 2 {
+		t.Fatalf("unexpected hits: %v", hits)
+	}
+	saved, _ = f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusSucceeded {
+		t.Fatalf("job status = %s, want succeeded", saved.Status())
+	}
+}
+
+func TestProcessPendingJobsDeliveryRoundsDoNotCountAsJobRetries(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	f := newSenderFixture(t, PushSenderConfig{MaxDeliveryAttempts: 4}, handler)
+	ctx := context.Background()
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/flaky")
+	job := f.addJob(t)
+
+	for round := 1; round < 4; round++ {
+		if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+			t.Fatalf("ProcessPendingJobs: %v", err)
+		}
+
+		saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+		if saved.Status() != model.JobStatusPending || saved.RetryCount() != 0 || saved.LastError() != "" {
+			t.Fatalf("job after round %d = status %s, retries %d, error %q; want pending without retries",
+				round, saved.Status(), saved.RetryCount(), saved.LastError())
+		}
+
+		// Make the next round due now instead of waiting for the backoff.
+		past := time.Now().Add(-time.Second)
+		deliveries, _ := f.deliveryRepo.FindByJobID(ctx, job.ID())
+		for _, d := range deliveries {
+			due := model.ReconstructPushDelivery(d.ID(), d.JobID(), d.SubscriptionID(), d.Status(), d.AttemptCount(),
+				&past, d.LastStatus(), d.LastError(), d.CreatedAt(), d.UpdatedAt())
+			f.deliveryRepo.Save(ctx, due)
+		}
+		saved.AwaitDeliveries(past)
+		f.jobRepo.Save(ctx, saved)
+	}
+
+	// The delivery has used up its attempts, and only then does the job fail.
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusFailed {
+		t.Fatalf("job status = %s, want failed", saved.Status())
+	}
+}
+
+func TestProcessPendingJobsSignsWithSubscriptionVAPIDKey(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received = map[string]string{}
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.URL.Path] = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{Workers: 2, PerHostConcurrency: 2, JobConcurrency: 1}, handler)
+	ctx := context.Background()
+
+	oldKey, _ := f.vapidKeys.ActiveKey(ctx)
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/old")
+
+	newKey, err := f.vapidKeys.GenerateKey(ctx, true, valueobject.VAPIDSubject{})
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/new")
+	f.addJob(t)
+
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	for path, key := range map[string]string{
+		"/fcm/send/old": oldKey.PublicKey(),
+		"/fcm/send/new": newKey.PublicKey(),
+	} {
+		if auth := received[path]; !strings.HasSuffix(auth, "k="+key) {
+			t.Errorf("%s Authorization = %q, want VAPID key %s", path, auth, key)
+		}
+	}
+}
+
+func TestProcessPendingJobsTemplates(t *testing.T) {
+	var hits int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{Workers: 1, PerHostConcurrency: 1, JobConcurrency: 1}, handler)
+	ctx := context.Background()
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/1")
+
+	template, _ := model.NewNotificationTemplate(1, "greeting", "Hi {{name}}", "Welcome", "", "", nil)
+	if err := f.templateRepo.Save(ctx, template); err != nil {
+		t.Fatalf("Save template: %v", err)
+	}
+
+	rendered := f.addJob(t)
+	rendered.UseTemplate("greeting", map[string]string{"name": "Alice"})
+	f.jobRepo.Save(ctx, rendered)
+
+	missingVars := f.addJob(t)
+	missingVars.UseTemplate("greeting", nil)
+	f.jobRepo.Save(ctx, missingVars)
+
+	missingTemplate := f.addJob(t)
+	missingTemplate.UseTemplate("deleted", nil)
+	f.jobRepo.Save(ctx, missingTemplate)
+
+	f.sender.ProcessPendingJobs(ctx, 10)
+
+	if hits != 1 {
+		t.Errorf("push service hits = %d, want 1", hits)
+	}
+	for job, want := range map[*model.PushJob]model.JobStatus{
+		rendered:        model.JobStatusSucceeded,
+		missingVars:     model.JobStatusFailed,
+		missingTemplate: model.JobStatusFailed,
+	} {
+		saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+		if saved.Status() != want {
+			t.Errorf("job %s status = %s (%s), want %s", job.ID().String(), saved.Status(), saved.LastError(), want)
+		}
+	}
+}
+
+func TestProcessPendingJobsAppliesNotificationPrefs(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		hits = map[string]int{}
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{Workers: 2, PerHostConcurrency: 2, JobConcurrency: 1}, handler)
+	ctx := context.Background()
+
+	optedOut, _ := valueobject.NewUserID(1)
+	quiet, _ := valueobject.NewUserID(2)
+	other, _ := valueobject.NewUserID(3)
+
+	optedOutPrefs := model.NewNotificationPrefs(optedOut)
+	optedOutPrefs.Update(true, map[string]bool{"news": false}, nil)
+	f.prefsRepo.Save(ctx, optedOutPrefs)
+
+	// A window that always contains now: it started a minute ago and ends in
+	// an hour, expressed in UTC.
+	now := time.Now().UTC()
+	quietHours, err := valueobject.NewQuietHours(now.Add(-time.Minute).Format("15:04"), now.Add(time.Hour).Format("15:04"), "UTC")
+	if err != nil {
+		t.Fatalf("NewQuietHours: %v", err)
+	}
+	quietPrefs := model.NewNotificationPrefs(quiet)
+	quietPrefs.Update(true, nil, &quietHours)
+	f.prefsRepo.Save(ctx, quietPrefs)
+
+	skippedSub := f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/opted-out", &optedOut)
+	deferredSub := f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/quiet", &quiet)
+	f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/other", &other)
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/anonymous")
+
+	id, _ := f.jobRepo.NextIdentity(ctx)
+	job, _ := model.NewPushJob(id, "", nil, "news", model.UrgencyNormal, 60, model.PushPayload{"title": "hi"}, nil)
+	f.jobRepo.Save(ctx, job)
+
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	if hits["/fcm/send/other"] != 1 || hits["/fcm/send/anonymous"] != 1 || len(hits) != 2 {
+		t.Errorf("hits = %v, want only other and anonymous", hits)
+	}
+
+	deliveries, _ := f.deliveryRepo.FindByJobID(ctx, job.ID())
+	for _, d := range deliveries {
+		switch d.SubscriptionID() {
+		case skippedSub.ID():
+			if d.Status() != model.DeliveryStatusSkipped {
+				t.Errorf("opted-out delivery status = %s, want skipped", d.Status())
+			}
+		case deferredSub.ID():
+			if d.Status() != model.DeliveryStatusPending || d.AttemptCount() != 0 || d.NextAttemptAt() == nil {
+				t.Fatalf("quiet delivery = %s attempts=%d next=%v", d.Status(), d.AttemptCount(), d.NextAttemptAt())
+			}
+			if until := d.NextAttemptAt().Sub(now); until < 58*time.Minute || until > time.Hour {
+				t.Errorf("quiet delivery deferred by %v, want until the end of quiet hours", until)
+			}
+		}
+	}
+
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusPending {
+		t.Errorf("job status = %s, want pending until quiet hours end", saved.Status())
+	}
+}
+
+func TestProcessPendingJobsTargetsSegment(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		hits = map[string]int{}
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{Workers: 2, PerHostConcurrency: 2, JobConcurrency: 1}, handler)
+	ctx := context.Background()
+
+	premium, _ := f.userRepo.NextIdentity(ctx)
+	email, _ := valueobject.NewEmail("premium@example.com")
+	premiumUser := model.NewUser(premium, "Premium", email)
+	premiumUser.SetAttributes(map[string]string{"plan": "premium"})
+	f.userRepo.Save(ctx, premiumUser)
+	basic, _ := valueobject.NewUserID(1) // the sample user, without attributes
+
+	f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/premium", &premium)
+	f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/basic", &basic)
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/anonymous")
+
+	segment, err := model.NewSegment(nil, map[string]string{"plan": "premium"}, nil, nil, 0, 0)
+	if err != nil {
+		t.Fatalf("NewSegment: %v", err)
+	}
+	job := f.addJob(t)
+	if err := job.TargetSegment(segment); err != nil {
+		t.Fatalf("TargetSegment: %v", err)
+	}
+	f.jobRepo.Save(ctx, job)
+
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	if hits["/fcm/send/premium"] != 1 || len(hits) != 1 {
+		t.Errorf("hits = %v, want only premium", hits)
+	}
+
+	counts, _ := f.deliveryRepo.CountByJobID(ctx, job.ID())
+	if counts.Total() != 1 || counts.Succeeded != 1 {
+		t.Errorf("deliveries = %+v, want a single succeeded delivery", counts)
+	}
+}
+
+func TestProcessPendingJobsExpandsBatchRecipients(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		hits = map[string]int{}
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{Workers: 2, PerHostConcurrency: 2, JobConcurrency: 1}, handler)
+	ctx := context.Background()
+
+	recipient, _ := valueobject.NewUserID(1)
+	other, _ := valueobject.NewUserID(2)
+	f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/recipient-phone", &recipient)
+	f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/recipient-laptop", &recipient)
+	f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/other", &other)
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/anonymous")
+
+	id, _ := f.jobRepo.NextIdentity(ctx)
+	job, _ := model.NewPushJob(id, "", nil, "", model.UrgencyNormal, 60, model.PushPayload{"title": "hi"}, nil)
+	if err := job.TargetRecipients(1); err != nil {
+		t.Fatalf("TargetRecipients: %v", err)
+	}
+	if err := f.jobRepo.CreateBatch(ctx, job, []valueobject.UserID{recipient}); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	if hits["/fcm/send/recipient-phone"] != 1 || hits["/fcm/send/recipient-laptop"] != 1 || len(hits) != 2 {
+		t.Errorf("hits = %v, want only the recipient's subscriptions", hits)
+	}
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusSucceeded {
+		t.Errorf("job status = %s, want succeeded", saved.Status())
+	}
+}
+
+func TestProcessPendingJobsBatchWithoutRecipients(t *testing.T) {
+	var hits sync.Map
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Store(r.URL.Path, true)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{}, handler)
+	ctx := context.Background()
+
+	other, _ := valueobject.NewUserID(1)
+	f.addUserSubscription(t, "https://fcm.googleapis.com/fcm/send/other", &other)
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/anonymous")
+
+	// The recipient rows are gone, as after their users were deleted before
+	// the job was sent.
+	id, _ := f.jobRepo.NextIdentity(ctx)
+	job, _ := model.NewPushJob(id, "", nil, "", model.UrgencyNormal, 60, model.PushPayload{"title": "hi"}, nil)
+	if err := job.TargetRecipients(1); err != nil {
+		t.Fatalf("TargetRecipients: %v", err)
+	}
+	if err := f.jobRepo.CreateBatch(ctx, job, nil); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	hits.Range(func(path, _ any) bool {
+		t.Errorf("batch without recipients was sent to %s", path)
+		return true
+	})
+	counts, _ := f.deliveryRepo.CountByJobID(ctx, job.ID())
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusSucceeded || counts.Total() != 0 {
+		t.Errorf("job = %s with %+v deliveries, want finished without deliveries", saved.Status(), counts)
+	}
+}
+
+func TestProcessPendingJobsShutdown(t *testing.T) {
+	tests := []struct {
+		name         string
+		drainTimeout time.Duration
+		// unblockAfter is when the in-flight request is answered after
+		// shutdown starts.
+		unblockAfter time.Duration
+		wantSent     int
+	}{
+		{"drains in-flight delivery", 5 * time.Second, 50 * time.Millisecond, 1},
+		{"abandons delivery past drain timeout", 50 * time.Millisecond, time.Second, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{}, 10)
+			unblock := make(chan struct{})
+			var (
+				mu   sync.Mutex
+				hits int
+			)
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				hits++
+				mu.Unlock()
+				started <- struct{}{}
+				select {
+				case <-unblock:
+				case <-r.Context().Done():
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			f := newSenderFixture(t, PushSenderConfig{Workers: 1, PerHostConcurrency: 1, JobConcurrency: 1, DrainTimeout: tt.drainTimeout}, handler)
+			bg := context.Background()
+			for i := 0; i < 3; i++ {
+				f.addSubscription(t, fmt.Sprintf("https://fcm.googleapis.com/fcm/send/%d", i))
+			}
+			job := f.addJob(t)
+
+			ctx, cancel := context.WithCancel(bg)
+			done := make(chan error, 1)
+			go func() {
+				_, err := f.sender.ProcessPendingJobs(ctx, 10)
+				done <- err
+			}()
+
+			<-started
+			cancel()
+			time.AfterFunc(tt.unblockAfter, func() { close(unblock) })
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("ProcessPendingJobs: %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("ProcessPendingJobs did not return after shutdown")
+			}
+
+			mu.Lock()
+			if hits != 1 {
+				t.Errorf("push service received %d requests, want 1", hits)
+			}
+			mu.Unlock()
+
+			counts, _ := f.deliveryRepo.CountByJobID(bg, job.ID())
+			if counts.Succeeded != tt.wantSent || counts.Pending != 3-tt.wantSent {
+				t.Errorf("delivery counts = %+v, want %d sent", counts, tt.wantSent)
+			}
+
+			saved, _ := f.jobRepo.FindByID(bg, job.ID())
+			if saved.Status() != model.JobStatusPending || saved.LeaseOwner() != "" || saved.RetryCount() != 0 {
+				t.Errorf("job = status %s, lease owner %q, retries %d; want released to pending",
+					saved.Status(), saved.LeaseOwner(), saved.RetryCount())
+			}
+		})
+	}
+}
+
+func TestProcessPendingJobsLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	f := newSenderFixture(t, PushSenderConfig{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-unblock
+		w.WriteHeader(http.StatusCreated)
+	}))
+	f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/device")
+	job := f.addJob(t)
+
+	done := make(chan struct{})
+	go func() {
+		f.sender.ProcessPendingJobs(ctx, 10)
+		close(done)
+	}()
+
+	// Another sender reclaims the job, e.g. after this one stalled past
+	// its lease, while the delivery is still in flight.
+	<-started
+	if reclaimed, _ := f.jobRepo.ClaimJob(ctx, job.ID(), "other", time.Minute, model.JobStatusSending); reclaimed == nil {
+		t.Fatal("ClaimJob by another sender failed")
+	}
+	close(unblock)
+	<-done
+
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusSending || saved.LeaseOwner() != "other" {
+		t.Errorf("job = status %s, lease owner %q; want left to the new owner", saved.Status(), saved.LeaseOwner())
+	}
+}
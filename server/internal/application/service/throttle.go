@@ -0,0 +1,113 @@
+package service
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostThrottle remembers push service hosts that asked us to slow down
+// (429/503), so deliveries to them are rescheduled instead of sent.
+type hostThrottle struct {
+	mu          sync.Mutex
+	pausedUntil map[string]time.Time
+}
+
+func newHostThrottle() *hostThrottle {
+	return &hostThrottle{
+		pausedUntil: make(map[string]time.Time),
+	}
+}
+
+// pause stops delivery to host until the given time. An existing longer
+// pause is kept.
+func (t *hostThrottle) pause(host string, until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if current, exists := t.pausedUntil[host]; exists && current.After(until) {
+		return
+	}
+	t.pausedUntil[host] = until
+}
+
+// until returns when host may be contacted again, if it is paused at now.
+func (t *hostThrottle) until(host string, now time.Time) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	until, exists := t.pausedUntil[host]
+	if !exists {
+		return time.Time{}, false
+	}
+	if !now.Before(until) {
+		delete(t.pausedUntil, host)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+func isThrottleStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter interprets a Retry-After header value, which is either a
+// number of seconds or an HTTP date (RFC 9110 section 10.2.3).
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if at.Before(now) {
+		return 0, true
+	}
+	return at.Sub(now), true
+}
+
+// throttleDelay returns how long to leave a host alone after it answered
+// with a throttling status.
+func (c PushSenderConfig) throttleDelay(retryAfter string, now time.Time) time.Duration {
+	delay, ok := parseRetryAfter(retryAfter, now)
+	if !ok {
+		delay = c.ThrottleDelay
+	}
+	return min(delay, c.MaxRetryAfter)
+}
+
+// backoffDelay returns the exponential delay before attempt retryCount+1,
+// spread by up to ±BackoffJitter so failed deliveries don't retry in lockstep.
+func (c PushSenderConfig) backoffDelay(retryCount int, random func() float64) time.Duration {
+	delay := c.BackoffBase
+	for i := 0; i < retryCount; i++ {
+		delay *= 2
+		if delay > c.BackoffMax {
+			delay = c.BackoffMax
+			break
+		}
+	}
+
+	if c.BackoffJitter > 0 {
+		if random == nil {
+			random = rand.Float64
+		}
+		factor := 1 + c.BackoffJitter*(2*random()-1)
+		delay = time.Duration(float64(delay) * factor)
+	}
+
+	return delay
+}
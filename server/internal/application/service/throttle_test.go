@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"", 0, false},
+		{"-5", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBackoffDelayJitter(t *testing.T) {
+	config := PushSenderConfig{BackoffBase: 10 * time.Second, BackoffMax: time.Minute, BackoffJitter: 0.5}
+
+	if got := config.backoffDelay(0, func() float64 { return 0 }); got != 5*time.Second {
+		t.Errorf("lowest jitter = %v, want 5s", got)
+	}
+	if got := config.backoffDelay(1, func() float64 { return 1 }); got != 30*time.Second {
+		t.Errorf("highest jitter = %v, want 30s", got)
+	}
+	if got := config.backoffDelay(10, func() float64 { return 0.5 }); got != time.Minute {
+		t.Errorf("capped delay = %v, want 1m", got)
+	}
+
+	config.BackoffJitter = 0
+	if got := config.backoffDelay(2, nil); got != 40*time.Second {
+		t.Errorf("delay without jitter = %v, want 40s", got)
+	}
+}
+
+func TestProcessPendingJobsHonorsRetryAfter(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		hits = map[string]int{}
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.Host]++
+		mu.Unlock()
+
+		switch r.Host {
+		case "fcm.googleapis.com":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "web.push.apple.com":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	})
+
+	f := newSenderFixture(t, PushSenderConfig{
+		Workers:            1,
+		PerHostConcurrency: 1,
+		JobConcurrency:     1,
+		ThrottleDelay:      45 * time.Second,
+	}, handler)
+	ctx := context.Background()
+
+	fcm1 := f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/1")
+	fcm2 := f.addSubscription(t, "https://fcm.googleapis.com/fcm/send/2")
+	apple := f.addSubscription(t, "https://web.push.apple.com/apple")
+	f.addSubscription(t, "https://updates.push.services.mozilla.com/wpush/v2/ok")
+	job := f.addJob(t)
+
+	start := time.Now()
+	if _, err := f.sender.ProcessPendingJobs(ctx, 10); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	if hits["fcm.googleapis.com"] != 1 {
+		t.Errorf("fcm hits = %d, want 1 (second delivery should wait for Retry-After)", hits["fcm.googleapis.com"])
+	}
+
+	deliveries, _ := f.deliveryRepo.FindByJobID(ctx, job.ID())
+	bySubscription := map[int64]*model.PushDelivery{}
+	for _, d := range deliveries {
+		bySubscription[d.SubscriptionID().Value()] = d
+	}
+
+	assertRetryAt := func(name string, d *model.PushDelivery, attempts int, after time.Duration) {
+		t.Helper()
+		if d.Status() != model.DeliveryStatusPending || d.AttemptCount() != attempts || d.NextAttemptAt() == nil {
+			t.Fatalf("%s delivery = %s attempts=%d next=%v", name, d.Status(), d.AttemptCount(), d.NextAttemptAt())
+		}
+		got := d.NextAttemptAt().Sub(start)
+		if got < after || got > after+5*time.Second {
+			t.Errorf("%s next attempt in %v, want about %v", name, got, after)
+		}
+	}
+	// Either fcm delivery may go first; the other one is deferred untried.
+	throttled, deferred := bySubscription[fcm1.ID().Value()], bySubscription[fcm2.ID().Value()]
+	if throttled.AttemptCount() == 0 {
+		throttled, deferred = deferred, throttled
+	}
+	assertRetryAt("throttled", throttled, 1, 2*time.Minute)
+	assertRetryAt("deferred", deferred, 0, 2*time.Minute)
+	assertRetryAt("503 without Retry-After", bySubscription[apple.ID().Value()], 1, 45*time.Second)
+
+	counts, _ := f.deliveryRepo.CountByJobID(ctx, job.ID())
+	if counts.Succeeded != 1 || counts.Pending != 3 {
+		t.Fatalf("counts = %+v", counts)
+	}
+
+	saved, _ := f.jobRepo.FindByID(ctx, job.ID())
+	if saved.Status() != model.JobStatusPending || saved.NextAttemptAt() == nil {
+		t.Fatalf("job status = %s next=%v, want pending until the earliest retry", saved.Status(), saved.NextAttemptAt())
+	}
+	if got := saved.NextAttemptAt().Sub(start); got < 45*time.Second || got > 50*time.Second {
+		t.Errorf("job next attempt in %v, want about 45s", got)
+	}
+}
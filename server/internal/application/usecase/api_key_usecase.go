@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+// apiKeySecretPrefix makes leaked keys easy to spot in logs and secret scanners.
+const apiKeySecretPrefix = "khk_"
+
+// lastUsedResolution limits how often authentication writes last_used_at.
+const lastUsedResolution = time.Minute
+
+type APIKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+	// bootstrapToken is accepted as an admin key so the first real key can
+	// be created over the API. Empty disables it.
+	bootstrapToken string
+}
+
+func NewAPIKeyUseCase(apiKeyRepo repository.APIKeyRepository, bootstrapToken string) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		apiKeyRepo:     apiKeyRepo,
+		bootstrapToken: bootstrapToken,
+	}
+}
+
+// CreateKey returns the new key together with its secret, which is not
+// stored and cannot be retrieved again.
+func (u *APIKeyUseCase) CreateKey(ctx context.Context, name string, scopes []model.APIKeyScope) (*model.APIKey, string, error) {
+	secret, err := generateAPIKeySecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, err := u.apiKeyRepo.NextIdentity(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := model.NewAPIKey(id, name, secret, scopes)
+	if err != nil {
+		return nil, "", errors.WrapDomainError(errors.ErrInvalidAPIKey.Code, errors.ErrInvalidAPIKey.Message, err)
+	}
+
+	if err := u.apiKeyRepo.Save(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+func (u *APIKeyUseCase) ListKeys(ctx context.Context) ([]*model.APIKey, error) {
+	return u.apiKeyRepo.FindAll(ctx)
+}
+
+// RevokeKey is idempotent; revoking an already revoked key succeeds.
+func (u *APIKeyUseCase) RevokeKey(ctx context.Context, id int64) (*model.APIKey, error) {
+	key, err := u.apiKeyRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.ErrAPIKeyNotFound
+	}
+
+	if key.IsRevoked() {
+		return key, nil
+	}
+	key.Revoke()
+	if err := u.apiKeyRepo.Save(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Authenticate resolves a bearer secret to an active key.
+func (u *APIKeyUseCase) Authenticate(ctx context.Context, secret string) (*model.APIKey, error) {
+	if u.bootstrapToken != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(u.bootstrapToken)) == 1 {
+		return model.ReconstructAPIKey(0, "ADMIN_API_TOKEN", "", "", []model.APIKeyScope{model.APIKeyScopeAdmin}, time.Time{}, nil, nil), nil
+	}
+
+	key, err := u.apiKeyRepo.FindBySecretHash(ctx, model.HashAPIKeySecret(secret))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.IsRevoked() {
+		return nil, errors.ErrUnauthenticated
+	}
+
+	now := time.Now()
+	if key.LastUsedAt() == nil || now.Sub(*key.LastUsedAt()) >= lastUsedResolution {
+		// Not Save: the key may have been revoked since it was loaded.
+		if err := u.apiKeyRepo.TouchLastUsed(ctx, key.ID(), now); err != nil {
+			return nil, err
+		}
+		key.MarkUsed(now)
+	}
+	return key, nil
+}
+
+func generateAPIKeySecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return apiKeySecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
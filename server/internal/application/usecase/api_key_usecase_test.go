@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+func TestAPIKeyUseCase(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewMemoryAPIKeyRepository()
+	uc := NewAPIKeyUseCase(repo, "bootstrap-token")
+
+	key, secret, err := uc.CreateKey(ctx, "billing", []model.APIKeyScope{model.APIKeyScopeSend, model.APIKeyScopeSend})
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if len(key.Scopes()) != 1 || key.SecretHash() == secret || key.Prefix() != secret[:model.APIKeyPrefixLength] {
+		t.Errorf("key = %+v, want one scope, hashed secret and clear prefix", key)
+	}
+
+	authenticated, err := uc.Authenticate(ctx, secret)
+	if err != nil || authenticated.ID() != key.ID() {
+		t.Fatalf("Authenticate = %v, %v", authenticated, err)
+	}
+	if authenticated.LastUsedAt() == nil {
+		t.Error("LastUsedAt not recorded")
+	}
+
+	bootstrap, err := uc.Authenticate(ctx, "bootstrap-token")
+	if err != nil || !bootstrap.HasScope(model.APIKeyScopeReadLogs) {
+		t.Errorf("Authenticate(bootstrap) = %v, %v, want admin key", bootstrap, err)
+	}
+
+	if _, err := uc.RevokeKey(ctx, key.ID()); err != nil {
+		t.Fatalf("RevokeKey: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, secret); err != errors.ErrUnauthenticated {
+		t.Errorf("Authenticate(revoked) = %v, want ErrUnauthenticated", err)
+	}
+
+	if _, _, err := uc.CreateKey(ctx, "bad", []model.APIKeyScope{"everything"}); err == nil {
+		t.Error("CreateKey accepted an unknown scope")
+	}
+	if _, err := uc.RevokeKey(ctx, 999); err != errors.ErrAPIKeyNotFound {
+		t.Errorf("RevokeKey(unknown) = %v, want ErrAPIKeyNotFound", err)
+	}
+}
+
+// revokingAPIKeyRepository revokes the key right after Authenticate has
+// loaded it, as a concurrent RevokeKey request would.
+type revokingAPIKeyRepository struct {
+	*persistence.MemoryAPIKeyRepository
+}
+
+func (r revokingAPIKeyRepository) FindBySecretHash(ctx context.Context, secretHash string) (*model.APIKey, error) {
+	key, err := r.MemoryAPIKeyRepository.FindBySecretHash(ctx, secretHash)
+	if err != nil || key == nil {
+		return key, err
+	}
+	revoked := *key
+	revoked.Revoke()
+	if err := r.MemoryAPIKeyRepository.Save(ctx, &revoked); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func TestAPIKeyUseCaseAuthenticateKeepsConcurrentRevocation(t *testing.T) {
+	ctx := context.Background()
+	repo := revokingAPIKeyRepository{persistence.NewMemoryAPIKeyRepository()}
+	uc := NewAPIKeyUseCase(repo, "")
+
+	key, secret, err := uc.CreateKey(ctx, "billing", []model.APIKeyScope{model.APIKeyScopeSend})
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, secret); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+
+	stored, err := repo.FindByID(ctx, key.ID())
+	if err != nil || stored == nil {
+		t.Fatalf("FindByID = %v, %v", stored, err)
+	}
+	if !stored.IsRevoked() {
+		t.Error("Authenticate overwrote a revocation made after the key was loaded")
+	}
+}
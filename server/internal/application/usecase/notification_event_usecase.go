@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type RecordNotificationEventRequest struct {
+	Type           model.NotificationEventType
+	JobID          valueobject.JobID
+	SubscriptionID *valueobject.PublicSubscriptionID
+	URL            string
+	OccurredAt     time.Time
+}
+
+// JobEngagement relates the events reported by Service Workers to the number
+// of subscriptions the push service accepted the job for.
+type JobEngagement struct {
+	JobID  valueobject.JobID
+	Sent   int
+	Counts model.EngagementCounts
+	// DisplayRate and ClickThroughRate are Displayed/Sent and Clicked/Sent.
+	DisplayRate      float64
+	ClickThroughRate float64
+}
+
+type NotificationEventUseCase struct {
+	eventRepo        repository.NotificationEventRepository
+	jobRepo          repository.PushJobRepository
+	subscriptionRepo repository.PushSubscriptionRepository
+	deliveryRepo     repository.PushDeliveryRepository
+}
+
+func NewNotificationEventUseCase(
+	eventRepo repository.NotificationEventRepository,
+	jobRepo repository.PushJobRepository,
+	subscriptionRepo repository.PushSubscriptionRepository,
+	deliveryRepo repository.PushDeliveryRepository,
+) *NotificationEventUseCase {
+	return &NotificationEventUseCase{
+		eventRepo:        eventRepo,
+		jobRepo:          jobRepo,
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+	}
+}
+
+func (u *NotificationEventUseCase) RecordEvent(ctx context.Context, req RecordNotificationEventRequest) error {
+	if !req.Type.IsValid() {
+		return errors.ErrInvalidNotificationEvent
+	}
+
+	job, err := u.jobRepo.FindByID(ctx, req.JobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return errors.ErrJobNotFound
+	}
+
+	// The endpoint is public, so only events from a subscription the job was
+	// actually sent to are accepted; anything else could inflate the rates.
+	if req.SubscriptionID == nil {
+		return errors.ErrInvalidNotificationEvent
+	}
+	subscription, err := u.subscriptionRepo.FindByPublicID(ctx, *req.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return errors.ErrSubscriptionNotFound
+	}
+	subscriptionID := subscription.ID()
+	delivery, err := u.deliveryRepo.FindByJobAndSubscription(ctx, req.JobID, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if delivery == nil {
+		return errors.ErrSubscriptionNotFound
+	}
+
+	id, err := u.eventRepo.NextIdentity(ctx)
+	if err != nil {
+		return err
+	}
+
+	event, err := model.NewNotificationEvent(id, req.JobID, &subscriptionID, req.Type, req.URL, req.OccurredAt)
+	if err != nil {
+		return errors.ErrInvalidNotificationEvent
+	}
+
+	if err := u.eventRepo.Save(ctx, event); err != nil {
+		return err
+	}
+
+	// Interactions keep the subscription in "active within N days" segments.
+	if req.Type.IsInteraction() {
+		return u.subscriptionRepo.MarkActive(ctx, subscriptionID, event.OccurredAt())
+	}
+	return nil
+}
+
+func (u *NotificationEventUseCase) GetEngagement(ctx context.Context, jobID valueobject.JobID) (*JobEngagement, error) {
+	job, err := u.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, errors.ErrJobNotFound
+	}
+
+	deliveries, err := u.deliveryRepo.CountByJobID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := u.eventRepo.CountByJobID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JobEngagement{
+		JobID:            jobID,
+		Sent:             deliveries.Succeeded,
+		Counts:           counts,
+		DisplayRate:      model.Rate(counts.Displayed, deliveries.Succeeded),
+		ClickThroughRate: model.Rate(counts.Clicked, deliveries.Succeeded),
+	}, nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+func TestNotificationEventUseCaseEngagement(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	subscriptionRepo := persistence.NewMemoryPushSubscriptionRepository()
+	deliveryRepo := persistence.NewMemoryPushDeliveryRepository()
+	uc := NewNotificationEventUseCase(persistence.NewMemoryNotificationEventRepository(), jobRepo, subscriptionRepo, deliveryRepo)
+
+	job := newTestJob(t, jobRepo, "")
+
+	var subscriptionIDs []valueobject.SubscriptionID
+	var publicIDs []valueobject.PublicSubscriptionID
+	for _, endpoint := range []string{"https://fcm.googleapis.com/fcm/send/a", "https://fcm.googleapis.com/fcm/send/b", "https://fcm.googleapis.com/fcm/send/other"} {
+		id, _ := subscriptionRepo.NextIdentity(ctx)
+		ep, _ := valueobject.NewPushEndpoint(endpoint)
+		p256dh, _ := valueobject.NewP256dhKey("BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM")
+		auth, _ := valueobject.NewAuthKey("tBHItJI5svbpez7KI4CCXg")
+		subscription := model.NewPushSubscription(id, nil, ep, valueobject.NewPushKeys(p256dh, auth), "", nil)
+		subscriptionRepo.Save(ctx, subscription)
+		subscriptionIDs = append(subscriptionIDs, id)
+		publicIDs = append(publicIDs, subscription.PublicID())
+	}
+	// The third subscription was not sent the job.
+	deliveryRepo.CreateForJob(ctx, job.ID(), subscriptionIDs[:2])
+	deliveries, _ := deliveryRepo.FindByJobID(ctx, job.ID())
+	for _, delivery := range deliveries {
+		delivery.MarkAsSucceeded(201)
+		deliveryRepo.Save(ctx, delivery)
+	}
+
+	record := func(eventType model.NotificationEventType, subscriptionID valueobject.PublicSubscriptionID) {
+		t.Helper()
+		err := uc.RecordEvent(ctx, RecordNotificationEventRequest{
+			Type:           eventType,
+			JobID:          job.ID(),
+			SubscriptionID: &subscriptionID,
+			OccurredAt:     time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("RecordEvent(%s): %v", eventType, err)
+		}
+	}
+	record(model.NotificationEventDisplayed, publicIDs[0])
+	record(model.NotificationEventDisplayed, publicIDs[1])
+	clickedAfter := time.Now()
+	record(model.NotificationEventClicked, publicIDs[0])
+	// A second click on the same device must not inflate the CTR.
+	record(model.NotificationEventClicked, publicIDs[0])
+
+	// Clicks keep the subscription active; automatic displays do not.
+	if clicked, _ := subscriptionRepo.FindByID(ctx, subscriptionIDs[0]); clicked.LastActiveAt().Before(clickedAfter) {
+		t.Errorf("LastActiveAt = %v, want the click time", clicked.LastActiveAt())
+	}
+	if displayed, _ := subscriptionRepo.FindByID(ctx, subscriptionIDs[1]); !displayed.LastActiveAt().Before(clickedAfter) {
+		t.Errorf("LastActiveAt = %v, want unchanged by a display", displayed.LastActiveAt())
+	}
+
+	engagement, err := uc.GetEngagement(ctx, job.ID())
+	if err != nil {
+		t.Fatalf("GetEngagement: %v", err)
+	}
+	if engagement.Sent != 2 || engagement.Counts.Displayed != 2 || engagement.Counts.Clicked != 1 {
+		t.Errorf("engagement = %+v, want sent 2, displayed 2, clicked 1", engagement)
+	}
+	if engagement.DisplayRate != 1 || engagement.ClickThroughRate != 0.5 {
+		t.Errorf("rates = %v/%v, want 1/0.5", engagement.DisplayRate, engagement.ClickThroughRate)
+	}
+
+	unknown, _ := valueobject.NewJobID(999)
+	err = uc.RecordEvent(ctx, RecordNotificationEventRequest{Type: model.NotificationEventClicked, JobID: unknown})
+	if err != errors.ErrJobNotFound {
+		t.Errorf("RecordEvent(unknown job) = %v, want ErrJobNotFound", err)
+	}
+	err = uc.RecordEvent(ctx, RecordNotificationEventRequest{Type: "opened", JobID: job.ID()})
+	if err != errors.ErrInvalidNotificationEvent {
+		t.Errorf("RecordEvent(opened) = %v, want ErrInvalidNotificationEvent", err)
+	}
+
+	// The endpoint is unauthenticated: events must come from a subscription
+	// the job was sent to, or anyone could push the rates past 100%.
+	unknownSubscription := valueobject.NewPublicSubscriptionID()
+	for _, tt := range []struct {
+		name           string
+		subscriptionID *valueobject.PublicSubscriptionID
+		want           error
+	}{
+		{"without subscription", nil, errors.ErrInvalidNotificationEvent},
+		{"unknown subscription", &unknownSubscription, errors.ErrSubscriptionNotFound},
+		{"subscription not sent the job", &publicIDs[2], errors.ErrSubscriptionNotFound},
+	} {
+		err := uc.RecordEvent(ctx, RecordNotificationEventRequest{Type: model.NotificationEventDisplayed, JobID: job.ID(), SubscriptionID: tt.subscriptionID})
+		if err != tt.want {
+			t.Errorf("RecordEvent(%s) = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if engagement, _ := uc.GetEngagement(ctx, job.ID()); engagement.Counts.Displayed != 2 {
+		t.Errorf("displayed = %d after rejected events, want 2", engagement.Counts.Displayed)
+	}
+}
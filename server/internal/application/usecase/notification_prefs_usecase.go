@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type QuietHoursInput struct {
+	Start    string
+	End      string
+	Timezone string
+}
+
+type UpdateNotificationPrefsInput struct {
+	Enabled    bool
+	Topics     map[string]bool
+	QuietHours *QuietHoursInput
+}
+
+type NotificationPrefsUseCase struct {
+	userRepo  repository.UserRepository
+	prefsRepo repository.NotificationPrefsRepository
+}
+
+func NewNotificationPrefsUseCase(userRepo repository.UserRepository, prefsRepo repository.NotificationPrefsRepository) *NotificationPrefsUseCase {
+	return &NotificationPrefsUseCase{
+		userRepo:  userRepo,
+		prefsRepo: prefsRepo,
+	}
+}
+
+// GetPrefs returns the user's saved preferences, or the defaults when the
+// user never saved any.
+func (u *NotificationPrefsUseCase) GetPrefs(ctx context.Context, userIDInt int) (*model.NotificationPrefs, error) {
+	userID, err := u.findUser(ctx, userIDInt)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := u.prefsRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		return model.NewNotificationPrefs(userID), nil
+	}
+	return prefs, nil
+}
+
+func (u *NotificationPrefsUseCase) UpdatePrefs(ctx context.Context, userIDInt int, input UpdateNotificationPrefsInput) (*model.NotificationPrefs, error) {
+	prefs, err := u.GetPrefs(ctx, userIDInt)
+	if err != nil {
+		return nil, err
+	}
+
+	var quietHours *valueobject.QuietHours
+	if input.QuietHours != nil {
+		q, err := valueobject.NewQuietHours(input.QuietHours.Start, input.QuietHours.End, input.QuietHours.Timezone)
+		if err != nil {
+			return nil, errors.WrapDomainError(errors.ErrInvalidQuietHours.Code, err.Error(), err)
+		}
+		quietHours = &q
+	}
+
+	prefs.Update(input.Enabled, input.Topics, quietHours)
+	if err := u.prefsRepo.Save(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (u *NotificationPrefsUseCase) findUser(ctx context.Context, userIDInt int) (valueobject.UserID, error) {
+	userID, err := valueobject.NewUserID(userIDInt)
+	if err != nil {
+		return valueobject.UserID{}, errors.WrapDomainError(errors.ErrInvalidUserID.Code, "Invalid user ID", err)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return valueobject.UserID{}, err
+	}
+	if user == nil {
+		return valueobject.UserID{}, errors.ErrUserNotFound
+	}
+	return userID, nil
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type NotificationTemplateInput struct {
+	Title string
+	Body  string
+	URL   string
+	Icon  string
+	Data  map[string]interface{}
+}
+
+type NotificationTemplateUseCase struct {
+	templateRepo repository.NotificationTemplateRepository
+}
+
+func NewNotificationTemplateUseCase(templateRepo repository.NotificationTemplateRepository) *NotificationTemplateUseCase {
+	return &NotificationTemplateUseCase{
+		templateRepo: templateRepo,
+	}
+}
+
+func (u *NotificationTemplateUseCase) CreateTemplate(ctx context.Context, key string, input NotificationTemplateInput) (*model.NotificationTemplate, error) {
+	existing, err := u.templateRepo.FindByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.ErrTemplateExists
+	}
+
+	id, err := u.templateRepo.NextIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := model.NewNotificationTemplate(id, key, input.Title, input.Body, input.URL, input.Icon, input.Data)
+	if err != nil {
+		return nil, errors.WrapDomainError(errors.ErrInvalidTemplate.Code, err.Error(), err)
+	}
+
+	if err := u.templateRepo.Save(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (u *NotificationTemplateUseCase) GetTemplate(ctx context.Context, key string) (*model.NotificationTemplate, error) {
+	template, err := u.templateRepo.FindByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, errors.ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+func (u *NotificationTemplateUseCase) ListTemplates(ctx context.Context) ([]*model.NotificationTemplate, error) {
+	return u.templateRepo.FindAll(ctx)
+}
+
+// UpdateTemplate replaces the template content. Pending jobs that use the
+// template pick up the change, since templates are rendered at send time.
+func (u *NotificationTemplateUseCase) UpdateTemplate(ctx context.Context, key string, input NotificationTemplateInput) (*model.NotificationTemplate, error) {
+	template, err := u.GetTemplate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := template.Update(input.Title, input.Body, input.URL, input.Icon, input.Data); err != nil {
+		return nil, errors.WrapDomainError(errors.ErrInvalidTemplate.Code, err.Error(), err)
+	}
+
+	if err := u.templateRepo.Save(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (u *NotificationTemplateUseCase) DeleteTemplate(ctx context.Context, key string) error {
+	if _, err := u.GetTemplate(ctx, key); err != nil {
+		return err
+	}
+	return u.templateRepo.Delete(ctx, key)
+}
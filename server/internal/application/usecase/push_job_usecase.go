@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+const (
+	defaultJobPageSize = 50
+	maxJobPageSize     = 200
+)
+
+// PushJobDetails is a job with its per-subscription delivery counts and the
+// number of logged send attempts.
+type PushJobDetails struct {
+	Job               *model.PushJob
+	Deliveries        model.DeliveryCounts
+	SucceededAttempts int
+	FailedAttempts    int
+}
+
+type ListPushJobsRequest struct {
+	Status model.JobStatus
+	UserID *valueobject.UserID
+	Topic  string
+	Cursor *valueobject.JobID
+	Limit  int
+}
+
+type ListPushJobsResponse struct {
+	Jobs []*model.PushJob
+	// NextCursor is set when more jobs may follow.
+	NextCursor *valueobject.JobID
+}
+
+type PushJobUseCase struct {
+	jobRepo      repository.PushJobRepository
+	deliveryRepo repository.PushDeliveryRepository
+	logRepo      repository.PushLogRepository
+}
+
+func NewPushJobUseCase(
+	jobRepo repository.PushJobRepository,
+	deliveryRepo repository.PushDeliveryRepository,
+	logRepo repository.PushLogRepository,
+) *PushJobUseCase {
+	return &PushJobUseCase{
+		jobRepo:      jobRepo,
+		deliveryRepo: deliveryRepo,
+		logRepo:      logRepo,
+	}
+}
+
+func (u *PushJobUseCase) GetJob(ctx context.Context, id valueobject.JobID) (*PushJobDetails, error) {
+	job, err := u.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, errors.ErrJobNotFound
+	}
+
+	deliveries, err := u.deliveryRepo.CountByJobID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	succeeded, err := u.logRepo.CountSuccessByJobID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	failed, err := u.logRepo.CountFailuresByJobID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PushJobDetails{
+		Job:               job,
+		Deliveries:        deliveries,
+		SucceededAttempts: succeeded,
+		FailedAttempts:    failed,
+	}, nil
+}
+
+func (u *PushJobUseCase) ListJobs(ctx context.Context, req ListPushJobsRequest) (*ListPushJobsResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultJobPageSize
+	}
+	limit = min(limit, maxJobPageSize)
+
+	jobs, err := u.jobRepo.FindJobs(ctx, repository.PushJobFilter{
+		Status:   req.Status,
+		UserID:   req.UserID,
+		Topic:    req.Topic,
+		BeforeID: req.Cursor,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push jobs: %w", err)
+	}
+
+	response := &ListPushJobsResponse{Jobs: jobs}
+	if len(jobs) == limit {
+		next := jobs[len(jobs)-1].ID()
+		response.NextCursor = &next
+	}
+	return response, nil
+}
+
+// CancelJob stops a pending or scheduled job from being sent. Jobs that a
+// sender already picked up cannot be cancelled.
+func (u *PushJobUseCase) CancelJob(ctx context.Context, id valueobject.JobID) (*model.PushJob, error) {
+	job, err := u.jobRepo.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job != nil {
+		return job, nil
+	}
+
+	existing, err := u.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.ErrJobNotFound
+	}
+	if existing.Status() == model.JobStatusCancelled {
+		return existing, nil
+	}
+	return nil, errors.ErrJobNotCancellable
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+func newTestJob(t *testing.T, repo *persistence.MemoryPushJobRepository, topic string) *model.PushJob {
+	t.Helper()
+	ctx := context.Background()
+
+	id, _ := repo.NextIdentity(ctx)
+	job, err := model.NewPushJob(id, "", nil, topic, model.UrgencyNormal, 60, model.PushPayload{"title": "hi"}, nil)
+	if err != nil {
+		t.Fatalf("NewPushJob: %v", err)
+	}
+	repo.Save(ctx, job)
+	return job
+}
+
+func TestPushJobUseCaseCancelJob(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	uc := NewPushJobUseCase(jobRepo, persistence.NewMemoryPushDeliveryRepository(), persistence.NewMemoryPushLogRepository())
+
+	pending := newTestJob(t, jobRepo, "")
+	sending := newTestJob(t, jobRepo, "")
+	if _, err := jobRepo.ClaimJob(ctx, sending.ID(), "sender", time.Minute, model.JobStatusPending); err != nil {
+		t.Fatalf("ClaimJob: %v", err)
+	}
+
+	cancelled, err := uc.CancelJob(ctx, pending.ID())
+	if err != nil || cancelled.Status() != model.JobStatusCancelled {
+		t.Fatalf("CancelJob(pending) = %v, %v", cancelled, err)
+	}
+	if _, err := uc.CancelJob(ctx, pending.ID()); err != nil {
+		t.Errorf("CancelJob(cancelled) = %v, want idempotent success", err)
+	}
+	if _, err := uc.CancelJob(ctx, sending.ID()); err != errors.ErrJobNotCancellable {
+		t.Errorf("CancelJob(sending) = %v, want ErrJobNotCancellable", err)
+	}
+	unknown, _ := valueobject.NewJobID(999)
+	if _, err := uc.CancelJob(ctx, unknown); err != errors.ErrJobNotFound {
+		t.Errorf("CancelJob(unknown) = %v, want ErrJobNotFound", err)
+	}
+
+	claimed, _ := jobRepo.ClaimReadyJobs(ctx, "sender", time.Minute, 10)
+	if len(claimed) != 0 {
+		t.Errorf("claimed %d jobs after cancellation, want 0", len(claimed))
+	}
+}
+
+func TestPushJobUseCaseListJobsPaginates(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	uc := NewPushJobUseCase(jobRepo, persistence.NewMemoryPushDeliveryRepository(), persistence.NewMemoryPushLogRepository())
+
+	for i := 0; i < 5; i++ {
+		newTestJob(t, jobRepo, "news")
+	}
+	newTestJob(t, jobRepo, "promo")
+
+	var seen []int64
+	req := ListPushJobsRequest{Topic: "news", Limit: 2}
+	for page := 0; page < 5; page++ {
+		result, err := uc.ListJobs(ctx, req)
+		if err != nil {
+			t.Fatalf("ListJobs: %v", err)
+		}
+		for _, job := range result.Jobs {
+			seen = append(seen, job.ID().Value())
+		}
+		if result.NextCursor == nil {
+			break
+		}
+		req.Cursor = result.NextCursor
+	}
+
+	want := []int64{5, 4, 3, 2, 1}
+	if len(seen) != len(want) {
+		t.Fatalf("listed jobs %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("listed jobs %v, want %v", seen, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+const (
+	defaultLogPageSize = 100
+	maxLogPageSize     = 1000
+)
+
+type QueryPushLogsRequest struct {
+	JobID          *valueobject.JobID
+	SubscriptionID *valueobject.SubscriptionID
+	// PublicSubscriptionID is resolved to SubscriptionID; an unknown ID
+	// yields no logs.
+	PublicSubscriptionID *valueobject.PublicSubscriptionID
+	Since                *time.Time
+	Until                *time.Time
+	// Cursor is the NextCursor of the previous page, or 0 for the first page.
+	Cursor int64
+	Limit  int
+}
+
+type QueryPushLogsResponse struct {
+	Logs []*model.PushLog
+	// NextCursor is non-zero when more logs may follow.
+	NextCursor int64
+}
+
+type PushLogUseCase struct {
+	logRepo          repository.PushLogRepository
+	subscriptionRepo repository.PushSubscriptionRepository
+}
+
+func NewPushLogUseCase(logRepo repository.PushLogRepository, subscriptionRepo repository.PushSubscriptionRepository) *PushLogUseCase {
+	return &PushLogUseCase{
+		logRepo:          logRepo,
+		subscriptionRepo: subscriptionRepo,
+	}
+}
+
+// QueryLogs returns delivery attempts oldest first, one page at a time.
+func (u *PushLogUseCase) QueryLogs(ctx context.Context, req QueryPushLogsRequest) (*QueryPushLogsResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLogPageSize
+	}
+	limit = min(limit, maxLogPageSize)
+
+	if req.PublicSubscriptionID != nil {
+		subscription, err := u.subscriptionRepo.FindByPublicID(ctx, *req.PublicSubscriptionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find subscription: %w", err)
+		}
+		if subscription == nil {
+			return &QueryPushLogsResponse{Logs: []*model.PushLog{}}, nil
+		}
+		subscriptionID := subscription.ID()
+		req.SubscriptionID = &subscriptionID
+	}
+
+	logs, err := u.logRepo.FindLogs(ctx, repository.PushLogFilter{
+		JobID:          req.JobID,
+		SubscriptionID: req.SubscriptionID,
+		Since:          req.Since,
+		Until:          req.Until,
+		AfterID:        req.Cursor,
+		Limit:          limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push logs: %w", err)
+	}
+
+	response := &QueryPushLogsResponse{Logs: logs}
+	if len(logs) == limit {
+		response.NextCursor = logs[len(logs)-1].ID()
+	}
+	return response, nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+)
+
+func TestPushLogUseCaseQueryLogs(t *testing.T) {
+	ctx := context.Background()
+	logRepo := persistence.NewMemoryPushLogRepository()
+	uc := NewPushLogUseCase(logRepo, persistence.NewMemoryPushSubscriptionRepository())
+
+	jobID, _ := valueobject.NewJobID(1)
+	otherJobID, _ := valueobject.NewJobID(2)
+	for i := 0; i < 5; i++ {
+		id, _ := logRepo.NextIdentity(ctx)
+		status := 201
+		target := &jobID
+		if i == 2 {
+			target = &otherJobID
+		}
+		logRepo.Save(ctx, model.NewPushLog(id, target, nil, &status, nil, ""))
+	}
+
+	first, err := uc.QueryLogs(ctx, QueryPushLogsRequest{JobID: &jobID, Limit: 2})
+	if err != nil || len(first.Logs) != 2 || first.NextCursor != 2 {
+		t.Fatalf("first page = %+v, %v", first, err)
+	}
+	second, _ := uc.QueryLogs(ctx, QueryPushLogsRequest{JobID: &jobID, Limit: 2, Cursor: first.NextCursor})
+	if len(second.Logs) != 2 || second.Logs[0].ID() != 4 || second.Logs[1].ID() != 5 {
+		t.Fatalf("second page = %+v", second.Logs)
+	}
+
+	future := time.Now().Add(time.Hour)
+	none, _ := uc.QueryLogs(ctx, QueryPushLogsRequest{JobID: &jobID, Since: &future})
+	if len(none.Logs) != 0 || none.NextCursor != 0 {
+		t.Errorf("logs since the future = %d", len(none.Logs))
+	}
+}
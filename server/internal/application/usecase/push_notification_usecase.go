@@ -3,12 +3,14 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
 )
 
 type SendPushRequest struct {
@@ -18,7 +20,12 @@ type SendPushRequest struct {
 	Urgency        model.Urgency
 	TTLSeconds     int
 	Payload        model.PushPayload
-	ScheduleAt     *time.Time
+	TemplateKey    string
+	TemplateVars   map[string]string
+	// Segment narrows a job without UserID down to the matching
+	// subscriptions when it is sent.
+	Segment    *model.Segment
+	ScheduleAt *time.Time
 }
 
 type SendPushResponse struct {
@@ -33,34 +40,100 @@ type SendBatchPushRequest struct {
 	Urgency        model.Urgency
 	TTLSeconds     int
 	Payload        model.PushPayload
+	TemplateKey    string
+	TemplateVars   map[string]string
 	ScheduleAt     *time.Time
 	IdempotencyKey string
 }
 
 type SendBatchPushResponse struct {
-	JobIDs  []valueobject.JobID
-	Success bool
-	Message string
+	JobID valueobject.JobID
+	// Recipients is the number of users the job will be sent to.
+	Recipients int
+	Skipped    []service.SkippedRecipient
+	Success    bool
+	Message    string
+}
+
+// PreviewAudienceRequest describes the targeting of a job to be sent; see
+// SendPushRequest.
+type PreviewAudienceRequest struct {
+	UserID  *valueobject.UserID
+	Segment *model.Segment
+	Topic   string
+}
+
+type PreviewAudienceResponse struct {
+	Estimate *service.AudienceEstimate
+	Success  bool
+	Message  string
 }
 
 type PushNotificationUseCase struct {
 	jobRepo          repository.PushJobRepository
 	subscriptionRepo repository.PushSubscriptionRepository
+	templateRepo     repository.NotificationTemplateRepository
 	pushService      *service.PushService
+	audience         *service.AudienceService
+	notifier         repository.JobNotifier
 }
 
 func NewPushNotificationUseCase(
 	jobRepo repository.PushJobRepository,
 	subscriptionRepo repository.PushSubscriptionRepository,
+	templateRepo repository.NotificationTemplateRepository,
 	pushService *service.PushService,
+	audience *service.AudienceService,
+	notifier repository.JobNotifier,
 ) *PushNotificationUseCase {
 	return &PushNotificationUseCase{
 		jobRepo:          jobRepo,
 		subscriptionRepo: subscriptionRepo,
+		templateRepo:     templateRepo,
 		pushService:      pushService,
+		audience:         audience,
+		notifier:         notifier,
+	}
+}
+
+// notifyJobReady wakes the dispatchers for a job that becomes ready at
+// readyAt (nil means now). The job is already saved and will be found by the
+// next poll anyway, so a failed notification is only logged.
+func notifyJobReady(ctx context.Context, notifier repository.JobNotifier, readyAt *time.Time) {
+	at := time.Now()
+	if readyAt != nil {
+		at = *readyAt
+	}
+	if err := notifier.NotifyJobReady(ctx, at); err != nil {
+		log.Printf("Failed to notify dispatchers: %v", err)
 	}
 }
 
+// checkTemplate verifies that the template exists and renders with vars, so
+// callers learn about typos and missing variables before the job is queued.
+// It returns a non-empty message when the request should be rejected.
+func checkTemplate(ctx context.Context, templateRepo repository.NotificationTemplateRepository, payload model.PushPayload, templateKey string, vars map[string]string) (string, error) {
+	if templateKey == "" {
+		if len(payload) == 0 {
+			return "Either payload or templateKey is required", nil
+		}
+		return "", nil
+	}
+
+	template, err := templateRepo.FindByKey(ctx, templateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to find notification template: %w", err)
+	}
+	if template == nil {
+		return fmt.Sprintf("Notification template not found: %s", templateKey), nil
+	}
+
+	if _, err := template.Render(vars); err != nil {
+		return fmt.Sprintf("Invalid template variables: %v", err), nil
+	}
+	return "", nil
+}
+
 func (pnu *PushNotificationUseCase) SendPush(ctx context.Context, req SendPushRequest) (*SendPushResponse, error) {
 	if req.IdempotencyKey != "" {
 		existingJob, err := pnu.pushService.ValidateJobIdempotency(ctx, req.IdempotencyKey)
@@ -77,6 +150,27 @@ func (pnu *PushNotificationUseCase) SendPush(ctx context.Context, req SendPushRe
 		}
 	}
 
+	message, err := checkTemplate(ctx, pnu.templateRepo, req.Payload, req.TemplateKey, req.TemplateVars)
+	if err != nil {
+		return nil, err
+	}
+	if message != "" {
+		return &SendPushResponse{
+			Success: false,
+			Message: message,
+		}, nil
+	}
+	if req.Payload == nil {
+		req.Payload = model.PushPayload{}
+	}
+
+	if req.UserID != nil && req.Segment != nil {
+		return &SendPushResponse{
+			Success: false,
+			Message: "A segment cannot be combined with userId",
+		}, nil
+	}
+
 	if req.UserID != nil {
 		canReceive, err := pnu.pushService.CanUserReceivePush(ctx, *req.UserID)
 		if err != nil {
@@ -89,6 +183,18 @@ func (pnu *PushNotificationUseCase) SendPush(ctx context.Context, req SendPushRe
 				Message: "User has no valid push subscriptions",
 			}, nil
 		}
+
+		allowed, err := pnu.pushService.UserAllowsTopic(ctx, *req.UserID, req.Topic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check notification prefs: %w", err)
+		}
+
+		if !allowed {
+			return &SendPushResponse{
+				Success: false,
+				Message: "User has opted out of this notification topic",
+			}, nil
+		}
 	}
 
 	jobID, err := pnu.jobRepo.NextIdentity(ctx)
@@ -120,11 +226,23 @@ func (pnu *PushNotificationUseCase) SendPush(ctx context.Context, req SendPushRe
 			Message: fmt.Sprintf("Invalid job parameters: %v", err),
 		}, nil
 	}
+	if req.TemplateKey != "" {
+		job.UseTemplate(req.TemplateKey, req.TemplateVars)
+	}
+	if req.Segment != nil {
+		if err := job.TargetSegment(req.Segment); err != nil {
+			return &SendPushResponse{
+				Success: false,
+				Message: fmt.Sprintf("Invalid segment: %v", err),
+			}, nil
+		}
+	}
 
 	err = pnu.jobRepo.Save(ctx, job)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save push job: %w", err)
 	}
+	notifyJobReady(ctx, pnu.notifier, job.ScheduleAt())
 
 	return &SendPushResponse{
 		JobID:   jobID,
@@ -133,78 +251,137 @@ func (pnu *PushNotificationUseCase) SendPush(ctx context.Context, req SendPushRe
 	}, nil
 }
 
+// PreviewAudience reports how many subscriptions a job with this targeting
+// would reach if it were sent now. Nothing is created.
+func (pnu *PushNotificationUseCase) PreviewAudience(ctx context.Context, req PreviewAudienceRequest) (*PreviewAudienceResponse, error) {
+	if req.UserID != nil && req.Segment != nil {
+		return &PreviewAudienceResponse{
+			Success: false,
+			Message: "A segment cannot be combined with userId",
+		}, nil
+	}
+
+	estimate, err := pnu.audience.Estimate(ctx, service.AudienceTarget{UserID: req.UserID, Segment: req.Segment}, req.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate audience: %w", err)
+	}
+
+	return &PreviewAudienceResponse{
+		Estimate: estimate,
+		Success:  true,
+		Message:  fmt.Sprintf("%d subscriptions match", estimate.Subscriptions),
+	}, nil
+}
+
+// SendBatchPush creates a single job for every eligible user in the list.
+// Users without valid subscriptions or who opted out of the topic are
+// reported in Skipped instead of failing the request.
 func (pnu *PushNotificationUseCase) SendBatchPush(ctx context.Context, req SendBatchPushRequest) (*SendBatchPushResponse, error) {
 	if req.IdempotencyKey != "" {
-		existingJob, err := pnu.pushService.ValidateJobIdempotency(ctx, req.IdempotencyKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to validate idempotency: %w", err)
-		}
-
-		if existingJob != nil {
-			return &SendBatchPushResponse{
-				JobIDs:  []valueobject.JobID{existingJob.ID()},
-				Success: true,
-				Message: "Batch job already exists (idempotent)",
-			}, nil
+		existing, err := pnu.existingBatchPush(ctx, req.IdempotencyKey)
+		if err != nil || existing != nil {
+			return existing, err
 		}
 	}
 
-	var jobIDs []valueobject.JobID
+	message, err := checkTemplate(ctx, pnu.templateRepo, req.Payload, req.TemplateKey, req.TemplateVars)
+	if err != nil {
+		return nil, err
+	}
+	if message != "" {
+		return &SendBatchPushResponse{
+			Success: false,
+			Message: message,
+		}, nil
+	}
+	if req.Payload == nil {
+		req.Payload = model.PushPayload{}
+	}
 
-	for _, userID := range req.UserIDs {
-		canReceive, err := pnu.pushService.CanUserReceivePush(ctx, userID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check if user %s can receive push: %w", userID.String(), err)
-		}
+	recipients, skipped, err := pnu.pushService.PartitionRecipients(ctx, req.UserIDs, req.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check batch recipients: %w", err)
+	}
+	if len(recipients) == 0 {
+		return &SendBatchPushResponse{
+			Skipped: skipped,
+			Success: false,
+			Message: "None of the users can receive this notification",
+		}, nil
+	}
 
-		if !canReceive {
-			continue
-		}
+	jobID, err := pnu.jobRepo.NextIdentity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate job ID: %w", err)
+	}
 
-		jobID, err := pnu.jobRepo.NextIdentity(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate job ID: %w", err)
-		}
+	if req.Urgency == "" {
+		req.Urgency = model.UrgencyNormal
+	}
 
-		urgency := req.Urgency
-		if urgency == "" {
-			urgency = model.UrgencyNormal
-		}
+	if req.TTLSeconds <= 0 {
+		req.TTLSeconds = 86400
+	}
 
-		ttlSeconds := req.TTLSeconds
-		if ttlSeconds <= 0 {
-			ttlSeconds = 86400
-		}
+	job, err := model.NewPushJob(
+		jobID,
+		req.IdempotencyKey,
+		nil,
+		req.Topic,
+		req.Urgency,
+		req.TTLSeconds,
+		req.Payload,
+		req.ScheduleAt,
+	)
+	if err != nil {
+		return &SendBatchPushResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid job parameters: %v", err),
+		}, nil
+	}
+	if req.TemplateKey != "" {
+		job.UseTemplate(req.TemplateKey, req.TemplateVars)
+	}
+	if err := job.TargetRecipients(len(recipients)); err != nil {
+		return nil, err
+	}
 
-		userIDCopy := userID
-		job, err := model.NewPushJob(
-			jobID,
-			"",
-			&userIDCopy,
-			req.Topic,
-			urgency,
-			ttlSeconds,
-			req.Payload,
-			req.ScheduleAt,
-		)
-		if err != nil {
-			return &SendBatchPushResponse{
-				Success: false,
-				Message: fmt.Sprintf("Invalid job parameters for user %s: %v", userID.String(), err),
-			}, nil
+	if err := pnu.jobRepo.CreateBatch(ctx, job, recipients); err != nil {
+		// A concurrent request with the same key won the insert; answer
+		// with its job like a retry arriving after it would get.
+		if err == errors.ErrDuplicateIdempotencyKey {
+			existing, findErr := pnu.existingBatchPush(ctx, req.IdempotencyKey)
+			if findErr != nil || existing != nil {
+				return existing, findErr
+			}
 		}
+		return nil, fmt.Errorf("failed to save batch push job: %w", err)
+	}
+	notifyJobReady(ctx, pnu.notifier, job.ScheduleAt())
 
-		err = pnu.jobRepo.Save(ctx, job)
-		if err != nil {
-			return nil, fmt.Errorf("failed to save push job for user %s: %w", userID.String(), err)
-		}
+	return &SendBatchPushResponse{
+		JobID:      jobID,
+		Recipients: len(recipients),
+		Skipped:    skipped,
+		Success:    true,
+		Message:    fmt.Sprintf("Batch job created for %d users (%d skipped)", len(recipients), len(skipped)),
+	}, nil
+}
 
-		jobIDs = append(jobIDs, jobID)
+// existingBatchPush returns the response for a batch job already created
+// with idempotencyKey, or nil when there is none.
+func (pnu *PushNotificationUseCase) existingBatchPush(ctx context.Context, idempotencyKey string) (*SendBatchPushResponse, error) {
+	existingJob, err := pnu.pushService.ValidateJobIdempotency(ctx, idempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate idempotency: %w", err)
+	}
+	if existingJob == nil {
+		return nil, nil
 	}
-
 	return &SendBatchPushResponse{
-		JobIDs:  jobIDs,
-		Success: true,
-		Message: fmt.Sprintf("Created %d push jobs successfully", len(jobIDs)),
+		JobID:      existingJob.ID(),
+		Recipients: existingJob.RecipientCount(),
+		Success:    true,
+		Message:    "Batch job already exists (idempotent)",
 	}, nil
 }
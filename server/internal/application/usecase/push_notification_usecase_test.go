@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+)
+
+func TestPushNotificationUseCaseSegments(t *testing.T) {
+	ctx := context.Background()
+
+	subscriptionUseCase, userRepo := newTestSubscriptionUseCase(t)
+	subscriptionRepo := subscriptionUseCase.subscriptionRepo
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	prefsRepo := persistence.NewMemoryNotificationPrefsRepository()
+	pushService := service.NewPushService(subscriptionRepo, jobRepo, prefsRepo)
+	audience := service.NewAudienceService(subscriptionRepo, userRepo, prefsRepo)
+	uc := NewPushNotificationUseCase(jobRepo, subscriptionRepo, persistence.NewMemoryNotificationTemplateRepository(jobRepo, persistence.NewMemoryPushScheduleRepository(jobRepo)), pushService, audience, persistence.NewMemoryJobNotifier())
+
+	alice := newTestUser(t, userRepo, "alice@example.com")
+	bob := newTestUser(t, userRepo, "bob@example.com")
+	aliceNews := model.NewNotificationPrefs(alice)
+	aliceNews.Update(true, map[string]bool{"news": true}, nil)
+	prefsRepo.Save(ctx, aliceNews)
+	bobNoNews := model.NewNotificationPrefs(bob)
+	bobNoNews.Update(true, map[string]bool{"news": false}, nil)
+	prefsRepo.Save(ctx, bobNoNews)
+
+	for i, userID := range []*valueobject.UserID{&alice, &alice, &bob, nil} {
+		result, err := subscriptionUseCase.Subscribe(ctx, SubscribePushRequest{
+			UserID:    userID,
+			Endpoint:  "https://fcm.googleapis.com/fcm/send/device-" + string(rune('a'+i)),
+			P256dhKey: testP256dh,
+			AuthKey:   testAuth,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("Subscribe = %+v, %v", result, err)
+		}
+	}
+
+	everyone, err := uc.PreviewAudience(ctx, PreviewAudienceRequest{Topic: "news"})
+	if err != nil || !everyone.Success {
+		t.Fatalf("PreviewAudience = %+v, %v", everyone, err)
+	}
+	if e := everyone.Estimate; e.Subscriptions != 4 || e.Users != 2 || e.Anonymous != 1 || e.OptedOut != 1 {
+		t.Errorf("estimate without segment = %+v", *e)
+	}
+
+	segment, _ := model.NewSegment([]string{"news"}, nil, nil, nil, 0, 0)
+	optedIn, err := uc.PreviewAudience(ctx, PreviewAudienceRequest{Segment: segment, Topic: "news"})
+	if err != nil || !optedIn.Success {
+		t.Fatalf("PreviewAudience(segment) = %+v, %v", optedIn, err)
+	}
+	if e := optedIn.Estimate; e.Subscriptions != 2 || e.Users != 1 || e.Anonymous != 0 || e.OptedOut != 0 {
+		t.Errorf("estimate for opted-in users = %+v", *e)
+	}
+
+	rejected, err := uc.SendPush(ctx, SendPushRequest{UserID: &alice, Segment: segment, Payload: model.PushPayload{"title": "hi"}})
+	if err != nil || rejected.Success {
+		t.Fatalf("SendPush(userId + segment) = %+v, %v, want failure", rejected, err)
+	}
+
+	created, err := uc.SendPush(ctx, SendPushRequest{Segment: segment, Topic: "news", Payload: model.PushPayload{"title": "hi"}})
+	if err != nil || !created.Success {
+		t.Fatalf("SendPush(segment) = %+v, %v", created, err)
+	}
+	job, _ := jobRepo.FindByID(ctx, created.JobID)
+	if job == nil || job.Segment() == nil || job.Segment().Topics()[0] != "news" {
+		t.Fatalf("saved job segment = %+v", job)
+	}
+}
+
+func TestPushNotificationUseCaseSendBatchPush(t *testing.T) {
+	ctx := context.Background()
+
+	subscriptionUseCase, userRepo := newTestSubscriptionUseCase(t)
+	subscriptionRepo := subscriptionUseCase.subscriptionRepo
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	prefsRepo := persistence.NewMemoryNotificationPrefsRepository()
+	pushService := service.NewPushService(subscriptionRepo, jobRepo, prefsRepo)
+	audience := service.NewAudienceService(subscriptionRepo, userRepo, prefsRepo)
+	uc := NewPushNotificationUseCase(jobRepo, subscriptionRepo, persistence.NewMemoryNotificationTemplateRepository(jobRepo, persistence.NewMemoryPushScheduleRepository(jobRepo)), pushService, audience, persistence.NewMemoryJobNotifier())
+
+	subscribed := newTestUser(t, userRepo, "subscribed@example.com")
+	optedOut := newTestUser(t, userRepo, "opted-out@example.com")
+	unsubscribed := newTestUser(t, userRepo, "unsubscribed@example.com")
+	noPromo := model.NewNotificationPrefs(optedOut)
+	noPromo.Update(true, map[string]bool{"promo": false}, nil)
+	prefsRepo.Save(ctx, noPromo)
+
+	for i, userID := range []valueobject.UserID{subscribed, optedOut} {
+		result, err := subscriptionUseCase.Subscribe(ctx, SubscribePushRequest{
+			UserID:    &userID,
+			Endpoint:  "https://fcm.googleapis.com/fcm/send/batch-" + string(rune('a'+i)),
+			P256dhKey: testP256dh,
+			AuthKey:   testAuth,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("Subscribe = %+v, %v", result, err)
+		}
+	}
+
+	req := SendBatchPushRequest{
+		UserIDs:        []valueobject.UserID{subscribed, optedOut, unsubscribed, subscribed},
+		Topic:          "promo",
+		Payload:        model.PushPayload{"title": "sale"},
+		IdempotencyKey: "campaign-1",
+	}
+	result, err := uc.SendBatchPush(ctx, req)
+	if err != nil || !result.Success {
+		t.Fatalf("SendBatchPush = %+v, %v", result, err)
+	}
+	if result.Recipients != 1 || len(result.Skipped) != 2 {
+		t.Fatalf("recipients = %d, skipped = %+v", result.Recipients, result.Skipped)
+	}
+	reasons := map[valueobject.UserID]service.RecipientSkipReason{}
+	for _, skipped := range result.Skipped {
+		reasons[skipped.UserID] = skipped.Reason
+	}
+	if reasons[optedOut] != service.RecipientSkipOptedOut || reasons[unsubscribed] != service.RecipientSkipNoSubscriptions {
+		t.Errorf("skip reasons = %v", reasons)
+	}
+
+	job, _ := jobRepo.FindByID(ctx, result.JobID)
+	if job == nil || !job.IsBatch() || job.RecipientCount() != 1 || job.IdempotencyKey() != "campaign-1" {
+		t.Fatalf("batch job = %+v", job)
+	}
+	recipients, _ := jobRepo.FindRecipients(ctx, job.ID())
+	if len(recipients) != 1 || !recipients[0].Equals(subscribed) {
+		t.Errorf("recipients = %v, want [%s]", recipients, subscribed.String())
+	}
+
+	again, err := uc.SendBatchPush(ctx, req)
+	if err != nil || !again.Success || !again.JobID.Equals(result.JobID) {
+		t.Fatalf("retried SendBatchPush = %+v, %v, want job %s", again, err, result.JobID.String())
+	}
+
+	// A concurrent request that checked the key before the first job was
+	// saved loses the insert and must still answer with the first job.
+	racing := &staleIdempotencyJobRepository{MemoryPushJobRepository: jobRepo, stale: 1}
+	racingUC := NewPushNotificationUseCase(racing, subscriptionRepo, uc.templateRepo, service.NewPushService(subscriptionRepo, racing, prefsRepo), audience, persistence.NewMemoryJobNotifier())
+	raced, err := racingUC.SendBatchPush(ctx, req)
+	if err != nil || !raced.Success || !raced.JobID.Equals(result.JobID) {
+		t.Fatalf("concurrent SendBatchPush = %+v, %v, want job %s", raced, err, result.JobID.String())
+	}
+
+	pending, _ := jobRepo.FindPendingJobs(ctx, 10)
+	if len(pending) != 1 {
+		t.Errorf("%d pending jobs, want a single batch job", len(pending))
+	}
+}
+
+// staleIdempotencyJobRepository misses the first stale idempotency key
+// lookups, as a request racing the one that creates the job would.
+type staleIdempotencyJobRepository struct {
+	*persistence.MemoryPushJobRepository
+	stale int
+}
+
+func (r *staleIdempotencyJobRepository) FindByIdempotencyKey(ctx context.Context, key string) (*model.PushJob, error) {
+	if r.stale > 0 {
+		r.stale--
+		return nil, nil
+	}
+	return r.MemoryPushJobRepository.FindByIdempotencyKey(ctx, key)
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+// CreatePushScheduleRequest describes a recurring send. The job fields are
+// those of SendPushRequest; Cron is evaluated in Timezone (UTC if empty).
+type CreatePushScheduleRequest struct {
+	Name         string
+	Cron         string
+	Timezone     string
+	UserID       *valueobject.UserID
+	Topic        string
+	Urgency      model.Urgency
+	TTLSeconds   int
+	Payload      model.PushPayload
+	TemplateKey  string
+	TemplateVars map[string]string
+	Segment      *model.Segment
+}
+
+type PushScheduleUseCase struct {
+	scheduleRepo repository.PushScheduleRepository
+	templateRepo repository.NotificationTemplateRepository
+	notifier     repository.JobNotifier
+}
+
+func NewPushScheduleUseCase(
+	scheduleRepo repository.PushScheduleRepository,
+	templateRepo repository.NotificationTemplateRepository,
+	notifier repository.JobNotifier,
+) *PushScheduleUseCase {
+	return &PushScheduleUseCase{
+		scheduleRepo: scheduleRepo,
+		templateRepo: templateRepo,
+		notifier:     notifier,
+	}
+}
+
+func (u *PushScheduleUseCase) CreateSchedule(ctx context.Context, req CreatePushScheduleRequest) (*model.PushSchedule, error) {
+	cron, err := valueobject.NewCronSchedule(req.Cron, req.Timezone)
+	if err != nil {
+		return nil, invalidSchedule(err.Error())
+	}
+
+	message, err := checkTemplate(ctx, u.templateRepo, req.Payload, req.TemplateKey, req.TemplateVars)
+	if err != nil {
+		return nil, err
+	}
+	if message != "" {
+		return nil, invalidSchedule(message)
+	}
+	if req.Payload == nil {
+		req.Payload = model.PushPayload{}
+	}
+	if req.Urgency == "" {
+		req.Urgency = model.UrgencyNormal
+	}
+	if req.TTLSeconds <= 0 {
+		req.TTLSeconds = 86400
+	}
+
+	id, err := u.scheduleRepo.NextIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := model.NewPushSchedule(id, req.Name, cron, req.UserID, req.Topic, req.Urgency, req.TTLSeconds, req.Payload)
+	if err != nil {
+		return nil, invalidSchedule(err.Error())
+	}
+	if req.TemplateKey != "" {
+		schedule.UseTemplate(req.TemplateKey, req.TemplateVars)
+	}
+	if req.Segment != nil {
+		if err := schedule.TargetSegment(req.Segment); err != nil {
+			return nil, invalidSchedule(err.Error())
+		}
+	}
+
+	if err := u.scheduleRepo.Save(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to save push schedule: %w", err)
+	}
+	notifyJobReady(ctx, u.notifier, schedule.NextRunAt())
+	return schedule, nil
+}
+
+func (u *PushScheduleUseCase) GetSchedule(ctx context.Context, id valueobject.ScheduleID) (*model.PushSchedule, error) {
+	schedule, err := u.scheduleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, errors.ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+func (u *PushScheduleUseCase) ListSchedules(ctx context.Context) ([]*model.PushSchedule, error) {
+	return u.scheduleRepo.FindAll(ctx)
+}
+
+// PauseSchedule stops the schedule from spawning jobs. Jobs it already
+// spawned are not affected; cancel them through the jobs API.
+func (u *PushScheduleUseCase) PauseSchedule(ctx context.Context, id valueobject.ScheduleID) (*model.PushSchedule, error) {
+	schedule, err := u.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status() == model.ScheduleStatusPaused {
+		return schedule, nil
+	}
+
+	schedule.Pause()
+	if err := u.scheduleRepo.Save(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to save push schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// ResumeSchedule reactivates the schedule from its next occurrence.
+func (u *PushScheduleUseCase) ResumeSchedule(ctx context.Context, id valueobject.ScheduleID) (*model.PushSchedule, error) {
+	schedule, err := u.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status() == model.ScheduleStatusActive {
+		return schedule, nil
+	}
+
+	if err := schedule.Resume(time.Now()); err != nil {
+		return nil, invalidSchedule(err.Error())
+	}
+	if err := u.scheduleRepo.Save(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to save push schedule: %w", err)
+	}
+	notifyJobReady(ctx, u.notifier, schedule.NextRunAt())
+	return schedule, nil
+}
+
+func (u *PushScheduleUseCase) DeleteSchedule(ctx context.Context, id valueobject.ScheduleID) error {
+	if _, err := u.GetSchedule(ctx, id); err != nil {
+		return err
+	}
+	return u.scheduleRepo.Delete(ctx, id)
+}
+
+func invalidSchedule(message string) *errors.DomainError {
+	return errors.NewDomainError(errors.ErrInvalidSchedule.Code, message)
+}
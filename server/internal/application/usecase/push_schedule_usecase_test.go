@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+func TestPushScheduleUseCaseCreateSchedule(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	scheduleRepo := persistence.NewMemoryPushScheduleRepository(jobRepo)
+	uc := NewPushScheduleUseCase(
+		scheduleRepo,
+		persistence.NewMemoryNotificationTemplateRepository(jobRepo, scheduleRepo),
+		persistence.NewMemoryJobNotifier(),
+	)
+	userID, _ := valueobject.NewUserID(1)
+	segment, _ := model.NewSegment([]string{"news"}, nil, nil, nil, 0, 0)
+
+	tests := []struct {
+		name string
+		req  CreatePushScheduleRequest
+	}{
+		{"missing name", CreatePushScheduleRequest{Cron: "@daily", Payload: model.PushPayload{"title": "hi"}}},
+		{"invalid cron", CreatePushScheduleRequest{Name: "digest", Cron: "every day", Payload: model.PushPayload{"title": "hi"}}},
+		{"invalid timezone", CreatePushScheduleRequest{Name: "digest", Cron: "@daily", Timezone: "Nowhere", Payload: model.PushPayload{"title": "hi"}}},
+		{"no payload", CreatePushScheduleRequest{Name: "digest", Cron: "@daily"}},
+		{"unknown template", CreatePushScheduleRequest{Name: "digest", Cron: "@daily", TemplateKey: "missing"}},
+		{"user and segment", CreatePushScheduleRequest{Name: "digest", Cron: "@daily", UserID: &userID, Segment: segment, Payload: model.PushPayload{"title": "hi"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.CreateSchedule(ctx, tt.req)
+			domainErr, ok := err.(*errors.DomainError)
+			if !ok || domainErr.Code != errors.ErrInvalidSchedule.Code {
+				t.Errorf("CreateSchedule = %v, want INVALID_SCHEDULE", err)
+			}
+		})
+	}
+
+	schedule, err := uc.CreateSchedule(ctx, CreatePushScheduleRequest{
+		Name:     "morning digest",
+		Cron:     "0 9 * * *",
+		Timezone: "Asia/Tokyo",
+		Payload:  model.PushPayload{"title": "Good morning"},
+		Segment:  segment,
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+	if schedule.Status() != model.ScheduleStatusActive || schedule.NextRunAt() == nil || schedule.Urgency() != model.UrgencyNormal {
+		t.Errorf("schedule = status %s, next %v, urgency %s", schedule.Status(), schedule.NextRunAt(), schedule.Urgency())
+	}
+	if hour := schedule.NextRunAt().In(time.FixedZone("JST", 9*60*60)).Hour(); hour != 9 {
+		t.Errorf("next run is at %d:00 in Tokyo, want 9:00", hour)
+	}
+}
+
+func TestPushScheduleUseCasePauseResumeDelete(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := persistence.NewMemoryPushJobRepository()
+	scheduleRepo := persistence.NewMemoryPushScheduleRepository(jobRepo)
+	uc := NewPushScheduleUseCase(
+		scheduleRepo,
+		persistence.NewMemoryNotificationTemplateRepository(jobRepo, scheduleRepo),
+		persistence.NewMemoryJobNotifier(),
+	)
+	schedule, err := uc.CreateSchedule(ctx, CreatePushScheduleRequest{Name: "reminder", Cron: "@weekly", Payload: model.PushPayload{"title": "hi"}})
+	if err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+
+	paused, err := uc.PauseSchedule(ctx, schedule.ID())
+	if err != nil || paused.Status() != model.ScheduleStatusPaused || paused.NextRunAt() != nil {
+		t.Fatalf("PauseSchedule = %v, %v", paused, err)
+	}
+	stored, _ := uc.GetSchedule(ctx, schedule.ID())
+	if stored.Status() != model.ScheduleStatusPaused {
+		t.Errorf("stored status = %s after pause", stored.Status())
+	}
+
+	resumed, err := uc.ResumeSchedule(ctx, schedule.ID())
+	if err != nil || resumed.Status() != model.ScheduleStatusActive || resumed.NextRunAt() == nil {
+		t.Fatalf("ResumeSchedule = %v, %v", resumed, err)
+	}
+
+	if err := uc.DeleteSchedule(ctx, schedule.ID()); err != nil {
+		t.Fatalf("DeleteSchedule: %v", err)
+	}
+	if _, err := uc.GetSchedule(ctx, schedule.ID()); err != errors.ErrScheduleNotFound {
+		t.Errorf("GetSchedule after delete = %v, want ErrScheduleNotFound", err)
+	}
+	if _, err := uc.PauseSchedule(ctx, schedule.ID()); err != errors.ErrScheduleNotFound {
+		t.Errorf("PauseSchedule after delete = %v, want ErrScheduleNotFound", err)
+	}
+}
@@ -9,25 +9,34 @@ import (
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
 )
 
 type SubscribePushRequest struct {
+	// UserID is the signed-in caller. Subscribing again with the same
+	// endpoint binds an anonymous subscription to them.
 	UserID         *valueobject.UserID
 	Endpoint       string
 	P256dhKey      string
 	AuthKey        string
 	UserAgent      string
 	ExpirationTime *int64
+	VAPIDPublicKey string
 }
 
 type SubscribePushResponse struct {
-	SubscriptionID valueobject.SubscriptionID
+	SubscriptionID valueobject.PublicSubscriptionID
 	Success        bool
 	Message        string
 }
 
+// UnsubscribePushRequest must prove ownership: the signed-in owner, or the
+// subscription's endpoint URL or auth secret, which only the browser knows.
 type UnsubscribePushRequest struct {
-	SubscriptionID valueobject.SubscriptionID
+	SubscriptionID valueobject.PublicSubscriptionID
+	UserID         *valueobject.UserID
+	Endpoint       string
+	AuthSecret     string
 }
 
 type UnsubscribePushResponse struct {
@@ -37,16 +46,22 @@ type UnsubscribePushResponse struct {
 
 type PushSubscriptionUseCase struct {
 	subscriptionRepo repository.PushSubscriptionRepository
+	userRepo         repository.UserRepository
 	pushService      *service.PushService
+	vapidKeyService  *service.VAPIDKeyService
 }
 
 func NewPushSubscriptionUseCase(
 	subscriptionRepo repository.PushSubscriptionRepository,
+	userRepo repository.UserRepository,
 	pushService *service.PushService,
+	vapidKeyService *service.VAPIDKeyService,
 ) *PushSubscriptionUseCase {
 	return &PushSubscriptionUseCase{
 		subscriptionRepo: subscriptionRepo,
+		userRepo:         userRepo,
 		pushService:      pushService,
+		vapidKeyService:  vapidKeyService,
 	}
 }
 
@@ -75,6 +90,30 @@ func (psu *PushSubscriptionUseCase) Subscribe(ctx context.Context, req Subscribe
 		}, nil
 	}
 
+	vapidKey, err := psu.resolveVAPIDKey(ctx, req.VAPIDPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if vapidKey == nil {
+		return &SubscribePushResponse{
+			Success: false,
+			Message: "Unknown application server key",
+		}, nil
+	}
+
+	if req.UserID != nil {
+		user, err := psu.userRepo.FindByID(ctx, *req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find user: %w", err)
+		}
+		if user == nil {
+			return &SubscribePushResponse{
+				Success: false,
+				Message: "User not found",
+			}, nil
+		}
+	}
+
 	isDuplicate, err := psu.pushService.IsSubscriptionDuplicate(ctx, endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check duplicate subscription: %w", err)
@@ -89,6 +128,15 @@ func (psu *PushSubscriptionUseCase) Subscribe(ctx context.Context, req Subscribe
 		keys := valueobject.NewPushKeys(p256dh, auth)
 		existing.UpdateKeys(keys)
 		existing.UpdateUserAgent(req.UserAgent)
+		existing.AssignVAPIDKey(vapidKey.ID())
+		if !existing.IsValid() {
+			existing.Revalidate()
+		}
+		// Anonymous requests never unbind; only a signed-in user can claim
+		// the browser.
+		if req.UserID != nil && !existing.IsOwnedBy(*req.UserID) {
+			existing.BindToUser(*req.UserID)
+		}
 
 		err = psu.subscriptionRepo.Save(ctx, existing)
 		if err != nil {
@@ -96,7 +144,7 @@ func (psu *PushSubscriptionUseCase) Subscribe(ctx context.Context, req Subscribe
 		}
 
 		return &SubscribePushResponse{
-			SubscriptionID: existing.ID(),
+			SubscriptionID: existing.PublicID(),
 			Success:        true,
 			Message:        "Subscription updated successfully",
 		}, nil
@@ -122,6 +170,7 @@ func (psu *PushSubscriptionUseCase) Subscribe(ctx context.Context, req Subscribe
 		req.UserAgent,
 		expirationTime,
 	)
+	subscription.AssignVAPIDKey(vapidKey.ID())
 
 	err = psu.subscriptionRepo.Save(ctx, subscription)
 	if err != nil {
@@ -129,23 +178,44 @@ func (psu *PushSubscriptionUseCase) Subscribe(ctx context.Context, req Subscribe
 	}
 
 	return &SubscribePushResponse{
-		SubscriptionID: id,
+		SubscriptionID: subscription.PublicID(),
 		Success:        true,
 		Message:        "Subscription created successfully",
 	}, nil
 }
 
+// resolveVAPIDKey returns the key the browser subscribed with: the one
+// matching publicKey, or the active key when the client did not say. It
+// returns nil for a public key the server does not know.
+func (psu *PushSubscriptionUseCase) resolveVAPIDKey(ctx context.Context, publicKey string) (*model.VAPIDKey, error) {
+	if publicKey == "" {
+		key, err := psu.vapidKeyService.ActiveKey(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active VAPID key: %w", err)
+		}
+		return key, nil
+	}
+
+	key, err := psu.vapidKeyService.FindByPublicKey(ctx, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find VAPID key: %w", err)
+	}
+	return key, nil
+}
+
 func (psu *PushSubscriptionUseCase) Unsubscribe(ctx context.Context, req UnsubscribePushRequest) (*UnsubscribePushResponse, error) {
-	subscription, err := psu.subscriptionRepo.FindByID(ctx, req.SubscriptionID)
+	subscription, err := psu.subscriptionRepo.FindByPublicID(ctx, req.SubscriptionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find subscription: %w", err)
 	}
 
 	if subscription == nil {
-		return &UnsubscribePushResponse{
-			Success: false,
-			Message: "Subscription not found",
-		}, nil
+		return nil, errors.ErrSubscriptionNotFound
+	}
+
+	ownedByCaller := req.UserID != nil && subscription.IsOwnedBy(*req.UserID)
+	if !ownedByCaller && !subscription.ProvesOwnership(req.Endpoint, req.AuthSecret) {
+		return nil, errors.ErrSubscriptionOwnership
 	}
 
 	subscription.MarkAsInvalid()
@@ -159,3 +229,31 @@ func (psu *PushSubscriptionUseCase) Unsubscribe(ctx context.Context, req Unsubsc
 		Message: "Subscription removed successfully",
 	}, nil
 }
+
+// ListUserSubscriptions returns the user's registered browsers.
+func (psu *PushSubscriptionUseCase) ListUserSubscriptions(ctx context.Context, userID valueobject.UserID) ([]*model.PushSubscription, error) {
+	user, err := psu.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	return psu.subscriptionRepo.FindValidSubscriptionsByUserID(ctx, userID)
+}
+
+// RevokeUserSubscription stops pushes to one of the user's browsers.
+// Subscriptions of other users are reported as not found.
+func (psu *PushSubscriptionUseCase) RevokeUserSubscription(ctx context.Context, userID valueobject.UserID, subscriptionID valueobject.PublicSubscriptionID) error {
+	subscription, err := psu.subscriptionRepo.FindByPublicID(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil || !subscription.IsOwnedBy(userID) || !subscription.IsValid() {
+		return errors.ErrSubscriptionNotFound
+	}
+
+	subscription.MarkAsInvalid()
+	return psu.subscriptionRepo.Save(ctx, subscription)
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/infrastructure/persistence"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+const (
+	testP256dh = "BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM"
+	testAuth   = "tBHItJI5svbpez7KI4CCXg"
+)
+
+func newTestSubscriptionUseCase(t *testing.T) (*PushSubscriptionUseCase, *persistence.MemoryUserRepository) {
+	t.Helper()
+	ctx := context.Background()
+
+	subscriptionRepo := persistence.NewMemoryPushSubscriptionRepository()
+	userRepo := persistence.NewMemoryUserRepository()
+	vapidKeys := service.NewVAPIDKeyService(persistence.NewMemoryVAPIDKeyRepository())
+	if _, err := vapidKeys.EnsureActiveKey(ctx, nil); err != nil {
+		t.Fatalf("EnsureActiveKey: %v", err)
+	}
+	pushService := service.NewPushService(subscriptionRepo, persistence.NewMemoryPushJobRepository(), persistence.NewMemoryNotificationPrefsRepository())
+	return NewPushSubscriptionUseCase(subscriptionRepo, userRepo, pushService, vapidKeys), userRepo
+}
+
+func newTestUser(t *testing.T, repo *persistence.MemoryUserRepository, email string) valueobject.UserID {
+	t.Helper()
+	ctx := context.Background()
+
+	id, _ := repo.NextIdentity(ctx)
+	addr, _ := valueobject.NewEmail(email)
+	if err := repo.Save(ctx, model.NewUser(id, "Test User", addr)); err != nil {
+		t.Fatalf("Save user: %v", err)
+	}
+	return id
+}
+
+func TestPushSubscriptionUseCaseBindsUser(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo := newTestSubscriptionUseCase(t)
+	alice := newTestUser(t, userRepo, "alice@example.com")
+	bob := newTestUser(t, userRepo, "bob@example.com")
+
+	subscribe := func(userID *valueobject.UserID) valueobject.PublicSubscriptionID {
+		t.Helper()
+		result, err := uc.Subscribe(ctx, SubscribePushRequest{
+			UserID:    userID,
+			Endpoint:  "https://fcm.googleapis.com/fcm/send/device",
+			P256dhKey: testP256dh,
+			AuthKey:   testAuth,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("Subscribe = %+v, %v", result, err)
+		}
+		return result.SubscriptionID
+	}
+
+	// Subscribed before login, then again after login from the same browser.
+	anonymous := subscribe(nil)
+	if subscriptions, _ := uc.ListUserSubscriptions(ctx, alice); len(subscriptions) != 0 {
+		t.Fatalf("alice has %d subscriptions before login, want 0", len(subscriptions))
+	}
+	if id := subscribe(&alice); !id.Equals(anonymous) {
+		t.Fatalf("re-subscribe created %v, want the anonymous subscription %v", id, anonymous)
+	}
+	subscriptions, err := uc.ListUserSubscriptions(ctx, alice)
+	if err != nil || len(subscriptions) != 1 || subscriptions[0].Endpoint().Host() != "fcm.googleapis.com" {
+		t.Fatalf("ListUserSubscriptions = %v, %v", subscriptions, err)
+	}
+
+	// An anonymous re-subscribe keeps the owner.
+	subscribe(nil)
+	if subscriptions, _ := uc.ListUserSubscriptions(ctx, alice); len(subscriptions) != 1 {
+		t.Errorf("anonymous re-subscribe unbound the subscription")
+	}
+
+	if err := uc.RevokeUserSubscription(ctx, bob, anonymous); err != errors.ErrSubscriptionNotFound {
+		t.Errorf("RevokeUserSubscription(bob) = %v, want ErrSubscriptionNotFound", err)
+	}
+	if err := uc.RevokeUserSubscription(ctx, alice, anonymous); err != nil {
+		t.Fatalf("RevokeUserSubscription(alice): %v", err)
+	}
+	if subscriptions, _ := uc.ListUserSubscriptions(ctx, alice); len(subscriptions) != 0 {
+		t.Errorf("revoked subscription is still listed")
+	}
+
+	// Subscribing again from the browser re-enables it.
+	subscribe(&alice)
+	if subscriptions, _ := uc.ListUserSubscriptions(ctx, alice); len(subscriptions) != 1 {
+		t.Errorf("re-subscribe did not revalidate the subscription")
+	}
+
+	unknown, _ := valueobject.NewUserID(999)
+	result, err := uc.Subscribe(ctx, SubscribePushRequest{
+		UserID:    &unknown,
+		Endpoint:  "https://fcm.googleapis.com/fcm/send/other",
+		P256dhKey: testP256dh,
+		AuthKey:   testAuth,
+	})
+	if err != nil || result.Success {
+		t.Errorf("Subscribe(unknown user) = %+v, %v, want failure", result, err)
+	}
+	if _, err := uc.ListUserSubscriptions(ctx, unknown); err != errors.ErrUserNotFound {
+		t.Errorf("ListUserSubscriptions(unknown) = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestPushSubscriptionUseCaseUnsubscribeRequiresOwnership(t *testing.T) {
+	ctx := context.Background()
+	uc, userRepo := newTestSubscriptionUseCase(t)
+	alice := newTestUser(t, userRepo, "alice@example.com")
+	bob := newTestUser(t, userRepo, "bob@example.com")
+
+	const endpoint = "https://fcm.googleapis.com/fcm/send/device"
+	subscribe := func(userID *valueobject.UserID) valueobject.PublicSubscriptionID {
+		t.Helper()
+		result, err := uc.Subscribe(ctx, SubscribePushRequest{UserID: userID, Endpoint: endpoint, P256dhKey: testP256dh, AuthKey: testAuth})
+		if err != nil || !result.Success {
+			t.Fatalf("Subscribe = %+v, %v", result, err)
+		}
+		return result.SubscriptionID
+	}
+	id := subscribe(&alice)
+	if _, err := valueobject.PublicSubscriptionIDFromString(id.String()); err != nil {
+		t.Fatalf("subscription ID %q is not opaque: %v", id, err)
+	}
+
+	denied := []UnsubscribePushRequest{
+		{SubscriptionID: id},
+		{SubscriptionID: id, UserID: &bob},
+		{SubscriptionID: id, Endpoint: "https://fcm.googleapis.com/fcm/send/other"},
+		{SubscriptionID: id, AuthSecret: "AAAAAAAAAAAAAAAAAAAAAA"},
+	}
+	for _, req := range denied {
+		if _, err := uc.Unsubscribe(ctx, req); err != errors.ErrSubscriptionOwnership {
+			t.Errorf("Unsubscribe(%+v) = %v, want ErrSubscriptionOwnership", req, err)
+		}
+	}
+
+	allowed := []UnsubscribePushRequest{
+		{SubscriptionID: id, UserID: &alice},
+		{SubscriptionID: id, Endpoint: endpoint},
+		{SubscriptionID: id, AuthSecret: testAuth},
+	}
+	for _, req := range allowed {
+		subscribe(&alice)
+		if _, err := uc.Unsubscribe(ctx, req); err != nil {
+			t.Errorf("Unsubscribe(%+v) = %v", req, err)
+		}
+	}
+
+	if _, err := uc.Unsubscribe(ctx, UnsubscribePushRequest{SubscriptionID: valueobject.NewPublicSubscriptionID(), UserID: &alice}); err != errors.ErrSubscriptionNotFound {
+		t.Errorf("Unsubscribe(unknown) = %v, want ErrSubscriptionNotFound", err)
+	}
+}
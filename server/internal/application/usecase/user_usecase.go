@@ -10,6 +10,14 @@ import (
 	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
 )
 
+// UpdateUserRequest changes the fields that are set. Attributes replace the
+// existing ones as a whole.
+type UpdateUserRequest struct {
+	Name       *string
+	Locale     *string
+	Attributes map[string]string
+}
+
 type UserUseCase struct {
 	userRepo    repository.UserRepository
 	userService *service.UserService
@@ -67,6 +75,33 @@ func (u *UserUseCase) GetAllUsers(ctx context.Context) ([]*model.User, error) {
 	return u.userRepo.FindAll(ctx)
 }
 
+func (u *UserUseCase) UpdateUser(ctx context.Context, userIDInt int, req UpdateUserRequest) (*model.User, error) {
+	user, err := u.GetUser(ctx, userIDInt)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if err := user.ChangeName(*req.Name); err != nil {
+			return nil, errors.WrapDomainError(errors.ErrInvalidUserProfile.Code, "Invalid name", err)
+		}
+	}
+	if req.Locale != nil {
+		user.ChangeLocale(*req.Locale)
+	}
+	if req.Attributes != nil {
+		if err := user.SetAttributes(req.Attributes); err != nil {
+			return nil, errors.WrapDomainError(errors.ErrInvalidUserProfile.Code, "Invalid attributes", err)
+		}
+	}
+
+	if err := u.userRepo.Save(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 func (u *UserUseCase) DeleteUser(ctx context.Context, userIDInt int) error {
 	userID, err := valueobject.NewUserID(userIDInt)
 	if err != nil {
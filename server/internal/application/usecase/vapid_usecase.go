@@ -1,7 +1,12 @@
 package usecase
 
 import (
+	"context"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/service"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
 )
 
 type GetVAPIDPublicKeyResponse struct {
@@ -11,21 +16,48 @@ type GetVAPIDPublicKeyResponse struct {
 }
 
 type VAPIDUseCase struct {
-	vapidService *service.VAPIDService
+	vapidKeyService *service.VAPIDKeyService
 }
 
-func NewVAPIDUseCase(vapidService *service.VAPIDService) *VAPIDUseCase {
+func NewVAPIDUseCase(vapidKeyService *service.VAPIDKeyService) *VAPIDUseCase {
 	return &VAPIDUseCase{
-		vapidService: vapidService,
+		vapidKeyService: vapidKeyService,
 	}
 }
 
-func (vu *VAPIDUseCase) GetPublicKey() *GetVAPIDPublicKeyResponse {
-	publicKey := vu.vapidService.GetPublicKey()
+func (vu *VAPIDUseCase) GetPublicKey(ctx context.Context) (*GetVAPIDPublicKeyResponse, error) {
+	key, err := vu.vapidKeyService.ActiveKey(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	return &GetVAPIDPublicKeyResponse{
-		PublicKey: publicKey,
+		PublicKey: key.PublicKey(),
 		Success:   true,
 		Message:   "VAPID public key retrieved successfully",
+	}, nil
+}
+
+func (vu *VAPIDUseCase) ListKeys(ctx context.Context) ([]*model.VAPIDKey, error) {
+	return vu.vapidKeyService.ListKeys(ctx)
+}
+
+// GenerateKey creates a new key pair. Activating it starts handing it out to
+// browsers; subscriptions made under older keys keep working. subject may be
+// empty to use the server's default VAPID subject.
+func (vu *VAPIDUseCase) GenerateKey(ctx context.Context, activate bool, subject string) (*model.VAPIDKey, error) {
+	var vapidSubject valueobject.VAPIDSubject
+	if subject != "" {
+		parsed, err := valueobject.NewVAPIDSubject(subject)
+		if err != nil {
+			return nil, errors.WrapDomainError(errors.ErrInvalidVAPIDSubject.Code, errors.ErrInvalidVAPIDSubject.Message, err)
+		}
+		vapidSubject = parsed
 	}
+
+	return vu.vapidKeyService.GenerateKey(ctx, activate, vapidSubject)
+}
+
+func (vu *VAPIDUseCase) ActivateKey(ctx context.Context, id int64) (*model.VAPIDKey, error) {
+	return vu.vapidKeyService.ActivateKey(ctx, id)
 }
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// NotificationEventType is what the Service Worker observed for a push.
+type NotificationEventType string
+
+const (
+	// NotificationEventDelivered is reported when the push event fires.
+	NotificationEventDelivered NotificationEventType = "delivered"
+	// NotificationEventDisplayed is reported after showNotification resolves.
+	NotificationEventDisplayed NotificationEventType = "displayed"
+	NotificationEventClicked   NotificationEventType = "clicked"
+	NotificationEventClosed    NotificationEventType = "closed"
+)
+
+func (t NotificationEventType) IsValid() bool {
+	switch t {
+	case NotificationEventDelivered, NotificationEventDisplayed, NotificationEventClicked, NotificationEventClosed:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsInteraction reports whether the event needed the user to act on the
+// notification, as opposed to being reported automatically.
+func (t NotificationEventType) IsInteraction() bool {
+	return t == NotificationEventClicked || t == NotificationEventClosed
+}
+
+type NotificationEvent struct {
+	id             int64
+	jobID          valueobject.JobID
+	subscriptionID *valueobject.SubscriptionID
+	eventType      NotificationEventType
+	url            string
+	occurredAt     time.Time
+	createdAt      time.Time
+}
+
+func NewNotificationEvent(
+	id int64,
+	jobID valueobject.JobID,
+	subscriptionID *valueobject.SubscriptionID,
+	eventType NotificationEventType,
+	url string,
+	occurredAt time.Time,
+) (*NotificationEvent, error) {
+	if !eventType.IsValid() {
+		return nil, fmt.Errorf("invalid event type: %s", eventType)
+	}
+
+	now := time.Now()
+	if occurredAt.IsZero() || occurredAt.After(now) {
+		occurredAt = now
+	}
+
+	return &NotificationEvent{
+		id:             id,
+		jobID:          jobID,
+		subscriptionID: subscriptionID,
+		eventType:      eventType,
+		url:            url,
+		occurredAt:     occurredAt,
+		createdAt:      now,
+	}, nil
+}
+
+func ReconstructNotificationEvent(
+	id int64,
+	jobID valueobject.JobID,
+	subscriptionID *valueobject.SubscriptionID,
+	eventType NotificationEventType,
+	url string,
+	occurredAt, createdAt time.Time,
+) *NotificationEvent {
+	return &NotificationEvent{
+		id:             id,
+		jobID:          jobID,
+		subscriptionID: subscriptionID,
+		eventType:      eventType,
+		url:            url,
+		occurredAt:     occurredAt,
+		createdAt:      createdAt,
+	}
+}
+
+func (ne *NotificationEvent) ID() int64 {
+	return ne.id
+}
+
+func (ne *NotificationEvent) JobID() valueobject.JobID {
+	return ne.jobID
+}
+
+func (ne *NotificationEvent) SubscriptionID() *valueobject.SubscriptionID {
+	return ne.subscriptionID
+}
+
+func (ne *NotificationEvent) Type() NotificationEventType {
+	return ne.eventType
+}
+
+func (ne *NotificationEvent) URL() string {
+	return ne.url
+}
+
+func (ne *NotificationEvent) OccurredAt() time.Time {
+	return ne.occurredAt
+}
+
+func (ne *NotificationEvent) CreatedAt() time.Time {
+	return ne.createdAt
+}
+
+// EngagementCounts counts subscriptions per event type for one job, so a
+// notification clicked twice on the same device counts once.
+type EngagementCounts struct {
+	Delivered int
+	Displayed int
+	Clicked   int
+	Closed    int
+}
+
+// Rate returns count / total, or 0 when total is 0.
+func Rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// NotificationPrefs holds a user's opt-ins. Topics not listed are allowed;
+// users opt out by setting a topic to false.
+type NotificationPrefs struct {
+	userID     valueobject.UserID
+	enabled    bool
+	topics     map[string]bool
+	quietHours *valueobject.QuietHours
+	updatedAt  time.Time
+}
+
+// NewNotificationPrefs returns the defaults used for users who never saved
+// preferences: everything enabled, no quiet hours.
+func NewNotificationPrefs(userID valueobject.UserID) *NotificationPrefs {
+	return &NotificationPrefs{
+		userID:    userID,
+		enabled:   true,
+		topics:    map[string]bool{},
+		updatedAt: time.Now(),
+	}
+}
+
+func ReconstructNotificationPrefs(
+	userID valueobject.UserID,
+	enabled bool,
+	topics map[string]bool,
+	quietHours *valueobject.QuietHours,
+	updatedAt time.Time,
+) *NotificationPrefs {
+	if topics == nil {
+		topics = map[string]bool{}
+	}
+	return &NotificationPrefs{
+		userID:     userID,
+		enabled:    enabled,
+		topics:     topics,
+		quietHours: quietHours,
+		updatedAt:  updatedAt,
+	}
+}
+
+func (np *NotificationPrefs) UserID() valueobject.UserID {
+	return np.userID
+}
+
+func (np *NotificationPrefs) Enabled() bool {
+	return np.enabled
+}
+
+func (np *NotificationPrefs) Topics() map[string]bool {
+	return np.topics
+}
+
+func (np *NotificationPrefs) QuietHours() *valueobject.QuietHours {
+	return np.quietHours
+}
+
+func (np *NotificationPrefs) UpdatedAt() time.Time {
+	return np.updatedAt
+}
+
+func (np *NotificationPrefs) Update(enabled bool, topics map[string]bool, quietHours *valueobject.QuietHours) {
+	if topics == nil {
+		topics = map[string]bool{}
+	}
+	np.enabled = enabled
+	np.topics = topics
+	np.quietHours = quietHours
+	np.updatedAt = time.Now()
+}
+
+// AllowsTopic reports whether the user wants pushes for topic. Jobs without
+// a topic are only blocked when notifications are disabled altogether.
+func (np *NotificationPrefs) AllowsTopic(topic string) bool {
+	if !np.enabled {
+		return false
+	}
+	if allowed, ok := np.topics[topic]; ok && topic != "" {
+		return allowed
+	}
+	return true
+}
+
+// OptedInTo reports whether the user explicitly turned topic on, as opposed
+// to receiving it by default.
+func (np *NotificationPrefs) OptedInTo(topic string) bool {
+	return np.enabled && np.topics[topic]
+}
+
+// QuietUntil returns the end of the quiet window when now falls inside it.
+func (np *NotificationPrefs) QuietUntil(now time.Time) (time.Time, bool) {
+	if np.quietHours == nil {
+		return time.Time{}, false
+	}
+	return np.quietHours.WindowEnd(now)
+}
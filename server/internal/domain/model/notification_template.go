@@ -0,0 +1,191 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// templateVarPattern matches {{name}} placeholders; surrounding spaces are
+// allowed ({{ name }}).
+var templateVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+var templateKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,99}$`)
+
+// NotificationTemplate is a reusable notification whose title, body, url,
+// icon and string values in data may contain {{variable}} placeholders.
+type NotificationTemplate struct {
+	id        int64
+	key       string
+	title     string
+	body      string
+	url       string
+	icon      string
+	data      map[string]interface{}
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+func NewNotificationTemplate(
+	id int64,
+	key string,
+	title, body, url, icon string,
+	data map[string]interface{},
+) (*NotificationTemplate, error) {
+	if !templateKeyPattern.MatchString(key) {
+		return nil, fmt.Errorf("template key must be 1-100 lowercase letters, digits, '_', '-' or '.'")
+	}
+
+	template := &NotificationTemplate{
+		id:        id,
+		key:       key,
+		createdAt: time.Now(),
+	}
+	if err := template.Update(title, body, url, icon, data); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func ReconstructNotificationTemplate(
+	id int64,
+	key string,
+	title, body, url, icon string,
+	data map[string]interface{},
+	createdAt, updatedAt time.Time,
+) *NotificationTemplate {
+	return &NotificationTemplate{
+		id:        id,
+		key:       key,
+		title:     title,
+		body:      body,
+		url:       url,
+		icon:      icon,
+		data:      data,
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+	}
+}
+
+func (nt *NotificationTemplate) ID() int64 {
+	return nt.id
+}
+
+func (nt *NotificationTemplate) Key() string {
+	return nt.key
+}
+
+func (nt *NotificationTemplate) Title() string {
+	return nt.title
+}
+
+func (nt *NotificationTemplate) Body() string {
+	return nt.body
+}
+
+func (nt *NotificationTemplate) URL() string {
+	return nt.url
+}
+
+func (nt *NotificationTemplate) Icon() string {
+	return nt.icon
+}
+
+func (nt *NotificationTemplate) Data() map[string]interface{} {
+	return nt.data
+}
+
+func (nt *NotificationTemplate) CreatedAt() time.Time {
+	return nt.createdAt
+}
+
+func (nt *NotificationTemplate) UpdatedAt() time.Time {
+	return nt.updatedAt
+}
+
+func (nt *NotificationTemplate) Update(title, body, url, icon string, data map[string]interface{}) error {
+	if strings.TrimSpace(title) == "" {
+		return fmt.Errorf("template title cannot be empty")
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("template body cannot be empty")
+	}
+
+	nt.title = title
+	nt.body = body
+	nt.url = url
+	nt.icon = icon
+	nt.data = data
+	nt.updatedAt = time.Now()
+	return nil
+}
+
+// Render substitutes variables into the template and returns the payload
+// the Service Worker receives. Every placeholder must have a variable.
+func (nt *NotificationTemplate) Render(variables map[string]string) (PushPayload, error) {
+	r := &templateRenderer{variables: variables, missing: map[string]bool{}}
+
+	payload := PushPayload{
+		"title": r.render(nt.title),
+		"body":  r.render(nt.body),
+	}
+	if nt.url != "" {
+		payload["url"] = r.render(nt.url)
+	}
+	if nt.icon != "" {
+		payload["icon"] = r.render(nt.icon)
+	}
+	if len(nt.data) > 0 {
+		payload["data"] = r.renderValue(nt.data)
+	}
+
+	if len(r.missing) > 0 {
+		names := make([]string, 0, len(r.missing))
+		for name := range r.missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("missing template variables: %s", strings.Join(names, ", "))
+	}
+	return payload, nil
+}
+
+type templateRenderer struct {
+	variables map[string]string
+	missing   map[string]bool
+}
+
+func (r *templateRenderer) render(text string) string {
+	return templateVarPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templateVarPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := r.variables[name]
+		if !ok {
+			r.missing[name] = true
+			return placeholder
+		}
+		return value
+	})
+}
+
+func (r *templateRenderer) renderValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.render(v)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			rendered[key] = r.renderValue(item)
+		}
+		return rendered
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			rendered[i] = r.renderValue(item)
+		}
+		return rendered
+	default:
+		return v
+	}
+}
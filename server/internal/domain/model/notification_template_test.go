@@ -0,0 +1,81 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNotificationTemplateRender(t *testing.T) {
+	template, err := NewNotificationTemplate(1, "order.shipped",
+		"Hi {{name}}", "Order {{ order }} has shipped", "https://example.com/orders/{{order}}", "/icon.png",
+		map[string]interface{}{
+			"order": "{{order}}",
+			"tags":  []interface{}{"{{name}}", 2},
+		})
+	if err != nil {
+		t.Fatalf("NewNotificationTemplate: %v", err)
+	}
+
+	payload, err := template.Render(map[string]string{"name": "Alice", "order": "42"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	want := PushPayload{
+		"title": "Hi Alice",
+		"body":  "Order 42 has shipped",
+		"url":   "https://example.com/orders/42",
+		"icon":  "/icon.png",
+		"data": map[string]interface{}{
+			"order": "42",
+			"tags":  []interface{}{"Alice", 2},
+		},
+	}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("Render = %#v, want %#v", payload, want)
+	}
+}
+
+func TestNotificationTemplateRenderMissingVariables(t *testing.T) {
+	template, _ := NewNotificationTemplate(1, "greeting", "Hi {{name}}", "{{count}} new messages", "", "", nil)
+
+	_, err := template.Render(map[string]string{})
+	if err == nil || err.Error() != "missing template variables: count, name" {
+		t.Fatalf("Render error = %v", err)
+	}
+}
+
+func TestPushJobRenderPayloadKeepsExtraKeys(t *testing.T) {
+	template, _ := NewNotificationTemplate(1, "greeting", "Hi {{name}}", "Welcome", "", "", nil)
+	job := &PushJob{payload: PushPayload{"title": "ignored", "tag": "welcome"}}
+	job.UseTemplate("greeting", map[string]string{"name": "Bob"})
+
+	payload, err := job.RenderPayload(template)
+	if err != nil {
+		t.Fatalf("RenderPayload: %v", err)
+	}
+	if payload["title"] != "Hi Bob" || payload["tag"] != "welcome" {
+		t.Errorf("RenderPayload = %#v", payload)
+	}
+}
+
+func TestNewNotificationTemplateValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		title string
+		body  string
+	}{
+		{"empty key", "", "t", "b"},
+		{"uppercase key", "Welcome", "t", "b"},
+		{"empty title", "welcome", " ", "b"},
+		{"empty body", "welcome", "t", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewNotificationTemplate(1, tt.key, tt.title, tt.body, "", "", nil); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
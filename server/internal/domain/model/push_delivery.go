@@ -0,0 +1,190 @@
+package model
+
+import (
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type DeliveryStatus string
+
+const (
+	// DeliveryStatusPending deliveries are waiting for their first attempt or
+	// for nextAttemptAt after a retryable failure.
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	// DeliveryStatusFailed deliveries exhausted their attempts.
+	DeliveryStatusFailed DeliveryStatus = "failed"
+	// DeliveryStatusGone deliveries target a subscription the push service
+	// reported as expired (404/410) or that was invalidated meanwhile.
+	DeliveryStatusGone DeliveryStatus = "gone"
+	// DeliveryStatusSkipped deliveries were dropped because the recipient
+	// opted out of the job's topic.
+	DeliveryStatusSkipped DeliveryStatus = "skipped"
+)
+
+// PushDelivery tracks one job's delivery to one subscription so retries only
+// target recipients that have not received the push yet.
+type PushDelivery struct {
+	id             int64
+	jobID          valueobject.JobID
+	subscriptionID valueobject.SubscriptionID
+	status         DeliveryStatus
+	attemptCount   int
+	nextAttemptAt  *time.Time
+	lastStatus     *int
+	lastError      string
+	createdAt      time.Time
+	updatedAt      time.Time
+}
+
+func NewPushDelivery(id int64, jobID valueobject.JobID, subscriptionID valueobject.SubscriptionID) *PushDelivery {
+	now := time.Now()
+	return &PushDelivery{
+		id:             id,
+		jobID:          jobID,
+		subscriptionID: subscriptionID,
+		status:         DeliveryStatusPending,
+		createdAt:      now,
+		updatedAt:      now,
+	}
+}
+
+func ReconstructPushDelivery(
+	id int64,
+	jobID valueobject.JobID,
+	subscriptionID valueobject.SubscriptionID,
+	status DeliveryStatus,
+	attemptCount int,
+	nextAttemptAt *time.Time,
+	lastStatus *int,
+	lastError string,
+	createdAt, updatedAt time.Time,
+) *PushDelivery {
+	return &PushDelivery{
+		id:             id,
+		jobID:          jobID,
+		subscriptionID: subscriptionID,
+		status:         status,
+		attemptCount:   attemptCount,
+		nextAttemptAt:  nextAttemptAt,
+		lastStatus:     lastStatus,
+		lastError:      lastError,
+		createdAt:      createdAt,
+		updatedAt:      updatedAt,
+	}
+}
+
+func (pd *PushDelivery) ID() int64 {
+	return pd.id
+}
+
+func (pd *PushDelivery) JobID() valueobject.JobID {
+	return pd.jobID
+}
+
+func (pd *PushDelivery) SubscriptionID() valueobject.SubscriptionID {
+	return pd.subscriptionID
+}
+
+func (pd *PushDelivery) Status() DeliveryStatus {
+	return pd.status
+}
+
+func (pd *PushDelivery) AttemptCount() int {
+	return pd.attemptCount
+}
+
+func (pd *PushDelivery) NextAttemptAt() *time.Time {
+	return pd.nextAttemptAt
+}
+
+func (pd *PushDelivery) LastStatus() *int {
+	return pd.lastStatus
+}
+
+func (pd *PushDelivery) LastError() string {
+	return pd.lastError
+}
+
+func (pd *PushDelivery) CreatedAt() time.Time {
+	return pd.createdAt
+}
+
+func (pd *PushDelivery) UpdatedAt() time.Time {
+	return pd.updatedAt
+}
+
+func (pd *PushDelivery) IsDue(now time.Time) bool {
+	if pd.status != DeliveryStatusPending {
+		return false
+	}
+	return pd.nextAttemptAt == nil || !now.Before(*pd.nextAttemptAt)
+}
+
+func (pd *PushDelivery) MarkAsSucceeded(statusCode int) {
+	pd.attemptCount++
+	pd.status = DeliveryStatusSucceeded
+	pd.lastStatus = &statusCode
+	pd.lastError = ""
+	pd.nextAttemptAt = nil
+	pd.updatedAt = time.Now()
+}
+
+func (pd *PushDelivery) MarkAsGone(statusCode *int, reason string) {
+	if statusCode != nil {
+		pd.attemptCount++
+	}
+	pd.status = DeliveryStatusGone
+	pd.lastStatus = statusCode
+	pd.lastError = reason
+	pd.nextAttemptAt = nil
+	pd.updatedAt = time.Now()
+}
+
+func (pd *PushDelivery) MarkAsSkipped(reason string) {
+	pd.status = DeliveryStatusSkipped
+	pd.lastError = reason
+	pd.nextAttemptAt = nil
+	pd.updatedAt = time.Now()
+}
+
+// MarkAttemptFailed records a failed attempt. The delivery stays pending
+// until nextAttemptAt unless maxAttempts has been reached, in which case it
+// becomes failed for good.
+func (pd *PushDelivery) MarkAttemptFailed(statusCode *int, reason string, nextAttemptAt time.Time, maxAttempts int) {
+	pd.attemptCount++
+	pd.lastStatus = statusCode
+	pd.lastError = reason
+	if pd.attemptCount >= maxAttempts {
+		pd.status = DeliveryStatusFailed
+		pd.nextAttemptAt = nil
+	} else {
+		pd.status = DeliveryStatusPending
+		pd.nextAttemptAt = &nextAttemptAt
+	}
+	pd.updatedAt = time.Now()
+}
+
+// Defer postpones a pending delivery without consuming an attempt, e.g. when
+// its push service host is throttling us.
+func (pd *PushDelivery) Defer(until time.Time, reason string) {
+	if pd.status != DeliveryStatusPending {
+		return
+	}
+	pd.lastError = reason
+	pd.nextAttemptAt = &until
+	pd.updatedAt = time.Now()
+}
+
+type DeliveryCounts struct {
+	Pending   int
+	Succeeded int
+	Failed    int
+	Gone      int
+	Skipped   int
+}
+
+func (c DeliveryCounts) Total() int {
+	return c.Pending + c.Succeeded + c.Failed + c.Gone + c.Skipped
+}
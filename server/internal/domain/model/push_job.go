@@ -18,6 +18,15 @@ const (
 	JobStatusCancelled JobStatus = "cancelled"
 )
 
+func (s JobStatus) IsValid() bool {
+	switch s {
+	case JobStatusPending, JobStatusSending, JobStatusSucceeded, JobStatusFailed, JobStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
 type Urgency string
 
 const (
@@ -42,6 +51,20 @@ func (p PushPayload) ToJSON() ([]byte, error) {
 	return json.Marshal(p)
 }
 
+// WithTracking returns a copy of the payload carrying the IDs the Service
+// Worker sends back with engagement events (see NotificationEvent).
+func (p PushPayload) WithTracking(jobID valueobject.JobID, subscriptionID valueobject.PublicSubscriptionID) PushPayload {
+	tracked := make(PushPayload, len(p)+1)
+	for key, value := range p {
+		tracked[key] = value
+	}
+	tracked["tracking"] = map[string]string{
+		"jobId":          jobID.String(),
+		"subscriptionId": subscriptionID.String(),
+	}
+	return tracked
+}
+
 type PushJob struct {
 	id             valueobject.JobID
 	idempotencyKey string
@@ -50,10 +73,17 @@ type PushJob struct {
 	urgency        Urgency
 	ttlSeconds     int
 	payload        PushPayload
+	templateKey    string
+	templateVars   map[string]string
+	segment        *Segment
+	recipientCount int
 	scheduleAt     *time.Time
 	status         JobStatus
 	retryCount     int
 	lastError      string
+	nextAttemptAt  *time.Time
+	leaseOwner     string
+	leaseExpiresAt *time.Time
 	createdAt      time.Time
 	updatedAt      time.Time
 }
@@ -102,10 +132,17 @@ func ReconstructPushJob(
 	urgency Urgency,
 	ttlSeconds int,
 	payload PushPayload,
+	templateKey string,
+	templateVars map[string]string,
+	segment *Segment,
+	recipientCount int,
 	scheduleAt *time.Time,
 	status JobStatus,
 	retryCount int,
 	lastError string,
+	nextAttemptAt *time.Time,
+	leaseOwner string,
+	leaseExpiresAt *time.Time,
 	createdAt, updatedAt time.Time,
 ) *PushJob {
 	return &PushJob{
@@ -116,10 +153,17 @@ func ReconstructPushJob(
 		urgency:        urgency,
 		ttlSeconds:     ttlSeconds,
 		payload:        payload,
+		templateKey:    templateKey,
+		templateVars:   templateVars,
+		segment:        segment,
+		recipientCount: recipientCount,
 		scheduleAt:     scheduleAt,
 		status:         status,
 		retryCount:     retryCount,
 		lastError:      lastError,
+		nextAttemptAt:  nextAttemptAt,
+		leaseOwner:     leaseOwner,
+		leaseExpiresAt: leaseExpiresAt,
 		createdAt:      createdAt,
 		updatedAt:      updatedAt,
 	}
@@ -153,6 +197,84 @@ func (pj *PushJob) Payload() PushPayload {
 	return pj.payload
 }
 
+// TemplateKey is the notification template rendered into the payload at
+// send time, or empty for jobs with a raw payload.
+func (pj *PushJob) TemplateKey() string {
+	return pj.templateKey
+}
+
+func (pj *PushJob) TemplateVars() map[string]string {
+	return pj.templateVars
+}
+
+// UseTemplate makes the job render the template with vars when it is sent.
+// The job's own payload keys are kept unless the template sets them.
+func (pj *PushJob) UseTemplate(key string, vars map[string]string) {
+	pj.templateKey = key
+	pj.templateVars = vars
+	pj.updatedAt = time.Now()
+}
+
+// RenderPayload returns the payload to send: the rendered template, plus
+// any payload keys the template does not set.
+func (pj *PushJob) RenderPayload(template *NotificationTemplate) (PushPayload, error) {
+	rendered, err := template.Render(pj.templateVars)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range pj.payload {
+		if _, exists := rendered[key]; !exists {
+			rendered[key] = value
+		}
+	}
+	return rendered, nil
+}
+
+// Segment narrows a job without a user down to the matching subscriptions.
+// It is evaluated when the job is sent, not when it is created.
+func (pj *PushJob) Segment() *Segment {
+	return pj.segment
+}
+
+// TargetSegment restricts the job to the subscriptions matching segment.
+// Jobs for a single user cannot be segmented.
+func (pj *PushJob) TargetSegment(segment *Segment) error {
+	if pj.userID != nil {
+		return fmt.Errorf("a job for a single user cannot target a segment")
+	}
+	if pj.recipientCount > 0 {
+		return fmt.Errorf("a batch job cannot target a segment")
+	}
+	pj.segment = segment
+	pj.updatedAt = time.Now()
+	return nil
+}
+
+// RecipientCount is the number of users a batch job was created for; the
+// recipients themselves are stored alongside the job. It is zero for jobs
+// that are not batches.
+func (pj *PushJob) RecipientCount() int {
+	return pj.recipientCount
+}
+
+func (pj *PushJob) IsBatch() bool {
+	return pj.recipientCount > 0
+}
+
+// TargetRecipients makes the job a batch sent to count users, whose
+// subscriptions are expanded into deliveries when the job is sent.
+func (pj *PushJob) TargetRecipients(count int) error {
+	if pj.userID != nil || pj.segment != nil {
+		return fmt.Errorf("a batch job cannot also target a user or a segment")
+	}
+	if count <= 0 {
+		return fmt.Errorf("a batch job needs at least one recipient")
+	}
+	pj.recipientCount = count
+	pj.updatedAt = time.Now()
+	return nil
+}
+
 func (pj *PushJob) ScheduleAt() *time.Time {
 	return pj.scheduleAt
 }
@@ -169,6 +291,18 @@ func (pj *PushJob) LastError() string {
 	return pj.lastError
 }
 
+func (pj *PushJob) NextAttemptAt() *time.Time {
+	return pj.nextAttemptAt
+}
+
+func (pj *PushJob) LeaseOwner() string {
+	return pj.leaseOwner
+}
+
+func (pj *PushJob) LeaseExpiresAt() *time.Time {
+	return pj.leaseExpiresAt
+}
+
 func (pj *PushJob) CreatedAt() time.Time {
 	return pj.createdAt
 }
@@ -182,9 +316,25 @@ func (pj *PushJob) MarkAsSending() {
 	pj.updatedAt = time.Now()
 }
 
+// Claim moves the job to sending on behalf of owner. The lease must be
+// renewed before leaseExpiresAt or another sender may reclaim the job.
+func (pj *PushJob) Claim(owner string, leaseExpiresAt time.Time) {
+	pj.status = JobStatusSending
+	pj.leaseOwner = owner
+	pj.leaseExpiresAt = &leaseExpiresAt
+	pj.updatedAt = time.Now()
+}
+
+func (pj *PushJob) ExtendLease(leaseExpiresAt time.Time) {
+	pj.leaseExpiresAt = &leaseExpiresAt
+	pj.updatedAt = time.Now()
+}
+
 func (pj *PushJob) MarkAsSucceeded() {
 	pj.status = JobStatusSucceeded
 	pj.lastError = ""
+	pj.nextAttemptAt = nil
+	pj.clearLease()
 	pj.updatedAt = time.Now()
 }
 
@@ -192,26 +342,125 @@ func (pj *PushJob) MarkAsFailed(error string) {
 	pj.status = JobStatusFailed
 	pj.lastError = error
 	pj.retryCount++
+	pj.nextAttemptAt = nil
+	pj.clearLease()
+	pj.updatedAt = time.Now()
+}
+
+// ScheduleRetry puts the job back to pending until nextAttemptAt, when the
+// sender picks it up again to retry the deliveries that are still pending.
+func (pj *PushJob) ScheduleRetry(nextAttemptAt time.Time, reason string) {
+	pj.status = JobStatusPending
+	pj.lastError = reason
+	pj.retryCount++
+	pj.nextAttemptAt = &nextAttemptAt
+	pj.clearLease()
+	pj.updatedAt = time.Now()
+}
+
+// AwaitDeliveries puts the job back to pending until nextAttemptAt, when
+// its next round sends the deliveries that are still pending. Unlike
+// ScheduleRetry the round went fine, so no retry is counted and the last
+// error is cleared.
+func (pj *PushJob) AwaitDeliveries(nextAttemptAt time.Time) {
+	pj.status = JobStatusPending
+	pj.lastError = ""
+	pj.nextAttemptAt = &nextAttemptAt
+	pj.clearLease()
+	pj.updatedAt = time.Now()
+}
+
+// AdvanceNextAttempt moves a pending job's next attempt forward to at without
+// counting a retry, e.g. when a queued delivery needs retrying before the
+// job's next round. It reports false when the job is not pending or is due
+// by then anyway.
+func (pj *PushJob) AdvanceNextAttempt(at time.Time) bool {
+	if pj.status != JobStatusPending || pj.nextAttemptAt == nil || !at.Before(*pj.nextAttemptAt) {
+		return false
+	}
+	pj.nextAttemptAt = &at
+	pj.updatedAt = time.Now()
+	return true
+}
+
+// Release hands a claimed job back to pending without counting a retry,
+// e.g. when its sender shuts down before finishing it.
+func (pj *PushJob) Release() {
+	pj.status = JobStatusPending
+	pj.clearLease()
 	pj.updatedAt = time.Now()
 }
 
 func (pj *PushJob) MarkAsCancelled() {
 	pj.status = JobStatusCancelled
+	pj.clearLease()
 	pj.updatedAt = time.Now()
 }
 
+// IsCancellable reports whether the job has not been picked up yet. Jobs
+// being sent or already finished cannot be cancelled.
+func (pj *PushJob) IsCancellable() bool {
+	return pj.status == JobStatusPending
+}
+
+func (pj *PushJob) clearLease() {
+	pj.leaseOwner = ""
+	pj.leaseExpiresAt = nil
+}
+
 func (pj *PushJob) IsReadyToSend() bool {
 	if pj.status != JobStatusPending {
 		return false
 	}
 
-	if pj.scheduleAt != nil && time.Now().Before(*pj.scheduleAt) {
+	now := time.Now()
+	if pj.scheduleAt != nil && now.Before(*pj.scheduleAt) {
+		return false
+	}
+
+	if pj.nextAttemptAt != nil && now.Before(*pj.nextAttemptAt) {
 		return false
 	}
 
 	return true
 }
 
+// IsLeaseExpired reports whether the job is stuck in sending because its
+// sender stopped renewing the lease (e.g. the process crashed).
+func (pj *PushJob) IsLeaseExpired() bool {
+	if pj.status != JobStatusSending {
+		return false
+	}
+	return pj.leaseExpiresAt == nil || time.Now().After(*pj.leaseExpiresAt)
+}
+
+func (pj *PushJob) IsClaimable() bool {
+	return pj.IsReadyToSend() || pj.IsLeaseExpired()
+}
+
+// ClaimableAt returns when a pending or sending job becomes claimable: once
+// it is due, or once its lease expires. A zero time means it already is.
+// Finished and cancelled jobs report false.
+func (pj *PushJob) ClaimableAt() (time.Time, bool) {
+	var at time.Time
+	switch pj.status {
+	case JobStatusPending:
+		if pj.scheduleAt != nil {
+			at = *pj.scheduleAt
+		}
+		if pj.nextAttemptAt != nil && pj.nextAttemptAt.After(at) {
+			at = *pj.nextAttemptAt
+		}
+	case JobStatusSending:
+		if pj.leaseExpiresAt != nil {
+			at = *pj.leaseExpiresAt
+		}
+	default:
+		return time.Time{}, false
+	}
+	return at, true
+}
+
 func (pj *PushJob) ShouldRetry(maxRetries int) bool {
 	return pj.status == JobStatusFailed && pj.retryCount < maxRetries
 }
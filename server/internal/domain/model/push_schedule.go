@@ -0,0 +1,291 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type ScheduleStatus string
+
+const (
+	ScheduleStatusActive ScheduleStatus = "active"
+	ScheduleStatusPaused ScheduleStatus = "paused"
+)
+
+func (s ScheduleStatus) IsValid() bool {
+	return s == ScheduleStatusActive || s == ScheduleStatusPaused
+}
+
+// PushSchedule spawns a PushJob on every occurrence of its cron schedule.
+// The job it spawns is described the same way as a one-off send: a user or
+// a segment, and a payload or a template.
+type PushSchedule struct {
+	id           valueobject.ScheduleID
+	name         string
+	cron         valueobject.CronSchedule
+	userID       *valueobject.UserID
+	topic        string
+	urgency      Urgency
+	ttlSeconds   int
+	payload      PushPayload
+	templateKey  string
+	templateVars map[string]string
+	segment      *Segment
+	status       ScheduleStatus
+	nextRunAt    *time.Time
+	lastRunAt    *time.Time
+	lastJobID    *valueobject.JobID
+	createdAt    time.Time
+	updatedAt    time.Time
+}
+
+func NewPushSchedule(
+	id valueobject.ScheduleID,
+	name string,
+	cron valueobject.CronSchedule,
+	userID *valueobject.UserID,
+	topic string,
+	urgency Urgency,
+	ttlSeconds int,
+	payload PushPayload,
+) (*PushSchedule, error) {
+	if name == "" {
+		return nil, fmt.Errorf("schedule name cannot be empty")
+	}
+	if cron.IsZero() {
+		return nil, fmt.Errorf("cron schedule is required")
+	}
+	if !urgency.IsValid() {
+		return nil, fmt.Errorf("invalid urgency: %s", urgency)
+	}
+	if ttlSeconds < 0 {
+		return nil, fmt.Errorf("TTL seconds must be non-negative")
+	}
+
+	now := time.Now()
+	next := cron.Next(now)
+	if next.IsZero() {
+		return nil, fmt.Errorf("cron expression %q never fires", cron.Expression())
+	}
+
+	return &PushSchedule{
+		id:         id,
+		name:       name,
+		cron:       cron,
+		userID:     userID,
+		topic:      topic,
+		urgency:    urgency,
+		ttlSeconds: ttlSeconds,
+		payload:    payload,
+		status:     ScheduleStatusActive,
+		nextRunAt:  &next,
+		createdAt:  now,
+		updatedAt:  now,
+	}, nil
+}
+
+func ReconstructPushSchedule(
+	id valueobject.ScheduleID,
+	name string,
+	cron valueobject.CronSchedule,
+	userID *valueobject.UserID,
+	topic string,
+	urgency Urgency,
+	ttlSeconds int,
+	payload PushPayload,
+	templateKey string,
+	templateVars map[string]string,
+	segment *Segment,
+	status ScheduleStatus,
+	nextRunAt *time.Time,
+	lastRunAt *time.Time,
+	lastJobID *valueobject.JobID,
+	createdAt, updatedAt time.Time,
+) *PushSchedule {
+	return &PushSchedule{
+		id:           id,
+		name:         name,
+		cron:         cron,
+		userID:       userID,
+		topic:        topic,
+		urgency:      urgency,
+		ttlSeconds:   ttlSeconds,
+		payload:      payload,
+		templateKey:  templateKey,
+		templateVars: templateVars,
+		segment:      segment,
+		status:       status,
+		nextRunAt:    nextRunAt,
+		lastRunAt:    lastRunAt,
+		lastJobID:    lastJobID,
+		createdAt:    createdAt,
+		updatedAt:    updatedAt,
+	}
+}
+
+func (ps *PushSchedule) ID() valueobject.ScheduleID {
+	return ps.id
+}
+
+func (ps *PushSchedule) Name() string {
+	return ps.name
+}
+
+func (ps *PushSchedule) Cron() valueobject.CronSchedule {
+	return ps.cron
+}
+
+func (ps *PushSchedule) UserID() *valueobject.UserID {
+	return ps.userID
+}
+
+func (ps *PushSchedule) Topic() string {
+	return ps.topic
+}
+
+func (ps *PushSchedule) Urgency() Urgency {
+	return ps.urgency
+}
+
+func (ps *PushSchedule) TTLSeconds() int {
+	return ps.ttlSeconds
+}
+
+func (ps *PushSchedule) Payload() PushPayload {
+	return ps.payload
+}
+
+func (ps *PushSchedule) TemplateKey() string {
+	return ps.templateKey
+}
+
+func (ps *PushSchedule) TemplateVars() map[string]string {
+	return ps.templateVars
+}
+
+// UseTemplate makes every spawned job render the template; see
+// PushJob.UseTemplate.
+func (ps *PushSchedule) UseTemplate(key string, vars map[string]string) {
+	ps.templateKey = key
+	ps.templateVars = vars
+	ps.updatedAt = time.Now()
+}
+
+func (ps *PushSchedule) Segment() *Segment {
+	return ps.segment
+}
+
+// TargetSegment narrows every spawned job to the segment. As with jobs,
+// a schedule for a single user cannot be segmented.
+func (ps *PushSchedule) TargetSegment(segment *Segment) error {
+	if ps.userID != nil {
+		return fmt.Errorf("a schedule for a single user cannot target a segment")
+	}
+	ps.segment = segment
+	ps.updatedAt = time.Now()
+	return nil
+}
+
+func (ps *PushSchedule) Status() ScheduleStatus {
+	return ps.status
+}
+
+// NextRunAt is the occurrence the schedule spawns its next job for, or nil
+// while the schedule is paused.
+func (ps *PushSchedule) NextRunAt() *time.Time {
+	return ps.nextRunAt
+}
+
+// LastRunAt is the most recent occurrence that was handled, whether or not
+// it spawned a job.
+func (ps *PushSchedule) LastRunAt() *time.Time {
+	return ps.lastRunAt
+}
+
+// LastJobID is the job spawned for the most recent occurrence that was not
+// skipped.
+func (ps *PushSchedule) LastJobID() *valueobject.JobID {
+	return ps.lastJobID
+}
+
+func (ps *PushSchedule) CreatedAt() time.Time {
+	return ps.createdAt
+}
+
+func (ps *PushSchedule) UpdatedAt() time.Time {
+	return ps.updatedAt
+}
+
+// Pause stops the schedule from spawning jobs until it is resumed.
+func (ps *PushSchedule) Pause() {
+	ps.status = ScheduleStatusPaused
+	ps.nextRunAt = nil
+	ps.updatedAt = time.Now()
+}
+
+// Resume reactivates a paused schedule from the next occurrence after now;
+// occurrences missed while paused are not caught up.
+func (ps *PushSchedule) Resume(now time.Time) error {
+	if ps.status == ScheduleStatusActive {
+		return nil
+	}
+	next := ps.cron.Next(now)
+	if next.IsZero() {
+		return fmt.Errorf("cron expression %q never fires again", ps.cron.Expression())
+	}
+	ps.status = ScheduleStatusActive
+	ps.nextRunAt = &next
+	ps.updatedAt = time.Now()
+	return nil
+}
+
+func (ps *PushSchedule) IsDue(now time.Time) bool {
+	return ps.status == ScheduleStatusActive && ps.nextRunAt != nil && !now.Before(*ps.nextRunAt)
+}
+
+// OccurrenceKey is the idempotency key of the job spawned for the current
+// occurrence, so an occurrence can never produce two jobs.
+func (ps *PushSchedule) OccurrenceKey() string {
+	if ps.nextRunAt == nil {
+		return ""
+	}
+	return fmt.Sprintf("schedule:%s:%d", ps.id.String(), ps.nextRunAt.Unix())
+}
+
+// NewJob builds the job for the current occurrence. It is ready to send
+// immediately.
+func (ps *PushSchedule) NewJob(id valueobject.JobID) (*PushJob, error) {
+	job, err := NewPushJob(id, ps.OccurrenceKey(), ps.userID, ps.topic, ps.urgency, ps.ttlSeconds, ps.payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ps.templateKey != "" {
+		job.UseTemplate(ps.templateKey, ps.templateVars)
+	}
+	if ps.segment != nil {
+		if err := job.TargetSegment(ps.segment); err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+// Advance records that the current occurrence was handled, by spawning
+// jobID or by skipping it when jobID is nil, and moves on to the first
+// occurrence after now. Occurrences that passed in the meantime are not
+// caught up.
+func (ps *PushSchedule) Advance(now time.Time, jobID *valueobject.JobID) {
+	ps.lastRunAt = ps.nextRunAt
+	if jobID != nil {
+		ps.lastJobID = jobID
+	}
+	next := ps.cron.Next(now)
+	if next.IsZero() {
+		ps.nextRunAt = nil
+	} else {
+		ps.nextRunAt = &next
+	}
+	ps.updatedAt = time.Now()
+}
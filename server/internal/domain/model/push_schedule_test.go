@@ -0,0 +1,105 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+func newTestSchedule(t *testing.T, expression string) *PushSchedule {
+	t.Helper()
+	cron, err := valueobject.NewCronSchedule(expression, "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("NewCronSchedule: %v", err)
+	}
+	id, _ := valueobject.NewScheduleID(7)
+	schedule, err := NewPushSchedule(id, "digest", cron, nil, "digest", UrgencyNormal, 3600, PushPayload{"title": "Daily digest"})
+	if err != nil {
+		t.Fatalf("NewPushSchedule: %v", err)
+	}
+	return schedule
+}
+
+func TestPushScheduleAdvance(t *testing.T) {
+	schedule := newTestSchedule(t, "0 9 * * *")
+	first := *schedule.NextRunAt()
+	if schedule.IsDue(first.Add(-time.Second)) || !schedule.IsDue(first) {
+		t.Fatalf("IsDue around %v is wrong", first)
+	}
+
+	jobID, _ := valueobject.NewJobID(1)
+	job, err := schedule.NewJob(jobID)
+	if err != nil {
+		t.Fatalf("NewJob: %v", err)
+	}
+	if job.IdempotencyKey() != schedule.OccurrenceKey() || job.ScheduleAt() != nil || job.Topic() != "digest" {
+		t.Errorf("job = key %q, scheduleAt %v, topic %q", job.IdempotencyKey(), job.ScheduleAt(), job.Topic())
+	}
+
+	// A replica that was down for three days resumes from the next
+	// occurrence instead of catching up on the missed ones.
+	late := first.AddDate(0, 0, 3).Add(time.Hour)
+	schedule.Advance(late, &jobID)
+	if !schedule.LastRunAt().Equal(first) || schedule.LastJobID() == nil || !schedule.LastJobID().Equals(jobID) {
+		t.Errorf("last run = %v, job %v", schedule.LastRunAt(), schedule.LastJobID())
+	}
+	if want := first.AddDate(0, 0, 4); !schedule.NextRunAt().Equal(want) {
+		t.Errorf("NextRunAt = %v, want %v", schedule.NextRunAt(), want)
+	}
+	if schedule.OccurrenceKey() == job.IdempotencyKey() {
+		t.Error("occurrence key did not change")
+	}
+
+	schedule.Advance(*schedule.NextRunAt(), nil)
+	if !schedule.LastJobID().Equals(jobID) {
+		t.Error("a skipped occurrence replaced the last job")
+	}
+}
+
+func TestPushSchedulePauseResume(t *testing.T) {
+	schedule := newTestSchedule(t, "*/5 * * * *")
+	schedule.Pause()
+	if schedule.Status() != ScheduleStatusPaused || schedule.NextRunAt() != nil || schedule.IsDue(time.Now().AddDate(1, 0, 0)) {
+		t.Fatalf("paused schedule: status %s, next %v", schedule.Status(), schedule.NextRunAt())
+	}
+
+	now := time.Now()
+	if err := schedule.Resume(now); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	next := schedule.NextRunAt()
+	if schedule.Status() != ScheduleStatusActive || next == nil || !next.After(now) || next.Sub(now) > 5*time.Minute {
+		t.Fatalf("resumed schedule: status %s, next %v", schedule.Status(), next)
+	}
+
+	if err := schedule.Resume(now.Add(time.Hour)); err != nil || schedule.NextRunAt() != next {
+		t.Error("resuming an active schedule moved its next run")
+	}
+}
+
+func TestPushScheduleTargetSegment(t *testing.T) {
+	schedule := newTestSchedule(t, "@weekly")
+	segment, _ := NewSegment([]string{"news"}, nil, nil, nil, 0, 0)
+	if err := schedule.TargetSegment(segment); err != nil {
+		t.Fatalf("TargetSegment: %v", err)
+	}
+	schedule.UseTemplate("weekly", map[string]string{"edition": "1"})
+
+	jobID, _ := valueobject.NewJobID(2)
+	job, err := schedule.NewJob(jobID)
+	if err != nil {
+		t.Fatalf("NewJob: %v", err)
+	}
+	if job.Segment() != segment || job.TemplateKey() != "weekly" {
+		t.Errorf("job segment %v, template %q", job.Segment(), job.TemplateKey())
+	}
+
+	userID, _ := valueobject.NewUserID(1)
+	cron, _ := valueobject.NewCronSchedule("@daily", "")
+	id, _ := valueobject.NewScheduleID(8)
+	personal, _ := NewPushSchedule(id, "reminder", cron, &userID, "", UrgencyNormal, 60, PushPayload{"title": "hi"})
+	if err := personal.TargetSegment(segment); err == nil {
+		t.Error("TargetSegment on a user schedule succeeded")
+	}
+}
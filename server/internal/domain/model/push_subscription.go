@@ -1,6 +1,7 @@
 package model
 
 import (
+	"crypto/subtle"
 	"time"
 
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
@@ -8,12 +9,15 @@ import (
 
 type PushSubscription struct {
 	id             valueobject.SubscriptionID
+	publicID       valueobject.PublicSubscriptionID
 	userID         *valueobject.UserID
 	endpoint       valueobject.PushEndpoint
 	keys           valueobject.PushKeys
 	userAgent      string
 	expirationTime *time.Time
+	vapidKeyID     *int64
 	isValid        bool
+	lastActiveAt   time.Time
 	createdAt      time.Time
 	updatedAt      time.Time
 }
@@ -29,12 +33,14 @@ func NewPushSubscription(
 	now := time.Now()
 	return &PushSubscription{
 		id:             id,
+		publicID:       valueobject.NewPublicSubscriptionID(),
 		userID:         userID,
 		endpoint:       endpoint,
 		keys:           keys,
 		userAgent:      userAgent,
 		expirationTime: expirationTime,
 		isValid:        true,
+		lastActiveAt:   now,
 		createdAt:      now,
 		updatedAt:      now,
 	}
@@ -42,22 +48,28 @@ func NewPushSubscription(
 
 func ReconstructPushSubscription(
 	id valueobject.SubscriptionID,
+	publicID valueobject.PublicSubscriptionID,
 	userID *valueobject.UserID,
 	endpoint valueobject.PushEndpoint,
 	keys valueobject.PushKeys,
 	userAgent string,
 	expirationTime *time.Time,
+	vapidKeyID *int64,
 	isValid bool,
+	lastActiveAt time.Time,
 	createdAt, updatedAt time.Time,
 ) *PushSubscription {
 	return &PushSubscription{
 		id:             id,
+		publicID:       publicID,
 		userID:         userID,
 		endpoint:       endpoint,
 		keys:           keys,
 		userAgent:      userAgent,
 		expirationTime: expirationTime,
+		vapidKeyID:     vapidKeyID,
 		isValid:        isValid,
+		lastActiveAt:   lastActiveAt,
 		createdAt:      createdAt,
 		updatedAt:      updatedAt,
 	}
@@ -67,6 +79,20 @@ func (ps *PushSubscription) ID() valueobject.SubscriptionID {
 	return ps.id
 }
 
+// PublicID is the only identifier exposed outside the server.
+func (ps *PushSubscription) PublicID() valueobject.PublicSubscriptionID {
+	return ps.publicID
+}
+
+// ProvesOwnership reports whether the caller knows the subscription's
+// endpoint URL or auth secret, which only the subscribed browser has.
+func (ps *PushSubscription) ProvesOwnership(endpoint, authSecret string) bool {
+	if endpoint != "" && subtle.ConstantTimeCompare([]byte(endpoint), []byte(ps.endpoint.Value())) == 1 {
+		return true
+	}
+	return authSecret != "" && subtle.ConstantTimeCompare([]byte(authSecret), []byte(ps.keys.Auth().Value())) == 1
+}
+
 func (ps *PushSubscription) UserID() *valueobject.UserID {
 	return ps.userID
 }
@@ -87,10 +113,27 @@ func (ps *PushSubscription) ExpirationTime() *time.Time {
 	return ps.expirationTime
 }
 
+// VAPIDKeyID is the application server key the browser subscribed with, or
+// nil for subscriptions created before keys were tracked.
+func (ps *PushSubscription) VAPIDKeyID() *int64 {
+	return ps.vapidKeyID
+}
+
 func (ps *PushSubscription) IsValid() bool {
 	return ps.isValid
 }
 
+// LastActiveAt is when the browser last re-subscribed or the user clicked
+// or closed one of its notifications.
+func (ps *PushSubscription) LastActiveAt() time.Time {
+	return ps.lastActiveAt
+}
+
+// Browser is the browser family the subscription was created from.
+func (ps *PushSubscription) Browser() valueobject.Browser {
+	return valueobject.BrowserFromUserAgent(ps.userAgent)
+}
+
 func (ps *PushSubscription) CreatedAt() time.Time {
 	return ps.createdAt
 }
@@ -104,6 +147,37 @@ func (ps *PushSubscription) MarkAsInvalid() {
 	ps.updatedAt = time.Now()
 }
 
+// Revalidate re-enables a subscription the browser has registered again.
+func (ps *PushSubscription) Revalidate() {
+	now := time.Now()
+	ps.isValid = true
+	ps.lastActiveAt = now
+	ps.updatedAt = now
+}
+
+// MarkActive records activity from the browser at at. Older reports are
+// ignored so events arriving out of order cannot move it back.
+func (ps *PushSubscription) MarkActive(at time.Time) bool {
+	if !at.After(ps.lastActiveAt) {
+		return false
+	}
+	ps.lastActiveAt = at
+	ps.updatedAt = time.Now()
+	return true
+}
+
+// BindToUser makes userID the owner of the subscription. The last signed-in
+// user of a browser wins.
+func (ps *PushSubscription) BindToUser(userID valueobject.UserID) {
+	ps.userID = &userID
+	ps.updatedAt = time.Now()
+}
+
+// IsOwnedBy reports whether the subscription is bound to userID.
+func (ps *PushSubscription) IsOwnedBy(userID valueobject.UserID) bool {
+	return ps.userID != nil && ps.userID.Equals(userID)
+}
+
 func (ps *PushSubscription) IsExpired() bool {
 	if ps.expirationTime == nil {
 		return false
@@ -120,3 +194,8 @@ func (ps *PushSubscription) UpdateUserAgent(userAgent string) {
 	ps.userAgent = userAgent
 	ps.updatedAt = time.Now()
 }
+
+func (ps *PushSubscription) AssignVAPIDKey(keyID int64) {
+	ps.vapidKeyID = &keyID
+	ps.updatedAt = time.Now()
+}
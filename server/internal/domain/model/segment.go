@@ -0,0 +1,159 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// Segment selects the subscriptions a job is sent to. Every criterion that
+// is set must match; an empty segment matches every valid subscription.
+// Criteria on the user (topics, attributes, locales) never match anonymous
+// subscriptions.
+type Segment struct {
+	topics           []string
+	attributes       map[string]string
+	browsers         []valueobject.Browser
+	locales          []string
+	activeWithinDays int
+	inactiveForDays  int
+}
+
+func NewSegment(
+	topics []string,
+	attributes map[string]string,
+	browsers []valueobject.Browser,
+	locales []string,
+	activeWithinDays int,
+	inactiveForDays int,
+) (*Segment, error) {
+	if activeWithinDays < 0 || inactiveForDays < 0 {
+		return nil, fmt.Errorf("activity windows must be non-negative")
+	}
+	if activeWithinDays > 0 && inactiveForDays >= activeWithinDays {
+		return nil, fmt.Errorf("inactiveForDays must be shorter than activeWithinDays")
+	}
+	for _, topic := range topics {
+		if topic == "" {
+			return nil, fmt.Errorf("topic cannot be empty")
+		}
+	}
+	for key := range attributes {
+		if key == "" {
+			return nil, fmt.Errorf("attribute key cannot be empty")
+		}
+	}
+	for _, locale := range locales {
+		if locale == "" {
+			return nil, fmt.Errorf("locale cannot be empty")
+		}
+	}
+
+	return &Segment{
+		topics:           topics,
+		attributes:       attributes,
+		browsers:         browsers,
+		locales:          locales,
+		activeWithinDays: activeWithinDays,
+		inactiveForDays:  inactiveForDays,
+	}, nil
+}
+
+// Topics are the topics the user must have explicitly opted in to.
+func (s *Segment) Topics() []string {
+	return s.topics
+}
+
+func (s *Segment) Attributes() map[string]string {
+	return s.attributes
+}
+
+func (s *Segment) Browsers() []valueobject.Browser {
+	return s.browsers
+}
+
+// Locales match the user's locale by language: "ja" matches "ja-JP".
+func (s *Segment) Locales() []string {
+	return s.locales
+}
+
+// ActiveWithinDays keeps subscriptions active in the last n days.
+func (s *Segment) ActiveWithinDays() int {
+	return s.activeWithinDays
+}
+
+// InactiveForDays keeps subscriptions with no activity for n days.
+func (s *Segment) InactiveForDays() int {
+	return s.inactiveForDays
+}
+
+// NeedsUser reports whether the segment has criteria on the user, so
+// callers know whether users and preferences must be loaded.
+func (s *Segment) NeedsUser() bool {
+	return len(s.topics) > 0 || len(s.attributes) > 0 || len(s.locales) > 0
+}
+
+// Matches evaluates the segment against a subscription. user and prefs are
+// nil for anonymous subscriptions and users without saved preferences.
+func (s *Segment) Matches(subscription *PushSubscription, user *User, prefs *NotificationPrefs, now time.Time) bool {
+	if len(s.browsers) > 0 && !containsBrowser(s.browsers, subscription.Browser()) {
+		return false
+	}
+
+	lastActive := subscription.LastActiveAt()
+	if s.activeWithinDays > 0 && lastActive.Before(now.AddDate(0, 0, -s.activeWithinDays)) {
+		return false
+	}
+	if s.inactiveForDays > 0 && lastActive.After(now.AddDate(0, 0, -s.inactiveForDays)) {
+		return false
+	}
+
+	if !s.NeedsUser() {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+
+	for _, topic := range s.topics {
+		if prefs == nil || !prefs.OptedInTo(topic) {
+			return false
+		}
+	}
+	for key, value := range s.attributes {
+		if actual, ok := user.Attributes()[key]; !ok || actual != value {
+			return false
+		}
+	}
+	if len(s.locales) > 0 && !matchesLocale(s.locales, user.Locale()) {
+		return false
+	}
+	return true
+}
+
+func containsBrowser(browsers []valueobject.Browser, browser valueobject.Browser) bool {
+	for _, candidate := range browsers {
+		if candidate == browser {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesLocale compares language tags case-insensitively; a tag without a
+// region matches every region of that language.
+func matchesLocale(locales []string, locale string) bool {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if locale == "" {
+		return false
+	}
+	for _, candidate := range locales {
+		candidate = strings.ToLower(strings.ReplaceAll(candidate, "_", "-"))
+		if locale == candidate || strings.HasPrefix(locale, candidate+"-") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+const firefoxUserAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0"
+
+func newSegmentTestSubscription(t *testing.T, userID *valueobject.UserID, userAgent string, lastActiveAt time.Time) *PushSubscription {
+	t.Helper()
+	id, _ := valueobject.NewSubscriptionID(1)
+	endpoint, err := valueobject.NewPushEndpoint("https://updates.push.services.mozilla.com/wpush/v2/abc")
+	if err != nil {
+		t.Fatalf("NewPushEndpoint: %v", err)
+	}
+	p256dh, _ := valueobject.NewP256dhKey("BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM")
+	auth, _ := valueobject.NewAuthKey("tBHItJI5svbpez7KI4CCXg")
+	now := time.Now()
+	return ReconstructPushSubscription(id, valueobject.NewPublicSubscriptionID(), userID, endpoint,
+		valueobject.NewPushKeys(p256dh, auth), userAgent, nil, nil, true, lastActiveAt, now, now)
+}
+
+func TestSegmentMatches(t *testing.T) {
+	now := time.Now()
+	userID, _ := valueobject.NewUserID(1)
+	email, _ := valueobject.NewEmail("user@example.com")
+	user := ReconstructUser(userID, "User", email, "ja-JP", map[string]string{"plan": "premium"}, now, now)
+	prefs := NewNotificationPrefs(userID)
+	prefs.Update(true, map[string]bool{"news": true, "promo": false}, nil)
+
+	owned := newSegmentTestSubscription(t, &userID, firefoxUserAgent, now.AddDate(0, 0, -3))
+	anonymous := newSegmentTestSubscription(t, nil, firefoxUserAgent, now)
+
+	tests := []struct {
+		name      string
+		segment   func() (*Segment, error)
+		owned     bool
+		anonymous bool
+	}{
+		{"empty", func() (*Segment, error) { return NewSegment(nil, nil, nil, nil, 0, 0) }, true, true},
+		{"opted-in topic", func() (*Segment, error) { return NewSegment([]string{"news"}, nil, nil, nil, 0, 0) }, true, false},
+		{"default topic is not an opt-in", func() (*Segment, error) { return NewSegment([]string{"sports"}, nil, nil, nil, 0, 0) }, false, false},
+		{"attribute", func() (*Segment, error) {
+			return NewSegment(nil, map[string]string{"plan": "premium"}, nil, nil, 0, 0)
+		}, true, false},
+		{"other attribute value", func() (*Segment, error) {
+			return NewSegment(nil, map[string]string{"plan": "free"}, nil, nil, 0, 0)
+		}, false, false},
+		{"browser", func() (*Segment, error) {
+			return NewSegment(nil, nil, []valueobject.Browser{valueobject.BrowserFirefox}, nil, 0, 0)
+		}, true, true},
+		{"other browser", func() (*Segment, error) {
+			return NewSegment(nil, nil, []valueobject.Browser{valueobject.BrowserChrome}, nil, 0, 0)
+		}, false, false},
+		{"language matches region", func() (*Segment, error) { return NewSegment(nil, nil, nil, []string{"ja"}, 0, 0) }, true, false},
+		{"other locale", func() (*Segment, error) { return NewSegment(nil, nil, nil, []string{"en-US"}, 0, 0) }, false, false},
+		{"active within", func() (*Segment, error) { return NewSegment(nil, nil, nil, nil, 7, 0) }, true, true},
+		{"inactive for", func() (*Segment, error) { return NewSegment(nil, nil, nil, nil, 0, 2) }, true, false},
+		{"not inactive long enough", func() (*Segment, error) { return NewSegment(nil, nil, nil, nil, 0, 5) }, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segment, err := tt.segment()
+			if err != nil {
+				t.Fatalf("NewSegment: %v", err)
+			}
+			if got := segment.Matches(owned, user, prefs, now); got != tt.owned {
+				t.Errorf("Matches(owned) = %v, want %v", got, tt.owned)
+			}
+			if got := segment.Matches(anonymous, nil, nil, now); got != tt.anonymous {
+				t.Errorf("Matches(anonymous) = %v, want %v", got, tt.anonymous)
+			}
+		})
+	}
+}
+
+func TestNewSegmentValidation(t *testing.T) {
+	if _, err := NewSegment(nil, nil, nil, nil, -1, 0); err == nil {
+		t.Error("negative activeWithinDays accepted")
+	}
+	if _, err := NewSegment(nil, nil, nil, nil, 7, 7); err == nil {
+		t.Error("inactiveForDays >= activeWithinDays accepted")
+	}
+	if _, err := NewSegment([]string{""}, nil, nil, nil, 0, 0); err == nil {
+		t.Error("empty topic accepted")
+	}
+	if _, err := NewSegment(nil, nil, nil, nil, 30, 7); err != nil {
+		t.Errorf("30/7 day window rejected: %v", err)
+	}
+}
@@ -8,31 +8,46 @@ import (
 )
 
 type User struct {
-	id        valueobject.UserID
-	name      string
-	email     valueobject.Email
-	createdAt time.Time
-	updatedAt time.Time
+	id         valueobject.UserID
+	name       string
+	email      valueobject.Email
+	locale     string
+	attributes map[string]string
+	createdAt  time.Time
+	updatedAt  time.Time
 }
 
 func NewUser(id valueobject.UserID, name string, email valueobject.Email) *User {
 	now := time.Now()
 	return &User{
-		id:        id,
-		name:      name,
-		email:     email,
-		createdAt: now,
-		updatedAt: now,
+		id:         id,
+		name:       name,
+		email:      email,
+		attributes: map[string]string{},
+		createdAt:  now,
+		updatedAt:  now,
 	}
 }
 
-func ReconstructUser(id valueobject.UserID, name string, email valueobject.Email, createdAt, updatedAt time.Time) *User {
+func ReconstructUser(
+	id valueobject.UserID,
+	name string,
+	email valueobject.Email,
+	locale string,
+	attributes map[string]string,
+	createdAt, updatedAt time.Time,
+) *User {
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
 	return &User{
-		id:        id,
-		name:      name,
-		email:     email,
-		createdAt: createdAt,
-		updatedAt: updatedAt,
+		id:         id,
+		name:       name,
+		email:      email,
+		locale:     locale,
+		attributes: attributes,
+		createdAt:  createdAt,
+		updatedAt:  updatedAt,
 	}
 }
 
@@ -48,6 +63,17 @@ func (u *User) Email() valueobject.Email {
 	return u.email
 }
 
+// Locale is a BCP 47 language tag such as "ja-JP", or empty when unknown.
+func (u *User) Locale() string {
+	return u.locale
+}
+
+// Attributes are free-form key/value pairs (e.g. "plan": "premium") that
+// segments can match on.
+func (u *User) Attributes() map[string]string {
+	return u.attributes
+}
+
 func (u *User) CreatedAt() time.Time {
 	return u.createdAt
 }
@@ -69,3 +95,23 @@ func (u *User) ChangeEmail(email valueobject.Email) {
 	u.email = email
 	u.updatedAt = time.Now()
 }
+
+func (u *User) ChangeLocale(locale string) {
+	u.locale = locale
+	u.updatedAt = time.Now()
+}
+
+// SetAttributes replaces the user's attributes.
+func (u *User) SetAttributes(attributes map[string]string) error {
+	for key := range attributes {
+		if key == "" {
+			return fmt.Errorf("attribute key cannot be empty")
+		}
+	}
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	u.attributes = attributes
+	u.updatedAt = time.Now()
+	return nil
+}
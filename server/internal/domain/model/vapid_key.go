@@ -0,0 +1,93 @@
+package model
+
+import (
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// VAPIDKey is an application server key pair (RFC 8292). Exactly one key is
+// active and handed to browsers for new subscriptions; retired keys are kept
+// so subscriptions created under them can still be sent to.
+type VAPIDKey struct {
+	id          int64
+	publicKey   string
+	privateKey  string
+	subject     valueobject.VAPIDSubject
+	isActive    bool
+	createdAt   time.Time
+	activatedAt *time.Time
+}
+
+func NewVAPIDKey(id int64, publicKey, privateKey string) *VAPIDKey {
+	return &VAPIDKey{
+		id:         id,
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		createdAt:  time.Now(),
+	}
+}
+
+func ReconstructVAPIDKey(
+	id int64,
+	publicKey, privateKey string,
+	subject valueobject.VAPIDSubject,
+	isActive bool,
+	createdAt time.Time,
+	activatedAt *time.Time,
+) *VAPIDKey {
+	return &VAPIDKey{
+		id:          id,
+		publicKey:   publicKey,
+		privateKey:  privateKey,
+		subject:     subject,
+		isActive:    isActive,
+		createdAt:   createdAt,
+		activatedAt: activatedAt,
+	}
+}
+
+func (k *VAPIDKey) ID() int64 {
+	return k.id
+}
+
+func (k *VAPIDKey) PublicKey() string {
+	return k.publicKey
+}
+
+func (k *VAPIDKey) PrivateKey() string {
+	return k.privateKey
+}
+
+// Subject overrides the server's default VAPID subject for pushes signed
+// with this key, so different applications can be contacted separately.
+// It is zero when the default applies.
+func (k *VAPIDKey) Subject() valueobject.VAPIDSubject {
+	return k.subject
+}
+
+func (k *VAPIDKey) SetSubject(subject valueobject.VAPIDSubject) {
+	k.subject = subject
+}
+
+func (k *VAPIDKey) IsActive() bool {
+	return k.isActive
+}
+
+func (k *VAPIDKey) CreatedAt() time.Time {
+	return k.createdAt
+}
+
+func (k *VAPIDKey) ActivatedAt() *time.Time {
+	return k.activatedAt
+}
+
+func (k *VAPIDKey) Activate() {
+	now := time.Now()
+	k.isActive = true
+	k.activatedAt = &now
+}
+
+func (k *VAPIDKey) Deactivate() {
+	k.isActive = false
+}
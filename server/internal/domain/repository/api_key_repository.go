@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+type APIKeyRepository interface {
+	Save(ctx context.Context, key *model.APIKey) error
+	FindByID(ctx context.Context, id int64) (*model.APIKey, error)
+	FindBySecretHash(ctx context.Context, secretHash string) (*model.APIKey, error)
+	FindAll(ctx context.Context) ([]*model.APIKey, error)
+	// TouchLastUsed records that an active key was used at at. It leaves
+	// revoked keys alone, so it cannot undo a concurrent revocation.
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+	NextIdentity(ctx context.Context) (int64, error)
+}
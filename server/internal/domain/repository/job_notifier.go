@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// JobNotifier wakes push dispatchers when a job becomes ready, so it is
+// sent right away instead of at the next poll. Notifications are best
+// effort: dispatchers still poll to catch anything that was missed.
+type JobNotifier interface {
+	// NotifyJobReady announces that a job becomes ready to send, or a
+	// schedule is due to spawn one, at readyAt; a zero or past time means
+	// now.
+	NotifyJobReady(ctx context.Context, readyAt time.Time) error
+	// Subscribe delivers the announced ready times, from every process
+	// sharing the repository, until ctx is cancelled.
+	Subscribe(ctx context.Context) (<-chan time.Time, error)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// Locker provides named locks shared by every replica, for periodic tasks
+// that should run on only one of them at a time.
+type Locker interface {
+	// TryLock acquires the named lock without waiting. When acquired is
+	// true the caller must call release once done; when another holder has
+	// the lock, acquired is false and release is nil.
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type NotificationEventRepository interface {
+	Save(ctx context.Context, event *model.NotificationEvent) error
+	// CountByJobID counts distinct subscriptions per event type. Events
+	// without a subscription count individually.
+	CountByJobID(ctx context.Context, jobID valueobject.JobID) (model.EngagementCounts, error)
+	NextIdentity(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type NotificationPrefsRepository interface {
+	Save(ctx context.Context, prefs *model.NotificationPrefs) error
+	FindByUserID(ctx context.Context, userID valueobject.UserID) (*model.NotificationPrefs, error)
+	// FindByUserIDs returns the saved preferences of the given users; users
+	// without saved preferences are absent from the map.
+	FindByUserIDs(ctx context.Context, userIDs []valueobject.UserID) (map[valueobject.UserID]*model.NotificationPrefs, error)
+}
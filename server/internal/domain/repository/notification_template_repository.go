@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+type NotificationTemplateRepository interface {
+	Save(ctx context.Context, template *model.NotificationTemplate) error
+	FindByKey(ctx context.Context, key string) (*model.NotificationTemplate, error)
+	FindAll(ctx context.Context) ([]*model.NotificationTemplate, error)
+	// Delete returns errors.ErrTemplateInUse when push jobs or schedules
+	// still reference the template.
+	Delete(ctx context.Context, key string) error
+	NextIdentity(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type PushDeliveryRepository interface {
+	// CreateForJob adds a pending delivery for each subscription, skipping
+	// pairs that already exist.
+	CreateForJob(ctx context.Context, jobID valueobject.JobID, subscriptionIDs []valueobject.SubscriptionID) error
+	Save(ctx context.Context, delivery *model.PushDelivery) error
+	// Hold postpones a due delivery to until for reason, e.g. while it waits
+	// in the delivery queue. It reports false when the delivery is no longer
+	// due or is leased, so of two rounds racing for it only one hands it out.
+	Hold(ctx context.Context, id int64, until time.Time, reason string) (bool, error)
+	// Claim leases a pending delivery to owner for leaseDuration so a single
+	// worker sends it, whether or not it is due. It returns nil when the
+	// delivery is settled or leased to another owner.
+	Claim(ctx context.Context, id int64, owner string, leaseDuration time.Duration) (*model.PushDelivery, error)
+	// SaveClaimed saves the outcome of a claimed delivery and ends the lease.
+	// It fails with errors.ErrDeliveryLeaseLost when owner no longer holds
+	// the lease.
+	SaveClaimed(ctx context.Context, delivery *model.PushDelivery, owner string) error
+	FindByID(ctx context.Context, id int64) (*model.PushDelivery, error)
+	FindByJobID(ctx context.Context, jobID valueobject.JobID) ([]*model.PushDelivery, error)
+	// FindByJobAndSubscription returns nil when the job was not sent to the
+	// subscription.
+	FindByJobAndSubscription(ctx context.Context, jobID valueobject.JobID, subscriptionID valueobject.SubscriptionID) (*model.PushDelivery, error)
+	// FindDueByJobID returns the pending deliveries due at now that are not
+	// leased to a worker.
+	FindDueByJobID(ctx context.Context, jobID valueobject.JobID, now time.Time) ([]*model.PushDelivery, error)
+	CountByJobID(ctx context.Context, jobID valueobject.JobID) (model.DeliveryCounts, error)
+	// NextAttemptAt returns the earliest retry time among the job's pending
+	// deliveries, or nil when none are pending. Leased deliveries count from
+	// when their lease expires.
+	NextAttemptAt(ctx context.Context, jobID valueobject.JobID) (*time.Time, error)
+}
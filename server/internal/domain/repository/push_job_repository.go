@@ -2,13 +2,30 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
 )
 
+// PushJobFilter narrows FindJobs. Zero values match every job.
+type PushJobFilter struct {
+	Status model.JobStatus
+	UserID *valueobject.UserID
+	Topic  string
+	// BeforeID is the pagination cursor: only jobs with a lower ID are
+	// returned. Jobs are ordered newest first.
+	BeforeID *valueobject.JobID
+	Limit    int
+}
+
 type PushJobRepository interface {
 	Save(ctx context.Context, job *model.PushJob) error
+	// CreateBatch saves a new batch job together with its recipients
+	// atomically. It fails with errors.ErrDuplicateIdempotencyKey when
+	// another job already uses the job's idempotency key.
+	CreateBatch(ctx context.Context, job *model.PushJob, recipients []valueobject.UserID) error
+	FindRecipients(ctx context.Context, id valueobject.JobID) ([]valueobject.UserID, error)
 	FindByID(ctx context.Context, id valueobject.JobID) (*model.PushJob, error)
 	FindByIdempotencyKey(ctx context.Context, key string) (*model.PushJob, error)
 	FindPendingJobs(ctx context.Context, limit int) ([]*model.PushJob, error)
@@ -17,6 +34,39 @@ type PushJobRepository interface {
 	UpdateStatus(ctx context.Context, id valueobject.JobID, status model.JobStatus, lastError string) error
 	IncrementRetryCount(ctx context.Context, id valueobject.JobID) error
 	Delete(ctx context.Context, id valueobject.JobID) error
-	DeleteOldCompletedJobs(ctx context.Context, olderThan int) error
+	// DeleteOldCompletedJobs removes succeeded, failed and cancelled jobs last
+	// updated more than olderThanDays ago, with their deliveries and events,
+	// and returns how many jobs were removed.
+	DeleteOldCompletedJobs(ctx context.Context, olderThanDays int) (int, error)
 	NextIdentity(ctx context.Context) (valueobject.JobID, error)
+	FindJobs(ctx context.Context, filter PushJobFilter) ([]*model.PushJob, error)
+	// Cancel atomically moves a pending job to cancelled. It returns nil when
+	// the job no longer exists or a sender has already picked it up.
+	Cancel(ctx context.Context, id valueobject.JobID) (*model.PushJob, error)
+
+	// ClaimReadyJobs atomically moves up to limit ready jobs (pending and due,
+	// or sending with an expired lease) to sending under owner's lease.
+	// Concurrent callers never receive the same job.
+	ClaimReadyJobs(ctx context.Context, owner string, leaseDuration time.Duration, limit int) ([]*model.PushJob, error)
+	// ClaimJob claims a single job if it is still in the expected status.
+	// It returns nil when another sender got there first.
+	ClaimJob(ctx context.Context, id valueobject.JobID, owner string, leaseDuration time.Duration, expected model.JobStatus) (*model.PushJob, error)
+	// RenewLease extends owner's lease; it fails with errors.ErrJobLeaseLost
+	// when the job is no longer held by owner.
+	RenewLease(ctx context.Context, id valueobject.JobID, owner string, leaseDuration time.Duration) error
+	// NextClaimableAt returns the earliest future time at which a job becomes
+	// claimable (scheduled, awaiting retry or leased), or nil when none will.
+	NextClaimableAt(ctx context.Context) (*time.Time, error)
+	// SaveClaimed writes the outcome of processing a job held by owner; it
+	// fails with errors.ErrJobLeaseLost when another sender has reclaimed the
+	// job in the meantime, leaving the new owner's state untouched.
+	SaveClaimed(ctx context.Context, job *model.PushJob, owner string) error
+	// ReleaseLease returns a job held by owner to pending so another sender
+	// can claim it right away; it fails with errors.ErrJobLeaseLost when the
+	// job is no longer held by owner.
+	ReleaseLease(ctx context.Context, id valueobject.JobID, owner string) error
+	// AdvanceNextAttempt brings a pending job's next attempt forward to at
+	// and reports whether it did. Jobs that are not pending, or are due by
+	// then anyway, are left alone.
+	AdvanceNextAttempt(ctx context.Context, id valueobject.JobID, at time.Time) (bool, error)
 }
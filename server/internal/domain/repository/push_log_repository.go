@@ -2,16 +2,30 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
 )
 
+// PushLogFilter narrows FindLogs. Logs are returned oldest first.
+type PushLogFilter struct {
+	JobID          *valueobject.JobID
+	SubscriptionID *valueobject.SubscriptionID
+	// Since is inclusive, Until exclusive.
+	Since *time.Time
+	Until *time.Time
+	// AfterID is the pagination cursor: only logs with a higher ID are returned.
+	AfterID int64
+	Limit   int
+}
+
 type PushLogRepository interface {
 	Save(ctx context.Context, log *model.PushLog) error
 	FindByJobID(ctx context.Context, jobID valueobject.JobID) ([]*model.PushLog, error)
 	FindBySubscriptionID(ctx context.Context, subscriptionID valueobject.SubscriptionID) ([]*model.PushLog, error)
-	DeleteOldLogs(ctx context.Context, olderThanDays int) error
+	FindLogs(ctx context.Context, filter PushLogFilter) ([]*model.PushLog, error)
+	DeleteOldLogs(ctx context.Context, olderThanDays int) (int, error)
 	CountSuccessByJobID(ctx context.Context, jobID valueobject.JobID) (int, error)
 	CountFailuresByJobID(ctx context.Context, jobID valueobject.JobID) (int, error)
 	NextIdentity(ctx context.Context) (int64, error)
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type PushScheduleRepository interface {
+	Save(ctx context.Context, schedule *model.PushSchedule) error
+	FindByID(ctx context.Context, id valueobject.ScheduleID) (*model.PushSchedule, error)
+	FindAll(ctx context.Context) ([]*model.PushSchedule, error)
+	// FindDueSchedules returns up to limit active schedules whose next run
+	// is at or before now, earliest first.
+	FindDueSchedules(ctx context.Context, now time.Time, limit int) ([]*model.PushSchedule, error)
+	// RecordRun saves the advanced schedule together with the job spawned
+	// for occurrence (nil when the occurrence was skipped), provided the
+	// stored schedule is still active and still due at occurrence. It
+	// returns false, saving nothing, when another replica already handled
+	// the occurrence or the schedule was paused or deleted in the meantime.
+	RecordRun(ctx context.Context, schedule *model.PushSchedule, occurrence time.Time, job *model.PushJob) (bool, error)
+	Delete(ctx context.Context, id valueobject.ScheduleID) error
+	NextIdentity(ctx context.Context) (valueobject.ScheduleID, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
@@ -10,12 +11,26 @@ import (
 type PushSubscriptionRepository interface {
 	Save(ctx context.Context, subscription *model.PushSubscription) error
 	FindByID(ctx context.Context, id valueobject.SubscriptionID) (*model.PushSubscription, error)
+	FindByPublicID(ctx context.Context, publicID valueobject.PublicSubscriptionID) (*model.PushSubscription, error)
 	FindByEndpoint(ctx context.Context, endpoint valueobject.PushEndpoint) (*model.PushSubscription, error)
 	FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*model.PushSubscription, error)
 	FindValidSubscriptions(ctx context.Context) ([]*model.PushSubscription, error)
 	FindValidSubscriptionsByUserID(ctx context.Context, userID valueobject.UserID) ([]*model.PushSubscription, error)
+	FindValidSubscriptionsByUserIDs(ctx context.Context, userIDs []valueobject.UserID) ([]*model.PushSubscription, error)
+	// CountValidSubscriptionsByUserIDs returns how many valid subscriptions
+	// each user has; users without any are absent from the map.
+	CountValidSubscriptionsByUserIDs(ctx context.Context, userIDs []valueobject.UserID) (map[valueobject.UserID]int, error)
 	MarkAsInvalid(ctx context.Context, id valueobject.SubscriptionID) error
-	DeleteExpiredSubscriptions(ctx context.Context) error
+	// MarkActive moves the subscription's last activity forward to at; an
+	// older at is ignored. Only that column is written, so it cannot undo a
+	// concurrent update such as an invalidation.
+	MarkActive(ctx context.Context, id valueobject.SubscriptionID, at time.Time) error
+	// DeleteExpiredSubscriptions removes subscriptions past their expiration
+	// time and returns how many were removed.
+	DeleteExpiredSubscriptions(ctx context.Context) (int, error)
+	// DeleteInvalidSubscriptions removes subscriptions that have been invalid
+	// (e.g. gone at the push service) for longer than olderThanDays.
+	DeleteInvalidSubscriptions(ctx context.Context, olderThanDays int) (int, error)
 	Delete(ctx context.Context, id valueobject.SubscriptionID) error
 	NextIdentity(ctx context.Context) (valueobject.SubscriptionID, error)
 }
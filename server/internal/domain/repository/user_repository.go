@@ -12,6 +12,8 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id valueobject.UserID) (*model.User, error)
 	FindByEmail(ctx context.Context, email valueobject.Email) (*model.User, error)
 	FindAll(ctx context.Context) ([]*model.User, error)
+	// FindByIDs returns the given users that exist, keyed by ID.
+	FindByIDs(ctx context.Context, ids []valueobject.UserID) (map[valueobject.UserID]*model.User, error)
 	Delete(ctx context.Context, id valueobject.UserID) error
 	NextIdentity(ctx context.Context) (valueobject.UserID, error)
 }
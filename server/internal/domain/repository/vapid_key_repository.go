@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+type VAPIDKeyRepository interface {
+	Save(ctx context.Context, key *model.VAPIDKey) error
+	FindByID(ctx context.Context, id int64) (*model.VAPIDKey, error)
+	FindByPublicKey(ctx context.Context, publicKey string) (*model.VAPIDKey, error)
+	FindActive(ctx context.Context) (*model.VAPIDKey, error)
+	FindAll(ctx context.Context) ([]*model.VAPIDKey, error)
+	// Activate makes the key the only active one.
+	Activate(ctx context.Context, id int64) error
+	NextIdentity(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// AudienceEstimate is the outcome of evaluating a job's targeting without
+// sending it.
+type AudienceEstimate struct {
+	// Subscriptions is the number of browsers the job would be expanded to.
+	Subscriptions int
+	// Users is the number of distinct users among them; anonymous
+	// subscriptions are counted in Anonymous instead.
+	Users     int
+	Anonymous int
+	// OptedOut subscriptions belong to users who turned the job's topic off
+	// and would be skipped at send time.
+	OptedOut int
+}
+
+// AudienceTarget is who a job is addressed to: one user, the recipients of
+// a batch, or, when neither is set, everyone. Segment narrows the result.
+type AudienceTarget struct {
+	UserID *valueobject.UserID
+	// Batch addresses only Recipients. A batch whose recipients have all
+	// been deleted reaches nobody rather than everyone.
+	Batch      bool
+	Recipients []valueobject.UserID
+	Segment    *model.Segment
+}
+
+// AudienceService resolves the valid subscriptions a job is sent to.
+type AudienceService struct {
+	subscriptionRepo repository.PushSubscriptionRepository
+	userRepo         repository.UserRepository
+	prefsRepo        repository.NotificationPrefsRepository
+}
+
+func NewAudienceService(
+	subscriptionRepo repository.PushSubscriptionRepository,
+	userRepo repository.UserRepository,
+	prefsRepo repository.NotificationPrefsRepository,
+) *AudienceService {
+	return &AudienceService{
+		subscriptionRepo: subscriptionRepo,
+		userRepo:         userRepo,
+		prefsRepo:        prefsRepo,
+	}
+}
+
+// Resolve returns the valid subscriptions addressed by target.
+func (as *AudienceService) Resolve(ctx context.Context, target AudienceTarget) ([]*model.PushSubscription, error) {
+	subscriptions, err := as.candidates(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	segment := target.Segment
+	if segment == nil {
+		return subscriptions, nil
+	}
+
+	var prefs map[valueobject.UserID]*model.NotificationPrefs
+	var users map[valueobject.UserID]*model.User
+	if segment.NeedsUser() {
+		users, prefs, err = as.loadUsers(ctx, subscriptions, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filterSegment(subscriptions, segment, users, prefs, time.Now()), nil
+}
+
+// Estimate evaluates the targeting the way the sender would at this moment
+// and counts the result, including the subscriptions that would be skipped
+// because their user opted out of topic.
+func (as *AudienceService) Estimate(ctx context.Context, target AudienceTarget, topic string) (*AudienceEstimate, error) {
+	subscriptions, err := as.candidates(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	segment := target.Segment
+
+	needsUsers := segment != nil && segment.NeedsUser()
+	users, prefs, err := as.loadUsers(ctx, subscriptions, needsUsers)
+	if err != nil {
+		return nil, err
+	}
+	if segment != nil {
+		subscriptions = filterSegment(subscriptions, segment, users, prefs, time.Now())
+	}
+
+	estimate := &AudienceEstimate{Subscriptions: len(subscriptions)}
+	seen := make(map[valueobject.UserID]bool)
+	for _, subscription := range subscriptions {
+		owner := subscription.UserID()
+		if owner == nil {
+			estimate.Anonymous++
+			continue
+		}
+		if !seen[*owner] {
+			seen[*owner] = true
+			estimate.Users++
+		}
+		if userPrefs := prefs[*owner]; userPrefs != nil && !userPrefs.AllowsTopic(topic) {
+			estimate.OptedOut++
+		}
+	}
+	return estimate, nil
+}
+
+func (as *AudienceService) candidates(ctx context.Context, target AudienceTarget) ([]*model.PushSubscription, error) {
+	if target.UserID != nil {
+		subscriptions, err := as.subscriptionRepo.FindValidSubscriptionsByUserID(ctx, *target.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user subscriptions: %w", err)
+		}
+		return subscriptions, nil
+	}
+
+	if target.Batch {
+		if len(target.Recipients) == 0 {
+			return nil, nil
+		}
+		subscriptions, err := as.subscriptionRepo.FindValidSubscriptionsByUserIDs(ctx, target.Recipients)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recipient subscriptions: %w", err)
+		}
+		return subscriptions, nil
+	}
+
+	subscriptions, err := as.subscriptionRepo.FindValidSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// loadUsers fetches the preferences, and the users themselves when
+// withUsers is set, of the subscriptions' owners in one query each.
+func (as *AudienceService) loadUsers(
+	ctx context.Context,
+	subscriptions []*model.PushSubscription,
+	withUsers bool,
+) (map[valueobject.UserID]*model.User, map[valueobject.UserID]*model.NotificationPrefs, error) {
+	seen := make(map[valueobject.UserID]bool)
+	var userIDs []valueobject.UserID
+	for _, subscription := range subscriptions {
+		if owner := subscription.UserID(); owner != nil && !seen[*owner] {
+			seen[*owner] = true
+			userIDs = append(userIDs, *owner)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	prefs, err := as.prefsRepo.FindByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get notification prefs: %w", err)
+	}
+	if !withUsers {
+		return nil, prefs, nil
+	}
+
+	users, err := as.userRepo.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	return users, prefs, nil
+}
+
+func filterSegment(
+	subscriptions []*model.PushSubscription,
+	segment *model.Segment,
+	users map[valueobject.UserID]*model.User,
+	prefs map[valueobject.UserID]*model.NotificationPrefs,
+	now time.Time,
+) []*model.PushSubscription {
+	matched := make([]*model.PushSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		var user *model.User
+		var userPrefs *model.NotificationPrefs
+		if owner := subscription.UserID(); owner != nil {
+			user = users[*owner]
+			userPrefs = prefs[*owner]
+		}
+		if segment.Matches(subscription, user, userPrefs, now) {
+			matched = append(matched, subscription)
+		}
+	}
+	return matched
+}
@@ -9,18 +9,34 @@ import (
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
 )
 
+// RecipientSkipReason explains why a user was left out of a batch.
+type RecipientSkipReason string
+
+const (
+	RecipientSkipNoSubscriptions RecipientSkipReason = "no_subscriptions"
+	RecipientSkipOptedOut        RecipientSkipReason = "opted_out"
+)
+
+type SkippedRecipient struct {
+	UserID valueobject.UserID
+	Reason RecipientSkipReason
+}
+
 type PushService struct {
 	subscriptionRepo repository.PushSubscriptionRepository
 	jobRepo          repository.PushJobRepository
+	prefsRepo        repository.NotificationPrefsRepository
 }
 
 func NewPushService(
 	subscriptionRepo repository.PushSubscriptionRepository,
 	jobRepo repository.PushJobRepository,
+	prefsRepo repository.NotificationPrefsRepository,
 ) *PushService {
 	return &PushService{
 		subscriptionRepo: subscriptionRepo,
 		jobRepo:          jobRepo,
+		prefsRepo:        prefsRepo,
 	}
 }
 
@@ -54,6 +70,57 @@ func (ps *PushService) CanUserReceivePush(ctx context.Context, userID valueobjec
 	return len(subscriptions) > 0, nil
 }
 
+// UserAllowsTopic checks the user's notification preferences. Users without
+// saved preferences receive every topic.
+func (ps *PushService) UserAllowsTopic(ctx context.Context, userID valueobject.UserID, topic string) (bool, error) {
+	prefs, err := ps.prefsRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get notification prefs: %w", err)
+	}
+	if prefs == nil {
+		return true, nil
+	}
+	return prefs.AllowsTopic(topic), nil
+}
+
+// PartitionRecipients splits a batch's users into those who can receive a
+// push on topic and those who are skipped, with one query for subscriptions
+// and one for preferences regardless of the number of users. Duplicate IDs
+// are dropped; the order of userIDs is kept.
+func (ps *PushService) PartitionRecipients(ctx context.Context, userIDs []valueobject.UserID, topic string) ([]valueobject.UserID, []SkippedRecipient, error) {
+	seen := make(map[valueobject.UserID]bool, len(userIDs))
+	unique := make([]valueobject.UserID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if !seen[userID] {
+			seen[userID] = true
+			unique = append(unique, userID)
+		}
+	}
+
+	counts, err := ps.subscriptionRepo.CountValidSubscriptionsByUserIDs(ctx, unique)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count user subscriptions: %w", err)
+	}
+	prefs, err := ps.prefsRepo.FindByUserIDs(ctx, unique)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get notification prefs: %w", err)
+	}
+
+	var eligible []valueobject.UserID
+	var skipped []SkippedRecipient
+	for _, userID := range unique {
+		switch userPrefs := prefs[userID]; {
+		case counts[userID] == 0:
+			skipped = append(skipped, SkippedRecipient{UserID: userID, Reason: RecipientSkipNoSubscriptions})
+		case userPrefs != nil && !userPrefs.AllowsTopic(topic):
+			skipped = append(skipped, SkippedRecipient{UserID: userID, Reason: RecipientSkipOptedOut})
+		default:
+			eligible = append(eligible, userID)
+		}
+	}
+	return eligible, skipped, nil
+}
+
 func (ps *PushService) CountActiveSubscriptions(ctx context.Context, userID valueobject.UserID) (int, error) {
 	subscriptions, err := ps.subscriptionRepo.FindValidSubscriptionsByUserID(ctx, userID)
 	if err != nil {
@@ -70,10 +137,23 @@ func (ps *PushService) CountActiveSubscriptions(ctx context.Context, userID valu
 	return activeCount, nil
 }
 
-func (ps *PushService) CleanupExpiredSubscriptions(ctx context.Context) error {
-	err := ps.subscriptionRepo.DeleteExpiredSubscriptions(ctx)
+// CleanupExpiredSubscriptions removes subscriptions past their expiration
+// time and returns how many were removed.
+func (ps *PushService) CleanupExpiredSubscriptions(ctx context.Context) (int, error) {
+	deleted, err := ps.subscriptionRepo.DeleteExpiredSubscriptions(ctx)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to cleanup expired subscriptions: %w", err)
+	}
+	return deleted, nil
+}
+
+// CleanupInvalidSubscriptions removes subscriptions that have been invalid
+// for longer than olderThanDays. Until then a browser that subscribes again
+// revives the same subscription.
+func (ps *PushService) CleanupInvalidSubscriptions(ctx context.Context, olderThanDays int) (int, error) {
+	deleted, err := ps.subscriptionRepo.DeleteInvalidSubscriptions(ctx, olderThanDays)
 	if err != nil {
-		return fmt.Errorf("failed to cleanup expired subscriptions: %w", err)
+		return deleted, fmt.Errorf("failed to cleanup invalid subscriptions: %w", err)
 	}
-	return nil
+	return deleted, nil
 }
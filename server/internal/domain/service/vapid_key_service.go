@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+// VAPIDKeyService manages the stored VAPID keys: the active key is given to
+// browsers for new subscriptions, and each subscription is sent to with the
+// key it was created under.
+type VAPIDKeyService struct {
+	keyRepo repository.VAPIDKeyRepository
+
+	// Key material never changes once stored, so keys looked up for
+	// delivery are cached by ID.
+	mu    sync.RWMutex
+	cache map[int64]*model.VAPIDKey
+}
+
+func NewVAPIDKeyService(keyRepo repository.VAPIDKeyRepository) *VAPIDKeyService {
+	return &VAPIDKeyService{
+		keyRepo: keyRepo,
+		cache:   make(map[int64]*model.VAPIDKey),
+	}
+}
+
+// EnsureActiveKey prepares the key store at startup. A configured key pair
+// is imported (and activated when no key is active yet, so a key rotated
+// later is not overridden on the next boot); without one, a new key pair is
+// generated when the store has no active key.
+func (s *VAPIDKeyService) EnsureActiveKey(ctx context.Context, configured *VAPIDService) (*model.VAPIDKey, error) {
+	active, err := s.keyRepo.FindActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find active VAPID key: %w", err)
+	}
+
+	if configured != nil {
+		key, err := s.importKey(ctx, configured)
+		if err != nil {
+			return nil, err
+		}
+		if active == nil {
+			return s.ActivateKey(ctx, key.ID())
+		}
+	}
+
+	if active == nil {
+		return s.GenerateKey(ctx, true, valueobject.VAPIDSubject{})
+	}
+
+	// Refuse to start with a corrupt key rather than failing every push.
+	if err := NewVAPIDServiceWithKeys(active.PrivateKey(), active.PublicKey()).ValidateKeyPair(); err != nil {
+		return nil, fmt.Errorf("stored VAPID key %d is invalid: %w", active.ID(), err)
+	}
+	return active, nil
+}
+
+func (s *VAPIDKeyService) importKey(ctx context.Context, pair *VAPIDService) (*model.VAPIDKey, error) {
+	if err := pair.ValidateKeyPair(); err != nil {
+		return nil, fmt.Errorf("invalid VAPID key pair: %w", err)
+	}
+
+	existing, err := s.keyRepo.FindByPublicKey(ctx, pair.GetPublicKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find VAPID key: %w", err)
+	}
+	if existing != nil {
+		if existing.PrivateKey() != pair.GetPrivateKey() {
+			return nil, fmt.Errorf("stored VAPID key %d has the configured public key but a different private key", existing.ID())
+		}
+		return existing, nil
+	}
+
+	return s.store(ctx, pair, valueobject.VAPIDSubject{})
+}
+
+// GenerateKey creates and stores a new key pair, optionally making it the
+// active key right away. A non-zero subject overrides the default VAPID
+// subject for this key.
+func (s *VAPIDKeyService) GenerateKey(ctx context.Context, activate bool, subject valueobject.VAPIDSubject) (*model.VAPIDKey, error) {
+	pair, err := NewVAPIDService()
+	if err != nil {
+		return nil, err
+	}
+	if err := pair.ValidateKeyPair(); err != nil {
+		return nil, fmt.Errorf("generated VAPID key pair is invalid: %w", err)
+	}
+
+	key, err := s.store(ctx, pair, subject)
+	if err != nil {
+		return nil, err
+	}
+	if activate {
+		return s.ActivateKey(ctx, key.ID())
+	}
+	return key, nil
+}
+
+func (s *VAPIDKeyService) store(ctx context.Context, pair *VAPIDService, subject valueobject.VAPIDSubject) (*model.VAPIDKey, error) {
+	id, err := s.keyRepo.NextIdentity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate VAPID key ID: %w", err)
+	}
+
+	key := model.NewVAPIDKey(id, pair.GetPublicKey(), pair.GetPrivateKey())
+	key.SetSubject(subject)
+	if err := s.keyRepo.Save(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to save VAPID key: %w", err)
+	}
+	return key, nil
+}
+
+// ActivateKey makes the key the one handed out for new subscriptions. The
+// previously active key is retired but kept for existing subscriptions.
+func (s *VAPIDKeyService) ActivateKey(ctx context.Context, id int64) (*model.VAPIDKey, error) {
+	key, err := s.keyRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find VAPID key: %w", err)
+	}
+	if key == nil {
+		return nil, errors.ErrVAPIDKeyNotFound
+	}
+
+	if err := s.keyRepo.Activate(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to activate VAPID key: %w", err)
+	}
+	return s.keyRepo.FindByID(ctx, id)
+}
+
+func (s *VAPIDKeyService) ActiveKey(ctx context.Context) (*model.VAPIDKey, error) {
+	key, err := s.keyRepo.FindActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find active VAPID key: %w", err)
+	}
+	if key == nil {
+		return nil, errors.ErrNoActiveVAPIDKey
+	}
+	return key, nil
+}
+
+func (s *VAPIDKeyService) FindByPublicKey(ctx context.Context, publicKey string) (*model.VAPIDKey, error) {
+	key, err := s.keyRepo.FindByPublicKey(ctx, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find VAPID key: %w", err)
+	}
+	return key, nil
+}
+
+func (s *VAPIDKeyService) ListKeys(ctx context.Context) ([]*model.VAPIDKey, error) {
+	keys, err := s.keyRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VAPID keys: %w", err)
+	}
+	return keys, nil
+}
+
+// KeyForSubscription returns the key to sign pushes to the subscription
+// with: the key it was created under, or the active key for subscriptions
+// that predate key tracking.
+func (s *VAPIDKeyService) KeyForSubscription(ctx context.Context, subscription *model.PushSubscription) (*model.VAPIDKey, error) {
+	if subscription.VAPIDKeyID() == nil {
+		return s.ActiveKey(ctx)
+	}
+
+	id := *subscription.VAPIDKeyID()
+	s.mu.RLock()
+	key, cached := s.cache[id]
+	s.mu.RUnlock()
+	if cached {
+		return key, nil
+	}
+
+	key, err := s.keyRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find VAPID key: %w", err)
+	}
+	if key == nil {
+		return nil, errors.ErrVAPIDKeyNotFound
+	}
+
+	s.mu.Lock()
+	s.cache[id] = key
+	s.mu.Unlock()
+	return key, nil
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
-	"crypto/ecdsa"
+	"bytes"
+	"crypto/ecdh"
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	webpush "github.com/SherClockHolmes/webpush-go"
 )
@@ -40,32 +42,48 @@ func (vs *VAPIDService) GetPrivateKey() string {
 	return vs.privateKey
 }
 
+// ValidateKeyPair checks that the private key is a valid P-256 scalar and
+// that its public point is the configured public key.
 func (vs *VAPIDService) ValidateKeyPair() error {
-	_, err := vs.parsePrivateKey()
+	privateKey, err := vs.parsePrivateKey()
 	if err != nil {
 		return fmt.Errorf("invalid private key: %w", err)
 	}
 
-	_, err = base64.RawURLEncoding.DecodeString(vs.publicKey)
+	publicKeyBytes, err := decodeVAPIDKey(vs.publicKey)
 	if err != nil {
 		return fmt.Errorf("invalid public key format: %w", err)
 	}
+	if _, err := ecdh.P256().NewPublicKey(publicKeyBytes); err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	if !bytes.Equal(privateKey.PublicKey().Bytes(), publicKeyBytes) {
+		return fmt.Errorf("public key does not match private key")
+	}
 
 	return nil
 }
 
-func (vs *VAPIDService) parsePrivateKey() (*ecdsa.PrivateKey, error) {
-	privateKeyBytes, err := base64.RawURLEncoding.DecodeString(vs.privateKey)
+func (vs *VAPIDService) parsePrivateKey() (*ecdh.PrivateKey, error) {
+	privateKeyBytes, err := decodeVAPIDKey(vs.privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode private key: %w", err)
 	}
 
-	// Simple validation - just check if we can decode the key
 	if len(privateKeyBytes) == 0 {
 		return nil, fmt.Errorf("private key is empty")
 	}
 
-	// For now, we'll skip the actual parsing since webpush.ParsePrivateKey doesn't exist
-	// The VAPID keys are already generated by webpush.GenerateVAPIDKeys() so they should be valid
-	return nil, nil
+	privateKey, err := ecdh.P256().NewPrivateKey(privateKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse P-256 private key: %w", err)
+	}
+	return privateKey, nil
+}
+
+// decodeVAPIDKey accepts the base64url encodings webpush-go accepts, with
+// or without padding.
+func decodeVAPIDKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
 }
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateKeyPair(t *testing.T) {
+	pair, err := NewVAPIDService()
+	if err != nil {
+		t.Fatalf("NewVAPIDService: %v", err)
+	}
+	other, err := NewVAPIDService()
+	if err != nil {
+		t.Fatalf("NewVAPIDService: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		privateKey string
+		publicKey  string
+		wantErr    string
+	}{
+		{"generated pair", pair.GetPrivateKey(), pair.GetPublicKey(), ""},
+		{"padded encoding", pair.GetPrivateKey() + "=", pair.GetPublicKey() + "=", ""},
+		{"mismatched public key", pair.GetPrivateKey(), other.GetPublicKey(), "does not match"},
+		{"private key not base64", "not base64!", pair.GetPublicKey(), "failed to decode private key"},
+		{"empty private key", "", pair.GetPublicKey(), "private key is empty"},
+		{"private key wrong length", "AAAA", pair.GetPublicKey(), "failed to parse P-256 private key"},
+		{"private key out of range", strings.Repeat("_", 43), pair.GetPublicKey(), "failed to parse P-256 private key"},
+		{"public key not base64", pair.GetPrivateKey(), "%%%", "invalid public key format"},
+		{"public key not on curve", pair.GetPrivateKey(), "BA" + strings.Repeat("A", 85), "invalid public key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewVAPIDServiceWithKeys(tt.privateKey, tt.publicKey).ValidateKeyPair()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateKeyPair: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateKeyPair error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
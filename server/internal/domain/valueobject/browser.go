@@ -0,0 +1,52 @@
+package valueobject
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Browser is the browser family a push subscription was created from,
+// derived from its User-Agent.
+type Browser string
+
+const (
+	BrowserChrome  Browser = "chrome"
+	BrowserEdge    Browser = "edge"
+	BrowserFirefox Browser = "firefox"
+	BrowserSafari  Browser = "safari"
+	BrowserOpera   Browser = "opera"
+	BrowserOther   Browser = "other"
+)
+
+func NewBrowser(value string) (Browser, error) {
+	browser := Browser(strings.ToLower(strings.TrimSpace(value)))
+	switch browser {
+	case BrowserChrome, BrowserEdge, BrowserFirefox, BrowserSafari, BrowserOpera, BrowserOther:
+		return browser, nil
+	default:
+		return "", fmt.Errorf("unknown browser: %s", value)
+	}
+}
+
+// BrowserFromUserAgent classifies a User-Agent string. Chromium based
+// browsers announce Chrome and Safari too, so they are checked first.
+func BrowserFromUserAgent(userAgent string) Browser {
+	switch {
+	case strings.Contains(userAgent, "Edg/"), strings.Contains(userAgent, "EdgA/"), strings.Contains(userAgent, "EdgiOS/"):
+		return BrowserEdge
+	case strings.Contains(userAgent, "OPR/"), strings.Contains(userAgent, "Opera"):
+		return BrowserOpera
+	case strings.Contains(userAgent, "Firefox/"), strings.Contains(userAgent, "FxiOS/"):
+		return BrowserFirefox
+	case strings.Contains(userAgent, "Chrome/"), strings.Contains(userAgent, "CriOS/"), strings.Contains(userAgent, "Chromium/"):
+		return BrowserChrome
+	case strings.Contains(userAgent, "Safari/"):
+		return BrowserSafari
+	default:
+		return BrowserOther
+	}
+}
+
+func (b Browser) String() string {
+	return string(b)
+}
@@ -0,0 +1,32 @@
+package valueobject
+
+import "testing"
+
+func TestBrowserFromUserAgent(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      Browser
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", BrowserChrome},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.2592.87", BrowserEdge},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.5; rv:127.0) Gecko/20100101 Firefox/127.0", BrowserFirefox},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", BrowserSafari},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 OPR/111.0.0.0", BrowserOpera},
+		{"", BrowserOther},
+	}
+
+	for _, tt := range tests {
+		if got := BrowserFromUserAgent(tt.userAgent); got != tt.want {
+			t.Errorf("BrowserFromUserAgent(%q) = %s, want %s", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestNewBrowser(t *testing.T) {
+	if browser, err := NewBrowser(" Firefox "); err != nil || browser != BrowserFirefox {
+		t.Errorf("NewBrowser(Firefox) = %q, %v", browser, err)
+	}
+	if _, err := NewBrowser("netscape"); err == nil {
+		t.Error("NewBrowser(netscape) succeeded, want error")
+	}
+}
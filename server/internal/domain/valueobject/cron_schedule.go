@@ -0,0 +1,67 @@
+package valueobject
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// MinScheduleInterval is the shortest period a recurring schedule may have.
+const MinScheduleInterval = time.Minute
+
+// CronSchedule is a standard five-field cron expression (or a descriptor
+// such as @daily) evaluated in a timezone, so "0 9 * * *" in Asia/Tokyo
+// fires at 09:00 Tokyo time regardless of where the server runs.
+type CronSchedule struct {
+	expression string
+	location   *time.Location
+	schedule   cron.Schedule
+}
+
+func NewCronSchedule(expression, timezone string) (CronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return CronSchedule{}, fmt.Errorf("cron expression cannot be empty")
+	}
+	if strings.HasPrefix(expression, "TZ=") || strings.HasPrefix(expression, "CRON_TZ=") {
+		return CronSchedule{}, fmt.Errorf("set the timezone separately instead of in the cron expression")
+	}
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return CronSchedule{}, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	schedule, err := cron.ParseStandard("CRON_TZ=" + location.String() + " " + expression)
+	if err != nil {
+		return CronSchedule{}, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok && every.Delay < MinScheduleInterval {
+		return CronSchedule{}, fmt.Errorf("schedules cannot run more often than every %s", MinScheduleInterval)
+	}
+
+	return CronSchedule{expression: expression, location: location, schedule: schedule}, nil
+}
+
+func (c CronSchedule) Expression() string {
+	return c.expression
+}
+
+func (c CronSchedule) Timezone() string {
+	return c.location.String()
+}
+
+// Next returns the first occurrence strictly after t, or the zero time if
+// the expression never fires again (e.g. 30 February).
+func (c CronSchedule) Next(t time.Time) time.Time {
+	return c.schedule.Next(t)
+}
+
+func (c CronSchedule) IsZero() bool {
+	return c.schedule == nil
+}
@@ -0,0 +1,52 @@
+package valueobject
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name       string
+		expression string
+		timezone   string
+		after      time.Time
+		want       time.Time
+	}{
+		{"daily in tokyo", "0 9 * * *", "Asia/Tokyo", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 9, 0, 0, 0, tokyo)},
+		{"same day", "0 9 * * *", "Asia/Tokyo", time.Date(2026, 3, 1, 8, 59, 0, 0, tokyo), time.Date(2026, 3, 1, 9, 0, 0, 0, tokyo)},
+		{"strictly after", "0 9 * * *", "Asia/Tokyo", time.Date(2026, 3, 1, 9, 0, 0, 0, tokyo), time.Date(2026, 3, 2, 9, 0, 0, 0, tokyo)},
+		{"weekly on monday", "30 8 * * MON", "UTC", time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 8, 30, 0, 0, time.UTC)},
+		{"descriptor", "@daily", "", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{"across dst", "0 9 * * *", "America/New_York", time.Date(2026, 3, 7, 15, 0, 0, 0, time.UTC), time.Date(2026, 3, 8, 9, 0, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := NewCronSchedule(tt.expression, tt.timezone)
+			if err != nil {
+				t.Fatalf("NewCronSchedule: %v", err)
+			}
+			if got := schedule.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCronScheduleInvalid(t *testing.T) {
+	for _, tt := range []struct{ expression, timezone string }{
+		{"", "UTC"},
+		{"0 9 * *", "UTC"},
+		{"0 25 * * *", "UTC"},
+		{"0 9 * * *", "Mars/Olympus"},
+		{"CRON_TZ=Asia/Tokyo 0 9 * * *", ""},
+		{"@every 10s", "UTC"},
+	} {
+		if _, err := NewCronSchedule(tt.expression, tt.timezone); err == nil {
+			t.Errorf("NewCronSchedule(%q, %q) succeeded", tt.expression, tt.timezone)
+		}
+	}
+}
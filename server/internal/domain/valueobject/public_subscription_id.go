@@ -0,0 +1,46 @@
+package valueobject
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const publicSubscriptionIDPrefix = "sub_"
+
+// PublicSubscriptionID is the identifier handed to browsers and API clients.
+// Unlike the sequential SubscriptionID it carries 128 random bits, so IDs of
+// other people's subscriptions cannot be guessed.
+type PublicSubscriptionID struct {
+	value string
+}
+
+func NewPublicSubscriptionID() PublicSubscriptionID {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return PublicSubscriptionID{value: publicSubscriptionIDPrefix + hex.EncodeToString(buf)}
+}
+
+func PublicSubscriptionIDFromString(s string) (PublicSubscriptionID, error) {
+	raw, ok := strings.CutPrefix(s, publicSubscriptionIDPrefix)
+	if !ok || len(raw) != 32 {
+		return PublicSubscriptionID{}, fmt.Errorf("invalid subscription ID format")
+	}
+	if _, err := hex.DecodeString(raw); err != nil || strings.ToLower(raw) != raw {
+		return PublicSubscriptionID{}, fmt.Errorf("invalid subscription ID format")
+	}
+	return PublicSubscriptionID{value: s}, nil
+}
+
+func (id PublicSubscriptionID) Value() string {
+	return id.value
+}
+
+func (id PublicSubscriptionID) String() string {
+	return id.value
+}
+
+func (id PublicSubscriptionID) Equals(other PublicSubscriptionID) bool {
+	return id.value == other.value
+}
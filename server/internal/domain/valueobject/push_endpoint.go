@@ -54,6 +54,15 @@ func (e PushEndpoint) Value() string {
 	return e.value
 }
 
+// Host is the push service the endpoint belongs to, e.g. fcm.googleapis.com.
+func (e PushEndpoint) Host() string {
+	parsed, err := url.Parse(e.value)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
+
 func (e PushEndpoint) String() string {
 	return e.value
 }
@@ -0,0 +1,86 @@
+package valueobject
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuietHours is a daily window, in the user's timezone, during which pushes
+// are held back. The window may wrap past midnight (22:00-07:00).
+type QuietHours struct {
+	start    int // minutes after midnight
+	end      int
+	location *time.Location
+}
+
+func NewQuietHours(start, end, timezone string) (QuietHours, error) {
+	startMinutes, err := parseClock(start)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+	endMinutes, err := parseClock(end)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+	if startMinutes == endMinutes {
+		return QuietHours{}, fmt.Errorf("quiet hours start and end must differ")
+	}
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	return QuietHours{start: startMinutes, end: endMinutes, location: location}, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not in HH:MM format", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (q QuietHours) Start() string {
+	return formatClock(q.start)
+}
+
+func (q QuietHours) End() string {
+	return formatClock(q.end)
+}
+
+func (q QuietHours) Timezone() string {
+	return q.location.String()
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// WindowEnd reports whether t falls inside the quiet window and, if so,
+// when the window ends.
+func (q QuietHours) WindowEnd(t time.Time) (time.Time, bool) {
+	local := t.In(q.location)
+	minutes := local.Hour()*60 + local.Minute()
+	year, month, day := local.Date()
+
+	if q.start < q.end {
+		if minutes < q.start || minutes >= q.end {
+			return time.Time{}, false
+		}
+	} else {
+		switch {
+		case minutes >= q.start:
+			day++ // the window ends tomorrow
+		case minutes < q.end:
+		default:
+			return time.Time{}, false
+		}
+	}
+
+	return time.Date(year, month, day, q.end/60, q.end%60, 0, 0, q.location), true
+}
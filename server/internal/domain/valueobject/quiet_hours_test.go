@@ -0,0 +1,52 @@
+package valueobject
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursWindowEnd(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	overnight, err := NewQuietHours("22:00", "07:30", "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("NewQuietHours: %v", err)
+	}
+	daytime, _ := NewQuietHours("12:00", "13:00", "Asia/Tokyo")
+
+	tests := []struct {
+		name    string
+		hours   QuietHours
+		at      time.Time
+		want    time.Time
+		inQuiet bool
+	}{
+		{"before overnight window", overnight, time.Date(2026, 3, 1, 21, 59, 0, 0, tokyo), time.Time{}, false},
+		{"evening part", overnight, time.Date(2026, 3, 1, 23, 0, 0, 0, tokyo), time.Date(2026, 3, 2, 7, 30, 0, 0, tokyo), true},
+		{"morning part", overnight, time.Date(2026, 3, 2, 6, 0, 0, 0, tokyo), time.Date(2026, 3, 2, 7, 30, 0, 0, tokyo), true},
+		{"end is exclusive", overnight, time.Date(2026, 3, 2, 7, 30, 0, 0, tokyo), time.Time{}, false},
+		{"utc input", overnight, time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 7, 30, 0, 0, tokyo), true},
+		{"daytime window", daytime, time.Date(2026, 3, 1, 12, 15, 0, 0, tokyo), time.Date(2026, 3, 1, 13, 0, 0, 0, tokyo), true},
+		{"after daytime window", daytime, time.Date(2026, 3, 1, 13, 15, 0, 0, tokyo), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, inQuiet := tt.hours.WindowEnd(tt.at)
+			if inQuiet != tt.inQuiet || !got.Equal(tt.want) {
+				t.Errorf("WindowEnd(%v) = %v, %v; want %v, %v", tt.at, got, inQuiet, tt.want, tt.inQuiet)
+			}
+		})
+	}
+}
+
+func TestNewQuietHoursInvalid(t *testing.T) {
+	for _, tt := range []struct{ start, end, tz string }{
+		{"25:00", "07:00", "UTC"},
+		{"22:00", "7", "UTC"},
+		{"22:00", "22:00", "UTC"},
+		{"22:00", "07:00", "Mars/Olympus"},
+	} {
+		if _, err := NewQuietHours(tt.start, tt.end, tt.tz); err == nil {
+			t.Errorf("NewQuietHours(%q, %q, %q) succeeded", tt.start, tt.end, tt.tz)
+		}
+	}
+}
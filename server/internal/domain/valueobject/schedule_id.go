@@ -0,0 +1,37 @@
+package valueobject
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type ScheduleID struct {
+	value int64
+}
+
+func NewScheduleID(value int64) (ScheduleID, error) {
+	if value <= 0 {
+		return ScheduleID{}, fmt.Errorf("schedule ID must be positive")
+	}
+	return ScheduleID{value: value}, nil
+}
+
+func ScheduleIDFromString(s string) (ScheduleID, error) {
+	value, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return ScheduleID{}, fmt.Errorf("invalid schedule ID format: %w", err)
+	}
+	return NewScheduleID(value)
+}
+
+func (id ScheduleID) Value() int64 {
+	return id.value
+}
+
+func (id ScheduleID) String() string {
+	return strconv.FormatInt(id.value, 10)
+}
+
+func (id ScheduleID) Equals(other ScheduleID) bool {
+	return id.value == other.value
+}
@@ -0,0 +1,63 @@
+package valueobject
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// VAPIDSubject is the "sub" claim of the VAPID JWT: a mailto: or https: URI
+// the push service can use to contact the application server operator
+// (RFC 8292 section 2.1).
+type VAPIDSubject struct {
+	value string
+}
+
+func NewVAPIDSubject(value string) (VAPIDSubject, error) {
+	if value == "" {
+		return VAPIDSubject{}, fmt.Errorf("VAPID subject cannot be empty")
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return VAPIDSubject{}, fmt.Errorf("invalid VAPID subject: %w", err)
+	}
+
+	switch parsed.Scheme {
+	case "mailto":
+		address := parsed.Opaque
+		if address == "" {
+			return VAPIDSubject{}, fmt.Errorf("VAPID subject mailto: URI has no address")
+		}
+		if _, err := mail.ParseAddress(address); err != nil || !emailRegex.MatchString(address) {
+			return VAPIDSubject{}, fmt.Errorf("invalid VAPID subject email address: %s", address)
+		}
+	case "https":
+		if parsed.Host == "" || parsed.User != nil {
+			return VAPIDSubject{}, fmt.Errorf("VAPID subject must be an absolute https: URL without credentials")
+		}
+	default:
+		return VAPIDSubject{}, fmt.Errorf("VAPID subject must be a mailto: or https: URI, got %q", value)
+	}
+
+	return VAPIDSubject{value: value}, nil
+}
+
+func (s VAPIDSubject) Value() string {
+	return s.value
+}
+
+func (s VAPIDSubject) String() string {
+	return s.value
+}
+
+func (s VAPIDSubject) IsZero() bool {
+	return s.value == ""
+}
+
+// Subscriber returns the subject in the form webpush-go expects, which adds
+// the "mailto:" prefix itself unless the value is an https: URL.
+func (s VAPIDSubject) Subscriber() string {
+	return strings.TrimPrefix(s.value, "mailto:")
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// Delivery queue backends for PUSH_DELIVERY_QUEUE.
+const (
+	DeliveryQueueMemory = "memory"
+	DeliveryQueueRedis  = "redis"
+)
+
+type Config struct {
+	Port           string
+	DatabaseURL    string
+	MigrateOnStart bool
+	// ShutdownTimeout bounds graceful shutdown after SIGINT/SIGTERM: HTTP
+	// requests in flight and the background loops must finish within it.
+	ShutdownTimeout time.Duration
+
+	// Push delivery fan-out
+	SenderWorkers            int
+	SenderPerHostConcurrency int
+	SenderJobConcurrency     int
+	SenderLeaseDuration      time.Duration
+	MaxDeliveryAttempts      int
+	// SenderDrainTimeout is how long deliveries in flight at shutdown may
+	// finish; keep it below ShutdownTimeout so unfinished jobs are released.
+	SenderDrainTimeout time.Duration
+
+	// Retry backoff and push service throttling
+	BackoffBase   time.Duration
+	BackoffMax    time.Duration
+	BackoffJitter float64
+	ThrottleDelay time.Duration
+	MaxRetryAfter time.Duration
+
+	// DeliveryQueue selects how due deliveries reach the send workers:
+	// empty sends them while the job is claimed, DeliveryQueueMemory and
+	// DeliveryQueueRedis (a Redis stream at RedisURL) enqueue one message
+	// per subscription. Messages a worker has not acknowledged within
+	// QueueVisibilityTimeout are redelivered; deliveries still unsent after
+	// QueueHold are enqueued again in case the queue lost them.
+	DeliveryQueue          string
+	RedisURL               string
+	QueueVisibilityTimeout time.Duration
+	QueueHold              time.Duration
+
+	// ScheduleMisfireGrace is how late a recurring schedule's occurrence may
+	// still spawn its job, e.g. after every replica was down.
+	ScheduleMisfireGrace time.Duration
+
+	// Maintenance: how often expired and old data is removed, and how many
+	// days logs, completed jobs and invalid subscriptions are kept.
+	MaintenanceInterval              time.Duration
+	LogRetentionDays                 int
+	JobRetentionDays                 int
+	InvalidSubscriptionRetentionDays int
+
+	// VAPID keys. A configured key pair is imported into the key store at
+	// startup; VAPIDKeyFile persists keys when DATABASE_URL is not set.
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	VAPIDKeyFile    string
+	// VAPIDSubject is the operator contact (mailto: or https:) sent to push
+	// services. Required to run the server; keys may override it.
+	VAPIDSubject valueobject.VAPIDSubject
+
+	// AdminAPIToken is accepted as an admin-scoped API key, so the first
+	// stored key can be created over the API. Empty disables it.
+	AdminAPIToken string
+	// AuthJWTSecret verifies HS256 user session tokens. User-authenticated
+	// endpoints reject every token while it is empty.
+	AuthJWTSecret string
+}
+
+func Load() (*Config, error) {
+	migrateOnStart, err := getEnvBool("MIGRATE_ON_START", false)
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownTimeout, err := getEnvDuration("SHUTDOWN_TIMEOUT", 25*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	senderWorkers, err := getEnvInt("PUSH_SENDER_WORKERS", 32)
+	if err != nil {
+		return nil, err
+	}
+	senderPerHost, err := getEnvInt("PUSH_SENDER_PER_HOST_CONCURRENCY", 16)
+	if err != nil {
+		return nil, err
+	}
+	senderJobs, err := getEnvInt("PUSH_SENDER_JOB_CONCURRENCY", 4)
+	if err != nil {
+		return nil, err
+	}
+	senderLease, err := getEnvDuration("PUSH_JOB_LEASE_DURATION", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDeliveryAttempts, err := getEnvInt("PUSH_MAX_DELIVERY_ATTEMPTS", 5)
+	if err != nil {
+		return nil, err
+	}
+	senderDrain, err := getEnvDuration("PUSH_DRAIN_TIMEOUT", 20*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	backoffBase, err := getEnvDuration("PUSH_BACKOFF_BASE", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	backoffMax, err := getEnvDuration("PUSH_BACKOFF_MAX", 6*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	backoffJitter, err := getEnvFraction("PUSH_BACKOFF_JITTER", 0.2)
+	if err != nil {
+		return nil, err
+	}
+	throttleDelay, err := getEnvDuration("PUSH_THROTTLE_DELAY", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	maxRetryAfter, err := getEnvDuration("PUSH_MAX_RETRY_AFTER", 6*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryQueue := os.Getenv("PUSH_DELIVERY_QUEUE")
+	switch deliveryQueue {
+	case "", DeliveryQueueMemory:
+	case DeliveryQueueRedis:
+		if os.Getenv("REDIS_URL") == "" {
+			return nil, fmt.Errorf("REDIS_URL is required when PUSH_DELIVERY_QUEUE is %s", DeliveryQueueRedis)
+		}
+	default:
+		return nil, fmt.Errorf("invalid PUSH_DELIVERY_QUEUE %q: use %s or %s", deliveryQueue, DeliveryQueueMemory, DeliveryQueueRedis)
+	}
+	queueVisibility, err := getEnvDuration("PUSH_QUEUE_VISIBILITY_TIMEOUT", 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	queueHold, err := getEnvDuration("PUSH_QUEUE_HOLD", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if queueHold <= queueVisibility {
+		// Otherwise a job round enqueues deliveries again while the queue
+		// still holds their messages.
+		return nil, fmt.Errorf("PUSH_QUEUE_HOLD (%s) must be longer than PUSH_QUEUE_VISIBILITY_TIMEOUT (%s)", queueHold, queueVisibility)
+	}
+
+	misfireGrace, err := getEnvDuration("PUSH_SCHEDULE_MISFIRE_GRACE", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	maintenanceInterval, err := getEnvDuration("PUSH_MAINTENANCE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	logRetentionDays, err := getEnvInt("PUSH_LOG_RETENTION_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	jobRetentionDays, err := getEnvInt("PUSH_JOB_RETENTION_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	invalidSubscriptionRetentionDays, err := getEnvInt("PUSH_INVALID_SUBSCRIPTION_RETENTION_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		Port:           getEnv("PORT", "8080"),
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		MigrateOnStart: migrateOnStart,
+
+		ShutdownTimeout: shutdownTimeout,
+
+		SenderWorkers:            senderWorkers,
+		SenderPerHostConcurrency: senderPerHost,
+		SenderJobConcurrency:     senderJobs,
+		SenderLeaseDuration:      senderLease,
+		MaxDeliveryAttempts:      maxDeliveryAttempts,
+		SenderDrainTimeout:       senderDrain,
+
+		BackoffBase:   backoffBase,
+		BackoffMax:    backoffMax,
+		BackoffJitter: backoffJitter,
+		ThrottleDelay: throttleDelay,
+		MaxRetryAfter: maxRetryAfter,
+
+		DeliveryQueue:          deliveryQueue,
+		RedisURL:               os.Getenv("REDIS_URL"),
+		QueueVisibilityTimeout: queueVisibility,
+		QueueHold:              queueHold,
+
+		ScheduleMisfireGrace: misfireGrace,
+
+		MaintenanceInterval:              maintenanceInterval,
+		LogRetentionDays:                 logRetentionDays,
+		JobRetentionDays:                 jobRetentionDays,
+		InvalidSubscriptionRetentionDays: invalidSubscriptionRetentionDays,
+
+		VAPIDPublicKey:  os.Getenv("VAPID_PUBLIC_KEY"),
+		VAPIDPrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
+		VAPIDKeyFile:    os.Getenv("VAPID_KEY_FILE"),
+
+		AdminAPIToken: os.Getenv("ADMIN_API_TOKEN"),
+		AuthJWTSecret: os.Getenv("AUTH_JWT_SECRET"),
+	}
+
+	if subject := os.Getenv("VAPID_SUBJECT"); subject != "" {
+		cfg.VAPIDSubject, err = valueobject.NewVAPIDSubject(subject)
+		if err != nil {
+			return nil, fmt.Errorf("invalid VAPID_SUBJECT: %w", err)
+		}
+	}
+
+	if (cfg.VAPIDPublicKey == "") != (cfg.VAPIDPrivateKey == "") {
+		return nil, fmt.Errorf("VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY must be set together")
+	}
+
+	return cfg, nil
+}
+
+// UsePostgres reports whether a DATABASE_URL was provided. Without it the
+// server falls back to the in-memory repositories.
+func (c *Config) UsePostgres() bool {
+	return c.DatabaseURL != ""
+}
+
+// HasVAPIDKeyPair reports whether a VAPID key pair was configured.
+func (c *Config) HasVAPIDKeyPair() bool {
+	return c.VAPIDPublicKey != ""
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if parsed <= 0 {
+		return 0, fmt.Errorf("%s must be positive, got %d", key, parsed)
+	}
+	return parsed, nil
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if parsed <= 0 {
+		return 0, fmt.Errorf("%s must be positive, got %s", key, parsed)
+	}
+	return parsed, nil
+}
+
+// getEnvFraction reads a number between 0 and 1 inclusive.
+func getEnvFraction(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if parsed < 0 || parsed > 1 {
+		return 0, fmt.Errorf("%s must be between 0 and 1, got %v", key, parsed)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,120 @@
+package messaging
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/queue"
+)
+
+type inFlightMessage struct {
+	message  queue.Message
+	deadline time.Time
+}
+
+// MemoryQueue implements queue.Queue within a single process. Messages are
+// lost when the process exits, along with the in-memory repositories they
+// refer to.
+type MemoryQueue struct {
+	mu                sync.Mutex
+	visibilityTimeout time.Duration
+	ready             []queue.Message
+	inFlight          map[string]inFlightMessage
+	nextReceipt       int64
+	// wake is signalled when messages are enqueued.
+	wake chan struct{}
+}
+
+func NewMemoryQueue(visibilityTimeout time.Duration) *MemoryQueue {
+	return &MemoryQueue{
+		visibilityTimeout: visibilityTimeout,
+		inFlight:          make(map[string]inFlightMessage),
+		wake:              make(chan struct{}, 1),
+	}
+}
+
+func (q *MemoryQueue) Enqueue(ctx context.Context, messages ...queue.Message) error {
+	q.mu.Lock()
+	q.ready = append(q.ready, messages...)
+	q.mu.Unlock()
+
+	q.signal()
+	return nil
+}
+
+func (q *MemoryQueue) Receive(ctx context.Context, max int) ([]queue.Received, error) {
+	for {
+		received, wait := q.take(max, time.Now())
+		if len(received) > 0 {
+			return received, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-q.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// take hands out up to max messages, redelivering those whose visibility
+// timeout passed first. When there are none it returns how long to wait for
+// the next timeout.
+func (q *MemoryQueue) take(max int, now time.Time) ([]queue.Received, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	wait := q.visibilityTimeout
+	var received []queue.Received
+	for receipt, m := range q.inFlight {
+		if len(received) == max {
+			break
+		}
+		if now.Before(m.deadline) {
+			wait = min(wait, m.deadline.Sub(now))
+			continue
+		}
+		delete(q.inFlight, receipt)
+		received = append(received, q.handOut(m.message, now))
+	}
+
+	for len(received) < max && len(q.ready) > 0 {
+		received = append(received, q.handOut(q.ready[0], now))
+		q.ready = q.ready[1:]
+	}
+	if len(q.ready) > 0 {
+		// Pass the wake-up on to another receiver.
+		q.signal()
+	}
+	return received, wait
+}
+
+func (q *MemoryQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *MemoryQueue) handOut(message queue.Message, now time.Time) queue.Received {
+	q.nextReceipt++
+	receipt := strconv.FormatInt(q.nextReceipt, 10)
+	q.inFlight[receipt] = inFlightMessage{message: message, deadline: now.Add(q.visibilityTimeout)}
+	return queue.Received{Message: message, Receipt: receipt}
+}
+
+func (q *MemoryQueue) Ack(ctx context.Context, receipts ...string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, receipt := range receipts {
+		delete(q.inFlight, receipt)
+	}
+	return nil
+}
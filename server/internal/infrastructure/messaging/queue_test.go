@@ -0,0 +1,103 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/queue"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+func testMessage(t *testing.T, n int64) queue.Message {
+	t.Helper()
+	jobID, _ := valueobject.NewJobID(n)
+	subscriptionID, _ := valueobject.NewSubscriptionID(n * 10)
+	return queue.Message{JobID: jobID, DeliveryID: n * 100, SubscriptionID: subscriptionID}
+}
+
+func receive(t *testing.T, q queue.Queue, max int, timeout time.Duration) []queue.Received {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	received, err := q.Receive(ctx, max)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	return received
+}
+
+// testQueueContract checks hand-out order, acknowledgement and redelivery of
+// unacknowledged messages. wait bounds how long a redelivery may take.
+func testQueueContract(t *testing.T, q queue.Queue, wait time.Duration) {
+	ctx := context.Background()
+	if err := q.Enqueue(ctx, testMessage(t, 1), testMessage(t, 2), testMessage(t, 3)); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	first := receive(t, q, 2, wait)
+	if len(first) != 2 || first[0].Message != testMessage(t, 1) || first[1].Message != testMessage(t, 2) {
+		t.Fatalf("first Receive = %+v", first)
+	}
+	second := receive(t, q, 2, wait)
+	if len(second) != 1 || second[0].Message != testMessage(t, 3) {
+		t.Fatalf("second Receive = %+v", second)
+	}
+
+	// The worker holding message 2 "crashes" without acknowledging it.
+	if err := q.Ack(ctx, first[0].Receipt, second[0].Receipt); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	redelivered := receive(t, q, 10, wait)
+	if len(redelivered) != 1 || redelivered[0].Message != testMessage(t, 2) {
+		t.Fatalf("redelivered = %+v", redelivered)
+	}
+	if err := q.Ack(ctx, redelivered[0].Receipt); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	drained, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	if received, err := q.Receive(drained, 10); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Receive after everything was acknowledged = %+v, %v", received, err)
+	}
+}
+
+func TestMemoryQueue(t *testing.T) {
+	testQueueContract(t, NewMemoryQueue(100*time.Millisecond), time.Second)
+}
+
+// TestRedisStreamQueue runs against TEST_REDIS_URL, e.g.
+//
+//	TEST_REDIS_URL=redis://localhost:6379/15 go test ./internal/infrastructure/messaging/
+func TestRedisStreamQueue(t *testing.T) {
+	redisURL := os.Getenv("TEST_REDIS_URL")
+	if redisURL == "" {
+		t.Skip("TEST_REDIS_URL is not set")
+	}
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		t.Fatalf("ParseURL: %v", err)
+	}
+	client := redis.NewClient(options)
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	stream := fmt.Sprintf("push-deliveries-test-%d", time.Now().UnixNano())
+	t.Cleanup(func() { client.Del(ctx, stream) })
+
+	worker, err := NewRedisStreamQueue(ctx, client, stream, "senders", "worker-1", 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewRedisStreamQueue: %v", err)
+	}
+	// A second queue on the same group must reuse it.
+	if _, err := NewRedisStreamQueue(ctx, client, stream, "senders", "worker-2", 500*time.Millisecond); err != nil {
+		t.Fatalf("NewRedisStreamQueue on an existing group: %v", err)
+	}
+	testQueueContract(t, worker, 2*readBlock)
+}
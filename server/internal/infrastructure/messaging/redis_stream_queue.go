@@ -0,0 +1,204 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/queue"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// readBlock bounds each blocking read so that messages abandoned by crashed
+// workers are reclaimed even while no new ones arrive. It is also how long a
+// read in progress may hold up shutdown.
+const readBlock = 5 * time.Second
+
+// RedisStreamQueue implements queue.Queue with a Redis stream read through a
+// consumer group. Every worker reads as its own consumer; messages left
+// unacknowledged in another consumer's pending list for longer than the
+// visibility timeout are claimed and delivered again.
+type RedisStreamQueue struct {
+	client            *redis.Client
+	stream            string
+	group             string
+	consumer          string
+	visibilityTimeout time.Duration
+
+	mu sync.Mutex
+	// claimCursor is where the next scan for abandoned messages starts, so
+	// long pending lists are scanned a part at a time.
+	claimCursor string
+}
+
+// NewRedisStreamQueue creates the stream and consumer group if they do not
+// exist yet.
+func NewRedisStreamQueue(ctx context.Context, client *redis.Client, stream, group, consumer string, visibilityTimeout time.Duration) (*RedisStreamQueue, error) {
+	err := client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	return &RedisStreamQueue{
+		client:            client,
+		stream:            stream,
+		group:             group,
+		consumer:          consumer,
+		visibilityTimeout: visibilityTimeout,
+		claimCursor:       "0-0",
+	}, nil
+}
+
+func (q *RedisStreamQueue) Enqueue(ctx context.Context, messages ...queue.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	_, err := q.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, message := range messages {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: q.stream,
+				Values: map[string]any{
+					"job":          message.JobID.Value(),
+					"delivery":     message.DeliveryID,
+					"subscription": message.SubscriptionID.Value(),
+				},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue deliveries: %w", err)
+	}
+	return nil
+}
+
+func (q *RedisStreamQueue) Receive(ctx context.Context, max int) ([]queue.Received, error) {
+	for {
+		claimed, err := q.claimAbandoned(ctx, max)
+		if err != nil {
+			return nil, q.receiveError(ctx, err)
+		}
+		if received := q.decode(claimed); len(received) > 0 {
+			return received, nil
+		}
+
+		streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.group,
+			Consumer: q.consumer,
+			Streams:  []string{q.stream, ">"},
+			Count:    int64(max),
+			Block:    readBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, q.receiveError(ctx, err)
+		}
+		for _, stream := range streams {
+			if received := q.decode(stream.Messages); len(received) > 0 {
+				return received, nil
+			}
+		}
+	}
+}
+
+// claimAbandoned takes over messages that have been pending longer than the
+// visibility timeout.
+func (q *RedisStreamQueue) claimAbandoned(ctx context.Context, max int) ([]redis.XMessage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	claimed, next, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.stream,
+		Group:    q.group,
+		Consumer: q.consumer,
+		MinIdle:  q.visibilityTimeout,
+		Start:    q.claimCursor,
+		Count:    int64(max),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	q.claimCursor = next
+	return claimed, nil
+}
+
+func (q *RedisStreamQueue) receiveError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("failed to receive deliveries: %w", err)
+}
+
+// decode converts stream entries to messages. Malformed entries are
+// acknowledged and dropped, since they would fail the same way every time.
+func (q *RedisStreamQueue) decode(entries []redis.XMessage) []queue.Received {
+	received := make([]queue.Received, 0, len(entries))
+	for _, entry := range entries {
+		message, err := decodeMessage(entry.Values)
+		if err != nil {
+			log.Printf("Dropping malformed queue entry %s: %v", entry.ID, err)
+			if err := q.Ack(context.Background(), entry.ID); err != nil {
+				log.Printf("Failed to drop queue entry %s: %v", entry.ID, err)
+			}
+			continue
+		}
+		received = append(received, queue.Received{Message: message, Receipt: entry.ID})
+	}
+	return received
+}
+
+func decodeMessage(values map[string]any) (queue.Message, error) {
+	field := func(name string) (int64, error) {
+		s, _ := values[name].(string)
+		return strconv.ParseInt(s, 10, 64)
+	}
+
+	job, err := field("job")
+	if err != nil {
+		return queue.Message{}, fmt.Errorf("invalid job ID: %w", err)
+	}
+	jobID, err := valueobject.NewJobID(job)
+	if err != nil {
+		return queue.Message{}, err
+	}
+	delivery, err := field("delivery")
+	if err != nil {
+		return queue.Message{}, fmt.Errorf("invalid delivery ID: %w", err)
+	}
+	subscription, err := field("subscription")
+	if err != nil {
+		return queue.Message{}, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+	subscriptionID, err := valueobject.NewSubscriptionID(subscription)
+	if err != nil {
+		return queue.Message{}, err
+	}
+	return queue.Message{JobID: jobID, DeliveryID: delivery, SubscriptionID: subscriptionID}, nil
+}
+
+// Ack acknowledges the entries and deletes them so the stream does not grow
+// without bound.
+func (q *RedisStreamQueue) Ack(ctx context.Context, receipts ...string) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, q.stream, q.group, receipts...)
+		pipe.XDel(ctx, q.stream, receipts...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge deliveries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_index.up.sql":   {Data: []byte("CREATE INDEX i ON t(c);")},
+		"0002_add_index.down.sql": {Data: []byte("DROP INDEX i;")},
+		"0001_create_t.up.sql":    {Data: []byte("CREATE TABLE t (c INT);")},
+		"0001_create_t.down.sql":  {Data: []byte("DROP TABLE t;")},
+	}
+
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("got %d migrations, want 2", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("migrations not ordered: %d, %d", migrations[0].Version, migrations[1].Version)
+	}
+	if migrations[0].Name != "create_t" || migrations[0].Checksum == "" {
+		t.Fatalf("unexpected migration: %+v", migrations[0])
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down": {
+			"0001_create_t.up.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+		},
+		"bad name": {
+			"create_t.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+		},
+		"conflicting names": {
+			"0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+			"0001_a.down.sql": {Data: []byte("SELECT 1;")},
+			"0001_b.up.sql":   {Data: []byte("SELECT 1;")},
+		},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadMigrations(fsys); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := EmbeddedMigrations()
+	if err != nil {
+		t.Fatalf("EmbeddedMigrations: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Fatalf("unexpected embedded migrations: %+v", migrations)
+	}
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.Exec(ctx, `DROP SCHEMA public CASCADE; CREATE SCHEMA public;`); err != nil {
+		t.Fatalf("failed to reset schema: %v", err)
+	}
+
+	migrator, err := NewMigrator(pool)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != len(migrator.migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(applied), len(migrator.migrations))
+	}
+
+	again, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("second Up: %v", err)
+	}
+	if len(again) != 0 {
+		t.Fatalf("second Up applied %d migrations", len(again))
+	}
+
+	if _, err := pool.Exec(ctx, `UPDATE schema_migrations SET checksum = 'tampered' WHERE version = 1`); err != nil {
+		t.Fatalf("tamper checksum: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, err := pool.Exec(ctx, `UPDATE schema_migrations SET checksum = $1 WHERE version = 1`, migrator.migrations[0].Checksum); err != nil {
+		t.Fatalf("restore checksum: %v", err)
+	}
+
+	reverted, err := migrator.Down(ctx, len(migrator.migrations))
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(reverted) != len(migrator.migrations) {
+		t.Fatalf("reverted %d migrations, want %d", len(reverted), len(migrator.migrations))
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Applied {
+			t.Fatalf("migration %d still applied after Down", s.Version)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS push_logs;
+DROP TABLE IF EXISTS push_jobs;
+DROP TYPE IF EXISTS job_status;
+DROP TABLE IF EXISTS notification_prefs;
+DROP TABLE IF EXISTS notification_templates;
+DROP TABLE IF EXISTS push_subscriptions;
+DROP TABLE IF EXISTS users;
@@ -1,12 +1,14 @@
--- Web Push Notification Database Schema
+-- Initial Web Push notification schema
 -- Based on TODO.md specifications
 
--- Users table (existing - for reference)
--- CREATE TABLE users (
---   id BIGSERIAL PRIMARY KEY,
---   email TEXT UNIQUE,
---   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
--- );
+-- Users table
+CREATE TABLE users (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  email TEXT UNIQUE NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
 
 -- Push subscriptions: Browser subscription data
 CREATE TABLE push_subscriptions (
@@ -88,9 +90,3 @@ CREATE INDEX idx_push_jobs_idempotency ON push_jobs(idempotency_key) WHERE idemp
 CREATE INDEX idx_push_logs_job_id ON push_logs(job_id);
 CREATE INDEX idx_push_logs_subscription_id ON push_logs(subscription_id);
 CREATE INDEX idx_push_logs_created_at ON push_logs(created_at);
-
--- Sample data for testing
-INSERT INTO notification_templates (key, title, body, url, icon) VALUES 
-('welcome', 'Welcome!', 'Welcome to our application!', '/', '/icons/welcome.png'),
-('news', 'News Update', 'Check out the latest news', '/news', '/icons/news.png'),
-('reminder', 'Reminder', 'You have a pending task', '/tasks', '/icons/reminder.png');
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_push_jobs_lease_expires_at;
+
+ALTER TABLE push_jobs
+  DROP COLUMN IF EXISTS lease_expires_at,
+  DROP COLUMN IF EXISTS lease_owner;
@@ -0,0 +1,7 @@
+-- Leases let several sender instances claim push jobs without delivering
+-- the same job twice; an expired lease marks a job abandoned by a crashed sender.
+ALTER TABLE push_jobs
+  ADD COLUMN lease_owner TEXT,
+  ADD COLUMN lease_expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_push_jobs_lease_expires_at ON push_jobs(lease_expires_at) WHERE status = 'sending';
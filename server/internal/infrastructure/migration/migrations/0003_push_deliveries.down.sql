@@ -0,0 +1,3 @@
+ALTER TABLE push_jobs DROP COLUMN IF EXISTS next_attempt_at;
+
+DROP TABLE IF EXISTS push_deliveries;
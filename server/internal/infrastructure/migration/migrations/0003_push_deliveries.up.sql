@@ -0,0 +1,20 @@
+-- Per-(job, subscription) delivery state so retries only target recipients
+-- that have not received the push yet.
+CREATE TABLE push_deliveries (
+  id BIGSERIAL PRIMARY KEY,
+  job_id BIGINT NOT NULL REFERENCES push_jobs(id) ON DELETE CASCADE,
+  subscription_id BIGINT NOT NULL REFERENCES push_subscriptions(id) ON DELETE CASCADE,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','succeeded','failed','gone')),
+  attempt_count INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ,             -- Earliest retry time while pending
+  last_status INT,                         -- Last HTTP status from push service
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (job_id, subscription_id)
+);
+
+CREATE INDEX idx_push_deliveries_pending ON push_deliveries(job_id, next_attempt_at) WHERE status = 'pending';
+
+-- Jobs with deliveries awaiting retry go back to pending until next_attempt_at
+ALTER TABLE push_jobs ADD COLUMN next_attempt_at TIMESTAMPTZ;
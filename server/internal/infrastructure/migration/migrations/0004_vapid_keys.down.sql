@@ -0,0 +1,3 @@
+ALTER TABLE push_subscriptions DROP COLUMN IF EXISTS vapid_key_id;
+
+DROP TABLE IF EXISTS vapid_keys;
@@ -0,0 +1,16 @@
+-- VAPID application server keys. Retired keys are kept so subscriptions
+-- created under them can still be sent to.
+CREATE TABLE vapid_keys (
+  id BIGSERIAL PRIMARY KEY,
+  public_key TEXT NOT NULL UNIQUE,
+  private_key TEXT NOT NULL,
+  is_active BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  activated_at TIMESTAMPTZ
+);
+
+-- At most one key is handed out for new subscriptions
+CREATE UNIQUE INDEX idx_vapid_keys_active ON vapid_keys(is_active) WHERE is_active;
+
+-- Key the subscription was created with (NULL for subscriptions that predate key tracking)
+ALTER TABLE push_subscriptions ADD COLUMN vapid_key_id BIGINT REFERENCES vapid_keys(id);
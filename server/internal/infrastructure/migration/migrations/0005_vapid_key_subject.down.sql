@@ -0,0 +1 @@
+ALTER TABLE vapid_keys DROP COLUMN IF EXISTS subject;
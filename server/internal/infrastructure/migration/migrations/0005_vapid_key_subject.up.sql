@@ -0,0 +1,2 @@
+-- Per-key VAPID subject (mailto:/https:) overriding the server default
+ALTER TABLE vapid_keys ADD COLUMN subject TEXT;
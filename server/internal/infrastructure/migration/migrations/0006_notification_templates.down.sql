@@ -0,0 +1,3 @@
+ALTER TABLE push_jobs DROP COLUMN IF EXISTS template_vars;
+
+ALTER TABLE notification_templates DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,4 @@
+ALTER TABLE notification_templates ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Variables substituted into the job's template at send time
+ALTER TABLE push_jobs ADD COLUMN template_vars JSONB;
@@ -0,0 +1,6 @@
+UPDATE push_deliveries SET status = 'gone' WHERE status = 'skipped';
+ALTER TABLE push_deliveries DROP CONSTRAINT push_deliveries_status_check;
+ALTER TABLE push_deliveries ADD CONSTRAINT push_deliveries_status_check
+  CHECK (status IN ('pending','succeeded','failed','gone'));
+
+ALTER TABLE notification_prefs DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,7 @@
+-- quiet_hours holds {"start": "22:00", "end": "07:00", "timezone": "Asia/Tokyo"}
+ALTER TABLE notification_prefs ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Deliveries to users who opted out of the job's topic are skipped
+ALTER TABLE push_deliveries DROP CONSTRAINT push_deliveries_status_check;
+ALTER TABLE push_deliveries ADD CONSTRAINT push_deliveries_status_check
+  CHECK (status IN ('pending','succeeded','failed','gone','skipped'));
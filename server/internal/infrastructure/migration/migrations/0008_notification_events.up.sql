@@ -0,0 +1,12 @@
+-- Engagement events reported by the Service Worker (POST /api/push/events)
+CREATE TABLE notification_events (
+  id BIGSERIAL PRIMARY KEY,
+  job_id BIGINT NOT NULL REFERENCES push_jobs(id) ON DELETE CASCADE,
+  subscription_id BIGINT REFERENCES push_subscriptions(id) ON DELETE SET NULL,
+  event_type TEXT NOT NULL CHECK (event_type IN ('delivered','displayed','clicked','closed')),
+  url TEXT,                                -- Clicked URL, if any
+  occurred_at TIMESTAMPTZ NOT NULL,        -- As reported by the client
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notification_events_job ON notification_events(job_id, event_type);
@@ -0,0 +1,12 @@
+-- Scoped API keys for server-to-server callers. Only a SHA-256 of the
+-- secret is stored; prefix is kept so operators can recognise a key.
+CREATE TABLE api_keys (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  prefix TEXT NOT NULL,
+  secret_hash TEXT NOT NULL UNIQUE,
+  scopes TEXT[] NOT NULL,                  -- send / read-logs / admin
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_used_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ
+);
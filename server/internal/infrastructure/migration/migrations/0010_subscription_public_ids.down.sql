@@ -0,0 +1 @@
+ALTER TABLE push_subscriptions DROP COLUMN IF EXISTS public_id;
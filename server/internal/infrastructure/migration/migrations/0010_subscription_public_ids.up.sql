@@ -0,0 +1,8 @@
+-- Opaque identifiers exposed to browsers and API clients instead of the
+-- sequential id. Existing rows get a random one in the same format.
+ALTER TABLE push_subscriptions ADD COLUMN public_id TEXT;
+
+UPDATE push_subscriptions SET public_id = 'sub_' || replace(gen_random_uuid()::text, '-', '');
+
+ALTER TABLE push_subscriptions ALTER COLUMN public_id SET NOT NULL;
+ALTER TABLE push_subscriptions ADD CONSTRAINT push_subscriptions_public_id_key UNIQUE (public_id);
@@ -0,0 +1,4 @@
+ALTER TABLE push_jobs DROP COLUMN IF EXISTS segment;
+ALTER TABLE push_subscriptions DROP COLUMN IF EXISTS last_active_at;
+ALTER TABLE users DROP COLUMN IF EXISTS attributes;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
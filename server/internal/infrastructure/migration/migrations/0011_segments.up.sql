@@ -0,0 +1,12 @@
+-- Attributes segments can match on. attributes holds {"plan": "premium"}
+ALTER TABLE users ADD COLUMN locale TEXT;
+ALTER TABLE users ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Refreshed when the browser re-subscribes or reports engagement events
+ALTER TABLE push_subscriptions ADD COLUMN last_active_at TIMESTAMPTZ;
+UPDATE push_subscriptions SET last_active_at = updated_at;
+ALTER TABLE push_subscriptions ALTER COLUMN last_active_at SET NOT NULL;
+ALTER TABLE push_subscriptions ALTER COLUMN last_active_at SET DEFAULT now();
+
+-- Segment criteria evaluated when the job is sent
+ALTER TABLE push_jobs ADD COLUMN segment JSONB;
@@ -0,0 +1,2 @@
+ALTER TABLE push_jobs DROP COLUMN IF EXISTS recipient_count;
+DROP TABLE IF EXISTS push_job_recipients;
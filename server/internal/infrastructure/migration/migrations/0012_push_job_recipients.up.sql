@@ -0,0 +1,8 @@
+-- Users a batch job is sent to; expanded into push_deliveries at send time
+CREATE TABLE push_job_recipients (
+  job_id BIGINT NOT NULL REFERENCES push_jobs(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  PRIMARY KEY (job_id, user_id)
+);
+
+ALTER TABLE push_jobs ADD COLUMN recipient_count INTEGER NOT NULL DEFAULT 0;
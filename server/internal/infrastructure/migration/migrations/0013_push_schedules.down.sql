@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS push_schedules;
+DROP TYPE IF EXISTS schedule_status;
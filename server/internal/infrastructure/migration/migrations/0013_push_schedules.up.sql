@@ -0,0 +1,25 @@
+CREATE TYPE schedule_status AS ENUM ('active','paused');
+
+-- Recurring schedules that spawn a push job on every cron occurrence
+CREATE TABLE push_schedules (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  cron_expression TEXT NOT NULL,
+  timezone TEXT NOT NULL DEFAULT 'UTC',
+  user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+  topic TEXT,
+  urgency TEXT NOT NULL CHECK (urgency IN ('very-low','low','normal','high')),
+  ttl_seconds INT NOT NULL CHECK (ttl_seconds >= 0),
+  payload JSONB NOT NULL,
+  template_key TEXT REFERENCES notification_templates(key),
+  template_vars JSONB,
+  segment JSONB,
+  status schedule_status NOT NULL DEFAULT 'active',
+  next_run_at TIMESTAMPTZ,                 -- NULL while paused
+  last_run_at TIMESTAMPTZ,
+  last_job_id BIGINT REFERENCES push_jobs(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_push_schedules_due ON push_schedules(next_run_at) WHERE status = 'active';
@@ -0,0 +1,3 @@
+ALTER TABLE push_jobs DROP CONSTRAINT push_jobs_user_id_fkey;
+ALTER TABLE push_jobs
+  ADD CONSTRAINT push_jobs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+-- Jobs for a deleted user are removed with the user; with SET NULL a
+-- pending job targeted at one user would be sent as a broadcast.
+ALTER TABLE push_jobs DROP CONSTRAINT push_jobs_user_id_fkey;
+ALTER TABLE push_jobs
+  ADD CONSTRAINT push_jobs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
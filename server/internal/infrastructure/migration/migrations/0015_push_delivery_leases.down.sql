@@ -0,0 +1,3 @@
+ALTER TABLE push_deliveries
+  DROP COLUMN IF EXISTS lease_expires_at,
+  DROP COLUMN IF EXISTS lease_owner;
@@ -0,0 +1,6 @@
+-- Queue workers lease a delivery before sending it, so a message delivered
+-- to two workers is sent once; an expired lease marks a delivery abandoned
+-- by a crashed worker.
+ALTER TABLE push_deliveries
+  ADD COLUMN lease_owner TEXT,
+  ADD COLUMN lease_expires_at TIMESTAMPTZ;
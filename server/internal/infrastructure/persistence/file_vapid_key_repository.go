@@ -0,0 +1,141 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type vapidKeyRecord struct {
+	ID          int64      `json:"id"`
+	PublicKey   string     `json:"publicKey"`
+	PrivateKey  string     `json:"privateKey"`
+	Subject     string     `json:"subject,omitempty"`
+	IsActive    bool       `json:"isActive"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ActivatedAt *time.Time `json:"activatedAt,omitempty"`
+}
+
+// FileVAPIDKeyRepository keeps VAPID keys in a JSON file so they survive
+// restarts when the server runs without PostgreSQL.
+type FileVAPIDKeyRepository struct {
+	*MemoryVAPIDKeyRepository
+
+	path string
+	// mu serializes a mutation with the file write that follows it.
+	mu sync.Mutex
+}
+
+func NewFileVAPIDKeyRepository(path string) (*FileVAPIDKeyRepository, error) {
+	r := &FileVAPIDKeyRepository{
+		MemoryVAPIDKeyRepository: NewMemoryVAPIDKeyRepository(),
+		path:                     path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VAPID key file: %w", err)
+	}
+
+	var records []vapidKeyRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse VAPID key file: %w", err)
+	}
+
+	ctx := context.Background()
+	for _, rec := range records {
+		var subject valueobject.VAPIDSubject
+		if rec.Subject != "" {
+			subject, err = valueobject.NewVAPIDSubject(rec.Subject)
+			if err != nil {
+				return nil, fmt.Errorf("invalid subject for VAPID key %d: %w", rec.ID, err)
+			}
+		}
+		key := model.ReconstructVAPIDKey(rec.ID, rec.PublicKey, rec.PrivateKey, subject, rec.IsActive, rec.CreatedAt, rec.ActivatedAt)
+		if err := r.MemoryVAPIDKeyRepository.Save(ctx, key); err != nil {
+			return nil, fmt.Errorf("failed to load VAPID key %d: %w", rec.ID, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *FileVAPIDKeyRepository) Save(ctx context.Context, key *model.VAPIDKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.MemoryVAPIDKeyRepository.Save(ctx, key); err != nil {
+		return err
+	}
+	return r.flush(ctx)
+}
+
+func (r *FileVAPIDKeyRepository) Activate(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.MemoryVAPIDKeyRepository.Activate(ctx, id); err != nil {
+		return err
+	}
+	return r.flush(ctx)
+}
+
+// flush rewrites the key file atomically. The file holds private keys, so it
+// is only readable by the owner.
+func (r *FileVAPIDKeyRepository) flush(ctx context.Context) error {
+	keys, err := r.MemoryVAPIDKeyRepository.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	records := make([]vapidKeyRecord, len(keys))
+	for i, key := range keys {
+		records[i] = vapidKeyRecord{
+			ID:          key.ID(),
+			PublicKey:   key.PublicKey(),
+			PrivateKey:  key.PrivateKey(),
+			Subject:     key.Subject().Value(),
+			IsActive:    key.IsActive(),
+			CreatedAt:   key.CreatedAt(),
+			ActivatedAt: key.ActivatedAt(),
+		}
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode VAPID keys: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write VAPID key file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write VAPID key file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write VAPID key file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write VAPID key file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to write VAPID key file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+func TestFileVAPIDKeyRepositoryPersistsKeys(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vapid-keys.json")
+
+	repo, err := NewFileVAPIDKeyRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileVAPIDKeyRepository: %v", err)
+	}
+
+	for _, pub := range []string{"old-public", "new-public"} {
+		id, _ := repo.NextIdentity(ctx)
+		if err := repo.Save(ctx, model.NewVAPIDKey(id, pub, pub+"-private")); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	if err := repo.Activate(ctx, 1); err != nil {
+		t.Fatalf("Activate(1): %v", err)
+	}
+	if err := repo.Activate(ctx, 2); err != nil {
+		t.Fatalf("Activate(2): %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("key file permissions = %o, want 600", perm)
+	}
+
+	reopened, err := NewFileVAPIDKeyRepository(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+
+	active, _ := reopened.FindActive(ctx)
+	if active == nil || active.ID() != 2 || active.PrivateKey() != "new-public-private" {
+		t.Fatalf("active key after reopen = %+v", active)
+	}
+	old, _ := reopened.FindByPublicKey(ctx, "old-public")
+	if old == nil || old.IsActive() || old.ActivatedAt() == nil {
+		t.Fatalf("retired key after reopen = %+v", old)
+	}
+	if id, _ := reopened.NextIdentity(ctx); id != 3 {
+		t.Fatalf("NextIdentity after reopen = %d, want 3", id)
+	}
+}
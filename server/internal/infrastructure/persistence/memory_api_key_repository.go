@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+// MemoryAPIKeyRepository stores copies of the keys, so a key loaded before
+// a revocation cannot be saved over it unnoticed.
+type MemoryAPIKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[int64]*model.APIKey
+	nextID int64
+}
+
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		keys:   make(map[int64]*model.APIKey),
+		nextID: 1,
+	}
+}
+
+func (r *MemoryAPIKeyRepository) Save(ctx context.Context, key *model.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *key
+	r.keys[key.ID()] = &stored
+	return nil
+}
+
+func (r *MemoryAPIKeyRepository) FindByID(ctx context.Context, id int64) (*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, nil
+	}
+	found := *key
+	return &found, nil
+}
+
+func (r *MemoryAPIKeyRepository) FindBySecretHash(ctx context.Context, secretHash string) (*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.SecretHash() == secretHash {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryAPIKeyRepository) FindAll(ctx context.Context) ([]*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		found := *key
+		result = append(result, &found)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID() < result[j].ID()
+	})
+	return result, nil
+}
+
+func (r *MemoryAPIKeyRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, exists := r.keys[id]; exists && !key.IsRevoked() {
+		key.MarkUsed(at)
+	}
+	return nil
+}
+
+func (r *MemoryAPIKeyRepository) NextIdentity(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+	return id, nil
+}
@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// notificationBuffer is how many notifications a slow subscriber may fall
+// behind before further ones are dropped; its next poll picks those jobs up.
+const notificationBuffer = 256
+
+// MemoryJobNotifier implements repository.JobNotifier within a single
+// process.
+type MemoryJobNotifier struct {
+	mu          sync.Mutex
+	subscribers map[chan time.Time]struct{}
+}
+
+func NewMemoryJobNotifier() *MemoryJobNotifier {
+	return &MemoryJobNotifier{
+		subscribers: make(map[chan time.Time]struct{}),
+	}
+}
+
+func (n *MemoryJobNotifier) NotifyJobReady(ctx context.Context, readyAt time.Time) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subscribers {
+		offer(ch, readyAt)
+	}
+	return nil
+}
+
+func (n *MemoryJobNotifier) Subscribe(ctx context.Context) (<-chan time.Time, error) {
+	ch := make(chan time.Time, notificationBuffer)
+
+	n.mu.Lock()
+	n.subscribers[ch] = struct{}{}
+	n.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(n.subscribers, ch)
+		close(ch)
+	})
+	return ch, nil
+}
@@ -0,0 +1,38 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryLocker implements repository.Locker within a single process.
+type MemoryLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		held: make(map[string]bool),
+	}
+}
+
+func (l *MemoryLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held[name] {
+		return nil, false, nil
+	}
+	l.held[name] = true
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			delete(l.held, name)
+		})
+	}
+	return release, true, nil
+}
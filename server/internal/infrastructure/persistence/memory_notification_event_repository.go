@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type MemoryNotificationEventRepository struct {
+	mu     sync.RWMutex
+	events map[int64]*model.NotificationEvent
+	nextID int64
+}
+
+func NewMemoryNotificationEventRepository() *MemoryNotificationEventRepository {
+	return &MemoryNotificationEventRepository{
+		events: make(map[int64]*model.NotificationEvent),
+		nextID: 1,
+	}
+}
+
+func (r *MemoryNotificationEventRepository) Save(ctx context.Context, event *model.NotificationEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[event.ID()] = event
+	return nil
+}
+
+func (r *MemoryNotificationEventRepository) CountByJobID(ctx context.Context, jobID valueobject.JobID) (model.EngagementCounts, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[model.NotificationEventType]map[int64]bool)
+	var counts model.EngagementCounts
+	for _, event := range r.events {
+		if !event.JobID().Equals(jobID) || event.SubscriptionID() == nil {
+			continue
+		}
+		if seen[event.Type()] == nil {
+			seen[event.Type()] = make(map[int64]bool)
+		}
+		subscriptionID := event.SubscriptionID().Value()
+		if seen[event.Type()][subscriptionID] {
+			continue
+		}
+		seen[event.Type()][subscriptionID] = true
+
+		switch event.Type() {
+		case model.NotificationEventDelivered:
+			counts.Delivered++
+		case model.NotificationEventDisplayed:
+			counts.Displayed++
+		case model.NotificationEventClicked:
+			counts.Clicked++
+		case model.NotificationEventClosed:
+			counts.Closed++
+		}
+	}
+	return counts, nil
+}
+
+func (r *MemoryNotificationEventRepository) NextIdentity(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+	return id, nil
+}
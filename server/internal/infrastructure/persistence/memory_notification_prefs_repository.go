@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type MemoryNotificationPrefsRepository struct {
+	mu    sync.RWMutex
+	prefs map[valueobject.UserID]*model.NotificationPrefs
+}
+
+func NewMemoryNotificationPrefsRepository() *MemoryNotificationPrefsRepository {
+	return &MemoryNotificationPrefsRepository{
+		prefs: make(map[valueobject.UserID]*model.NotificationPrefs),
+	}
+}
+
+func (r *MemoryNotificationPrefsRepository) Save(ctx context.Context, prefs *model.NotificationPrefs) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prefs[prefs.UserID()] = prefs
+	return nil
+}
+
+func (r *MemoryNotificationPrefsRepository) FindByUserID(ctx context.Context, userID valueobject.UserID) (*model.NotificationPrefs, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs, exists := r.prefs[userID]
+	if !exists {
+		return nil, nil
+	}
+	return prefs, nil
+}
+
+func (r *MemoryNotificationPrefsRepository) FindByUserIDs(ctx context.Context, userIDs []valueobject.UserID) (map[valueobject.UserID]*model.NotificationPrefs, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[valueobject.UserID]*model.NotificationPrefs)
+	for _, userID := range userIDs {
+		if prefs, exists := r.prefs[userID]; exists {
+			result[userID] = prefs
+		}
+	}
+	return result, nil
+}
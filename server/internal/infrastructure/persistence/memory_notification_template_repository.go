@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+// MemoryNotificationTemplateRepository checks jobRepo and scheduleRepo for
+// references before deleting a template, like the foreign keys do in
+// PostgreSQL.
+type MemoryNotificationTemplateRepository struct {
+	mu           sync.RWMutex
+	templates    map[string]*model.NotificationTemplate
+	jobRepo      *MemoryPushJobRepository
+	scheduleRepo *MemoryPushScheduleRepository
+	nextID       int64
+}
+
+func NewMemoryNotificationTemplateRepository(jobRepo *MemoryPushJobRepository, scheduleRepo *MemoryPushScheduleRepository) *MemoryNotificationTemplateRepository {
+	return &MemoryNotificationTemplateRepository{
+		templates:    make(map[string]*model.NotificationTemplate),
+		jobRepo:      jobRepo,
+		scheduleRepo: scheduleRepo,
+		nextID:       1,
+	}
+}
+
+func (r *MemoryNotificationTemplateRepository) Save(ctx context.Context, template *model.NotificationTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates[template.Key()] = template
+	return nil
+}
+
+func (r *MemoryNotificationTemplateRepository) FindByKey(ctx context.Context, key string) (*model.NotificationTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	template, exists := r.templates[key]
+	if !exists {
+		return nil, nil
+	}
+	return template, nil
+}
+
+func (r *MemoryNotificationTemplateRepository) FindAll(ctx context.Context) ([]*model.NotificationTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.NotificationTemplate, 0, len(r.templates))
+	for _, template := range r.templates {
+		result = append(result, template)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key() < result[j].Key()
+	})
+	return result, nil
+}
+
+func (r *MemoryNotificationTemplateRepository) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.jobRepo.usesTemplate(key) || r.scheduleRepo.usesTemplate(key) {
+		return errors.ErrTemplateInUse
+	}
+	delete(r.templates, key)
+	return nil
+}
+
+func (r *MemoryNotificationTemplateRepository) NextIdentity(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+	return id, nil
+}
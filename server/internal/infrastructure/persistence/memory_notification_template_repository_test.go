@@ -0,0 +1,51 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+func TestMemoryNotificationTemplateRepositoryDeleteInUse(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := NewMemoryPushJobRepository()
+	scheduleRepo := NewMemoryPushScheduleRepository(jobRepo)
+	repo := NewMemoryNotificationTemplateRepository(jobRepo, scheduleRepo)
+
+	for i, key := range []string{"job-template", "schedule-template", "unused"} {
+		template, _ := model.NewNotificationTemplate(int64(i+1), key, "title", "body", "", "", nil)
+		if err := repo.Save(ctx, template); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	jobID, _ := jobRepo.NextIdentity(ctx)
+	job, _ := model.NewPushJob(jobID, "", nil, "", model.UrgencyNormal, 60, model.PushPayload{}, nil)
+	job.UseTemplate("job-template", nil)
+	jobRepo.Save(ctx, job)
+
+	scheduleID, _ := scheduleRepo.NextIdentity(ctx)
+	cron, _ := valueobject.NewCronSchedule("@daily", "")
+	schedule, _ := model.NewPushSchedule(scheduleID, "digest", cron, nil, "", model.UrgencyNormal, 60, model.PushPayload{})
+	schedule.UseTemplate("schedule-template", nil)
+	scheduleRepo.Save(ctx, schedule)
+
+	for _, key := range []string{"job-template", "schedule-template"} {
+		if err := repo.Delete(ctx, key); err != errors.ErrTemplateInUse {
+			t.Errorf("Delete(%s) = %v, want ErrTemplateInUse", key, err)
+		}
+		if found, _ := repo.FindByKey(ctx, key); found == nil {
+			t.Errorf("%s was deleted while in use", key)
+		}
+	}
+
+	if err := repo.Delete(ctx, "unused"); err != nil {
+		t.Fatalf("Delete(unused): %v", err)
+	}
+	if found, _ := repo.FindByKey(ctx, "unused"); found != nil {
+		t.Error("unused template still exists after Delete")
+	}
+}
@@ -0,0 +1,221 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type deliveryKey struct {
+	jobID          valueobject.JobID
+	subscriptionID valueobject.SubscriptionID
+}
+
+type deliveryLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// MemoryPushDeliveryRepository stores copies of the deliveries, since queue
+// workers update deliveries of the same job concurrently.
+type MemoryPushDeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries map[int64]*model.PushDelivery
+	byKey      map[deliveryKey]int64
+	leases     map[int64]deliveryLease
+	nextID     int64
+}
+
+func NewMemoryPushDeliveryRepository() *MemoryPushDeliveryRepository {
+	return &MemoryPushDeliveryRepository{
+		deliveries: make(map[int64]*model.PushDelivery),
+		byKey:      make(map[deliveryKey]int64),
+		leases:     make(map[int64]deliveryLease),
+		nextID:     1,
+	}
+}
+
+func (r *MemoryPushDeliveryRepository) CreateForJob(ctx context.Context, jobID valueobject.JobID, subscriptionIDs []valueobject.SubscriptionID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, subscriptionID := range subscriptionIDs {
+		key := deliveryKey{jobID: jobID, subscriptionID: subscriptionID}
+		if _, exists := r.byKey[key]; exists {
+			continue
+		}
+
+		id := r.nextID
+		r.nextID++
+		r.deliveries[id] = model.NewPushDelivery(id, jobID, subscriptionID)
+		r.byKey[key] = id
+	}
+	return nil
+}
+
+func (r *MemoryPushDeliveryRepository) Save(ctx context.Context, delivery *model.PushDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.deliveries[delivery.ID()]; !exists {
+		return fmt.Errorf("delivery not found")
+	}
+	stored := *delivery
+	r.deliveries[delivery.ID()] = &stored
+	return nil
+}
+
+func (r *MemoryPushDeliveryRepository) Hold(ctx context.Context, id int64, until time.Time, reason string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	delivery, exists := r.deliveries[id]
+	if !exists || !delivery.IsDue(now) || r.leasedAt(id, now) {
+		return false, nil
+	}
+	held := *delivery
+	held.Defer(until, reason)
+	r.deliveries[id] = &held
+	return true, nil
+}
+
+func (r *MemoryPushDeliveryRepository) Claim(ctx context.Context, id int64, owner string, leaseDuration time.Duration) (*model.PushDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	delivery, exists := r.deliveries[id]
+	if !exists || delivery.Status() != model.DeliveryStatusPending || r.leasedAt(id, now) {
+		return nil, nil
+	}
+	r.leases[id] = deliveryLease{owner: owner, expiresAt: now.Add(leaseDuration)}
+	found := *delivery
+	return &found, nil
+}
+
+func (r *MemoryPushDeliveryRepository) SaveClaimed(ctx context.Context, delivery *model.PushDelivery, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lease, leased := r.leases[delivery.ID()]; !leased || lease.owner != owner {
+		return errors.ErrDeliveryLeaseLost
+	}
+	delete(r.leases, delivery.ID())
+	stored := *delivery
+	r.deliveries[delivery.ID()] = &stored
+	return nil
+}
+
+// leasedAt reports whether a worker holds the delivery's lease at now. The
+// caller must hold r.mu.
+func (r *MemoryPushDeliveryRepository) leasedAt(id int64, now time.Time) bool {
+	lease, leased := r.leases[id]
+	return leased && lease.expiresAt.After(now)
+}
+
+func (r *MemoryPushDeliveryRepository) FindByID(ctx context.Context, id int64) (*model.PushDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, exists := r.deliveries[id]
+	if !exists {
+		return nil, nil
+	}
+	found := *delivery
+	return &found, nil
+}
+
+func (r *MemoryPushDeliveryRepository) FindByJobAndSubscription(ctx context.Context, jobID valueobject.JobID, subscriptionID valueobject.SubscriptionID) (*model.PushDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.byKey[deliveryKey{jobID: jobID, subscriptionID: subscriptionID}]
+	if !exists {
+		return nil, nil
+	}
+	found := *r.deliveries[id]
+	return &found, nil
+}
+
+func (r *MemoryPushDeliveryRepository) FindByJobID(ctx context.Context, jobID valueobject.JobID) ([]*model.PushDelivery, error) {
+	return r.filter(func(d *model.PushDelivery) bool {
+		return d.JobID().Equals(jobID)
+	}), nil
+}
+
+func (r *MemoryPushDeliveryRepository) FindDueByJobID(ctx context.Context, jobID valueobject.JobID, now time.Time) ([]*model.PushDelivery, error) {
+	return r.filter(func(d *model.PushDelivery) bool {
+		return d.JobID().Equals(jobID) && d.IsDue(now) && !r.leasedAt(d.ID(), now)
+	}), nil
+}
+
+func (r *MemoryPushDeliveryRepository) CountByJobID(ctx context.Context, jobID valueobject.JobID) (model.DeliveryCounts, error) {
+	var counts model.DeliveryCounts
+	for _, d := range r.filter(func(d *model.PushDelivery) bool { return d.JobID().Equals(jobID) }) {
+		switch d.Status() {
+		case model.DeliveryStatusPending:
+			counts.Pending++
+		case model.DeliveryStatusSucceeded:
+			counts.Succeeded++
+		case model.DeliveryStatusFailed:
+			counts.Failed++
+		case model.DeliveryStatusGone:
+			counts.Gone++
+		case model.DeliveryStatusSkipped:
+			counts.Skipped++
+		}
+	}
+	return counts, nil
+}
+
+func (r *MemoryPushDeliveryRepository) NextAttemptAt(ctx context.Context, jobID valueobject.JobID) (*time.Time, error) {
+	var next *time.Time
+	for _, d := range r.filter(func(d *model.PushDelivery) bool {
+		return d.JobID().Equals(jobID) && d.Status() == model.DeliveryStatusPending
+	}) {
+		at := d.NextAttemptAt()
+		if at == nil {
+			now := time.Now()
+			at = &now
+		}
+		if lease, leased := r.lease(d.ID()); leased && lease.expiresAt.After(*at) {
+			at = &lease.expiresAt
+		}
+		if next == nil || at.Before(*next) {
+			next = at
+		}
+	}
+	return next, nil
+}
+
+func (r *MemoryPushDeliveryRepository) lease(id int64) (deliveryLease, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lease, leased := r.leases[id]
+	return lease, leased
+}
+
+func (r *MemoryPushDeliveryRepository) filter(match func(d *model.PushDelivery) bool) []*model.PushDelivery {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*model.PushDelivery
+	for _, d := range r.deliveries {
+		if match(d) {
+			found := *d
+			result = append(result, &found)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID() < result[j].ID()
+	})
+	return result
+}
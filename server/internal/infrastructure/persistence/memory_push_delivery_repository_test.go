@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+func TestMemoryPushDeliveryRepositoryLeases(t *testing.T) {
+	jobID, _ := valueobject.NewJobID(1)
+	subscriptionID, _ := valueobject.NewSubscriptionID(1)
+	testDeliveryLeases(t, NewMemoryPushDeliveryRepository(), jobID, subscriptionID)
+}
+
+// testDeliveryLeases checks that a delivery is handed to the queue once and
+// sent by a single worker. It is shared by the memory and PostgreSQL
+// repositories.
+func testDeliveryLeases(t *testing.T, repo repository.PushDeliveryRepository, jobID valueobject.JobID, subscriptionID valueobject.SubscriptionID) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := repo.CreateForJob(ctx, jobID, []valueobject.SubscriptionID{subscriptionID}); err != nil {
+		t.Fatalf("CreateForJob: %v", err)
+	}
+	delivery, err := repo.FindByJobAndSubscription(ctx, jobID, subscriptionID)
+	if err != nil || delivery == nil {
+		t.Fatalf("FindByJobAndSubscription = %v, %v", delivery, err)
+	}
+	id := delivery.ID()
+
+	// Of two rounds racing to enqueue the delivery, only the first holds it.
+	holdUntil := time.Now().Add(time.Hour)
+	if held, err := repo.Hold(ctx, id, holdUntil, "queued for sending"); err != nil || !held {
+		t.Fatalf("Hold = %v, %v, want held", held, err)
+	}
+	if held, err := repo.Hold(ctx, id, holdUntil, "queued for sending"); err != nil || held {
+		t.Fatalf("Hold(already held) = %v, %v, want not held", held, err)
+	}
+	if due, _ := repo.FindDueByJobID(ctx, jobID, time.Now()); len(due) != 0 {
+		t.Fatalf("held delivery is due: %v", due)
+	}
+
+	// Only one of two workers handed the message claims the delivery.
+	claimed, err := repo.Claim(ctx, id, "worker-a", time.Minute)
+	if err != nil || claimed == nil || claimed.ID() != id {
+		t.Fatalf("Claim = %v, %v", claimed, err)
+	}
+	if other, err := repo.Claim(ctx, id, "worker-b", time.Minute); err != nil || other != nil {
+		t.Fatalf("Claim(leased) = %v, %v, want nil", other, err)
+	}
+
+	// A worker without the lease cannot save an outcome.
+	claimed.MarkAsSucceeded(201)
+	if err := repo.SaveClaimed(ctx, claimed, "worker-b"); err != errors.ErrDeliveryLeaseLost {
+		t.Fatalf("SaveClaimed(other owner) = %v, want ErrDeliveryLeaseLost", err)
+	}
+	if err := repo.SaveClaimed(ctx, claimed, "worker-a"); err != nil {
+		t.Fatalf("SaveClaimed: %v", err)
+	}
+	stored, _ := repo.FindByID(ctx, id)
+	if stored == nil || stored.Status() != model.DeliveryStatusSucceeded {
+		t.Fatalf("delivery after SaveClaimed = %+v", stored)
+	}
+
+	// Settled deliveries can be neither claimed nor saved again.
+	if again, err := repo.Claim(ctx, id, "worker-b", time.Minute); err != nil || again != nil {
+		t.Fatalf("Claim(settled) = %v, %v, want nil", again, err)
+	}
+	if err := repo.SaveClaimed(ctx, claimed, "worker-a"); err != errors.ErrDeliveryLeaseLost {
+		t.Fatalf("SaveClaimed(lease ended) = %v, want ErrDeliveryLeaseLost", err)
+	}
+}
@@ -3,23 +3,31 @@ package persistence
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
 )
 
+// MemoryPushJobRepository stores copies of the jobs, so a sender holding a
+// job it no longer owns cannot change the stored state behind the new
+// owner's back.
 type MemoryPushJobRepository struct {
-	mu     sync.RWMutex
-	jobs   map[valueobject.JobID]*model.PushJob
-	nextID int64
+	mu         sync.RWMutex
+	jobs       map[valueobject.JobID]*model.PushJob
+	recipients map[valueobject.JobID][]valueobject.UserID
+	nextID     int64
 }
 
 func NewMemoryPushJobRepository() *MemoryPushJobRepository {
 	return &MemoryPushJobRepository{
-		jobs:   make(map[valueobject.JobID]*model.PushJob),
-		nextID: 1,
+		jobs:       make(map[valueobject.JobID]*model.PushJob),
+		recipients: make(map[valueobject.JobID][]valueobject.UserID),
+		nextID:     1,
 	}
 }
 
@@ -27,10 +35,39 @@ func (r *MemoryPushJobRepository) Save(ctx context.Context, job *model.PushJob)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.jobs[job.ID()] = job
+	r.jobs[job.ID()] = copyPushJob(job)
 	return nil
 }
 
+func copyPushJob(job *model.PushJob) *model.PushJob {
+	copied := *job
+	return &copied
+}
+
+func (r *MemoryPushJobRepository) CreateBatch(ctx context.Context, job *model.PushJob, recipients []valueobject.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key := job.IdempotencyKey(); key != "" {
+		for id, stored := range r.jobs {
+			if id != job.ID() && stored.IdempotencyKey() == key {
+				return errors.ErrDuplicateIdempotencyKey
+			}
+		}
+	}
+
+	r.jobs[job.ID()] = copyPushJob(job)
+	r.recipients[job.ID()] = append([]valueobject.UserID(nil), recipients...)
+	return nil
+}
+
+func (r *MemoryPushJobRepository) FindRecipients(ctx context.Context, id valueobject.JobID) ([]valueobject.UserID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]valueobject.UserID(nil), r.recipients[id]...), nil
+}
+
 func (r *MemoryPushJobRepository) FindByID(ctx context.Context, id valueobject.JobID) (*model.PushJob, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -39,7 +76,7 @@ func (r *MemoryPushJobRepository) FindByID(ctx context.Context, id valueobject.J
 	if !exists {
 		return nil, nil
 	}
-	return job, nil
+	return copyPushJob(job), nil
 }
 
 func (r *MemoryPushJobRepository) FindByIdempotencyKey(ctx context.Context, key string) (*model.PushJob, error) {
@@ -48,7 +85,7 @@ func (r *MemoryPushJobRepository) FindByIdempotencyKey(ctx context.Context, key
 
 	for _, job := range r.jobs {
 		if job.IdempotencyKey() == key && key != "" {
-			return job, nil
+			return copyPushJob(job), nil
 		}
 	}
 	return nil, nil
@@ -62,7 +99,7 @@ func (r *MemoryPushJobRepository) FindPendingJobs(ctx context.Context, limit int
 	count := 0
 	for _, job := range r.jobs {
 		if job.Status() == model.JobStatusPending && count < limit {
-			result = append(result, job)
+			result = append(result, copyPushJob(job))
 			count++
 		}
 	}
@@ -77,7 +114,7 @@ func (r *MemoryPushJobRepository) FindReadyToSendJobs(ctx context.Context, limit
 	count := 0
 	for _, job := range r.jobs {
 		if job.IsReadyToSend() && count < limit {
-			result = append(result, job)
+			result = append(result, copyPushJob(job))
 			count++
 		}
 	}
@@ -92,7 +129,7 @@ func (r *MemoryPushJobRepository) FindFailedJobsForRetry(ctx context.Context, ma
 	count := 0
 	for _, job := range r.jobs {
 		if job.ShouldRetry(maxRetries) && count < limit {
-			result = append(result, job)
+			result = append(result, copyPushJob(job))
 			count++
 		}
 	}
@@ -140,23 +177,193 @@ func (r *MemoryPushJobRepository) Delete(ctx context.Context, id valueobject.Job
 	defer r.mu.Unlock()
 
 	delete(r.jobs, id)
+	delete(r.recipients, id)
 	return nil
 }
 
-func (r *MemoryPushJobRepository) DeleteOldCompletedJobs(ctx context.Context, olderThan int) error {
+func (r *MemoryPushJobRepository) DeleteOldCompletedJobs(ctx context.Context, olderThanDays int) (int, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	cutoff := time.Now().Add(-time.Duration(olderThan) * 24 * time.Hour)
+	cutoff := time.Now().Add(-time.Duration(olderThanDays) * 24 * time.Hour)
+	deleted := 0
 	for id, job := range r.jobs {
-		if (job.Status() == model.JobStatusSucceeded || job.Status() == model.JobStatusFailed) &&
-			job.UpdatedAt().Before(cutoff) {
-			delete(r.jobs, id)
+		switch job.Status() {
+		case model.JobStatusSucceeded, model.JobStatusFailed, model.JobStatusCancelled:
+			if job.UpdatedAt().Before(cutoff) {
+				delete(r.jobs, id)
+				delete(r.recipients, id)
+				deleted++
+			}
+		}
+	}
+	return deleted, nil
+}
+
+func (r *MemoryPushJobRepository) ClaimReadyJobs(ctx context.Context, owner string, leaseDuration time.Duration, limit int) ([]*model.PushJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimable []*model.PushJob
+	for _, job := range r.jobs {
+		if job.IsClaimable() {
+			claimable = append(claimable, job)
+		}
+	}
+	sort.Slice(claimable, func(i, j int) bool {
+		return claimable[i].ID().Value() < claimable[j].ID().Value()
+	})
+	if len(claimable) > limit {
+		claimable = claimable[:limit]
+	}
+
+	leaseExpiresAt := time.Now().Add(leaseDuration)
+	claimed := make([]*model.PushJob, len(claimable))
+	for i, job := range claimable {
+		job.Claim(owner, leaseExpiresAt)
+		claimed[i] = copyPushJob(job)
+	}
+	return claimed, nil
+}
+
+func (r *MemoryPushJobRepository) ClaimJob(ctx context.Context, id valueobject.JobID, owner string, leaseDuration time.Duration, expected model.JobStatus) (*model.PushJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists || job.Status() != expected {
+		return nil, nil
+	}
+
+	job.Claim(owner, time.Now().Add(leaseDuration))
+	return copyPushJob(job), nil
+}
+
+func (r *MemoryPushJobRepository) RenewLease(ctx context.Context, id valueobject.JobID, owner string, leaseDuration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists || job.Status() != model.JobStatusSending || job.LeaseOwner() != owner {
+		return errors.ErrJobLeaseLost
+	}
+
+	job.ExtendLease(time.Now().Add(leaseDuration))
+	return nil
+}
+
+func (r *MemoryPushJobRepository) NextClaimableAt(ctx context.Context) (*time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	var next *time.Time
+	for _, job := range r.jobs {
+		at, ok := job.ClaimableAt()
+		if !ok || !at.After(now) {
+			continue
+		}
+		if next == nil || at.Before(*next) {
+			next = &at
 		}
 	}
+	return next, nil
+}
+
+func (r *MemoryPushJobRepository) SaveClaimed(ctx context.Context, job *model.PushJob, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.jobs[job.ID()]
+	if !exists || stored.Status() != model.JobStatusSending || stored.LeaseOwner() != owner {
+		return errors.ErrJobLeaseLost
+	}
+
+	r.jobs[job.ID()] = copyPushJob(job)
 	return nil
 }
 
+func (r *MemoryPushJobRepository) ReleaseLease(ctx context.Context, id valueobject.JobID, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists || job.Status() != model.JobStatusSending || job.LeaseOwner() != owner {
+		return errors.ErrJobLeaseLost
+	}
+
+	job.Release()
+	return nil
+}
+
+func (r *MemoryPushJobRepository) AdvanceNextAttempt(ctx context.Context, id valueobject.JobID, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return false, nil
+	}
+	return job.AdvanceNextAttempt(at), nil
+}
+
+func (r *MemoryPushJobRepository) FindJobs(ctx context.Context, filter repository.PushJobFilter) ([]*model.PushJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var jobs []*model.PushJob
+	for _, job := range r.jobs {
+		if filter.Status != "" && job.Status() != filter.Status {
+			continue
+		}
+		if filter.UserID != nil && (job.UserID() == nil || !job.UserID().Equals(*filter.UserID)) {
+			continue
+		}
+		if filter.Topic != "" && job.Topic() != filter.Topic {
+			continue
+		}
+		if filter.BeforeID != nil && job.ID().Value() >= filter.BeforeID.Value() {
+			continue
+		}
+		jobs = append(jobs, copyPushJob(job))
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID().Value() > jobs[j].ID().Value()
+	})
+	if filter.Limit > 0 && len(jobs) > filter.Limit {
+		jobs = jobs[:filter.Limit]
+	}
+	return jobs, nil
+}
+
+func (r *MemoryPushJobRepository) Cancel(ctx context.Context, id valueobject.JobID) (*model.PushJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists || !job.IsCancellable() {
+		return nil, nil
+	}
+
+	job.MarkAsCancelled()
+	return copyPushJob(job), nil
+}
+
+// usesTemplate reports whether any job, finished or not, references the
+// template.
+func (r *MemoryPushJobRepository) usesTemplate(key string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, job := range r.jobs {
+		if job.TemplateKey() == key {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *MemoryPushJobRepository) NextIdentity(ctx context.Context) (valueobject.JobID, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -2,10 +2,12 @@ package persistence
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
 )
 
@@ -56,17 +58,52 @@ func (r *MemoryPushLogRepository) FindBySubscriptionID(ctx context.Context, subs
 	return result, nil
 }
 
-func (r *MemoryPushLogRepository) DeleteOldLogs(ctx context.Context, olderThanDays int) error {
+func (r *MemoryPushLogRepository) FindLogs(ctx context.Context, filter repository.PushLogFilter) ([]*model.PushLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*model.PushLog
+	for _, log := range r.logs {
+		if filter.JobID != nil && (log.JobID() == nil || !log.JobID().Equals(*filter.JobID)) {
+			continue
+		}
+		if filter.SubscriptionID != nil && (log.SubscriptionID() == nil || !log.SubscriptionID().Equals(*filter.SubscriptionID)) {
+			continue
+		}
+		if filter.Since != nil && log.CreatedAt().Before(*filter.Since) {
+			continue
+		}
+		if filter.Until != nil && !log.CreatedAt().Before(*filter.Until) {
+			continue
+		}
+		if log.ID() <= filter.AfterID {
+			continue
+		}
+		result = append(result, log)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID() < result[j].ID()
+	})
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (r *MemoryPushLogRepository) DeleteOldLogs(ctx context.Context, olderThanDays int) (int, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	cutoff := time.Now().Add(-time.Duration(olderThanDays) * 24 * time.Hour)
+	deleted := 0
 	for id, log := range r.logs {
 		if log.CreatedAt().Before(cutoff) {
 			delete(r.logs, id)
+			deleted++
 		}
 	}
-	return nil
+	return deleted, nil
 }
 
 func (r *MemoryPushLogRepository) CountSuccessByJobID(ctx context.Context, jobID valueobject.JobID) (int, error) {
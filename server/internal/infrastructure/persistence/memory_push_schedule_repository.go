@@ -0,0 +1,136 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// MemoryPushScheduleRepository stores copies of the schedules, so a caller
+// advancing a schedule it loaded does not change the stored one before
+// RecordRun compares them. Spawned jobs are saved to jobRepo.
+type MemoryPushScheduleRepository struct {
+	mu        sync.RWMutex
+	schedules map[valueobject.ScheduleID]*model.PushSchedule
+	jobRepo   repository.PushJobRepository
+	nextID    int64
+}
+
+func NewMemoryPushScheduleRepository(jobRepo repository.PushJobRepository) *MemoryPushScheduleRepository {
+	return &MemoryPushScheduleRepository{
+		schedules: make(map[valueobject.ScheduleID]*model.PushSchedule),
+		jobRepo:   jobRepo,
+		nextID:    1,
+	}
+}
+
+func (r *MemoryPushScheduleRepository) Save(ctx context.Context, schedule *model.PushSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *schedule
+	r.schedules[schedule.ID()] = &stored
+	return nil
+}
+
+func (r *MemoryPushScheduleRepository) FindByID(ctx context.Context, id valueobject.ScheduleID) (*model.PushSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, exists := r.schedules[id]
+	if !exists {
+		return nil, nil
+	}
+	found := *schedule
+	return &found, nil
+}
+
+func (r *MemoryPushScheduleRepository) FindAll(ctx context.Context) ([]*model.PushSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.PushSchedule, 0, len(r.schedules))
+	for _, schedule := range r.schedules {
+		found := *schedule
+		result = append(result, &found)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID().Value() < result[j].ID().Value()
+	})
+	return result, nil
+}
+
+func (r *MemoryPushScheduleRepository) FindDueSchedules(ctx context.Context, now time.Time, limit int) ([]*model.PushSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*model.PushSchedule
+	for _, schedule := range r.schedules {
+		if schedule.IsDue(now) {
+			found := *schedule
+			result = append(result, &found)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NextRunAt().Before(*result[j].NextRunAt())
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *MemoryPushScheduleRepository) RecordRun(ctx context.Context, schedule *model.PushSchedule, occurrence time.Time, job *model.PushJob) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.schedules[schedule.ID()]
+	if !exists || stored.Status() != model.ScheduleStatusActive ||
+		stored.NextRunAt() == nil || !stored.NextRunAt().Equal(occurrence) {
+		return false, nil
+	}
+
+	if job != nil {
+		if err := r.jobRepo.Save(ctx, job); err != nil {
+			return false, err
+		}
+	}
+	advanced := *schedule
+	r.schedules[schedule.ID()] = &advanced
+	return true, nil
+}
+
+func (r *MemoryPushScheduleRepository) Delete(ctx context.Context, id valueobject.ScheduleID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.schedules, id)
+	return nil
+}
+
+func (r *MemoryPushScheduleRepository) usesTemplate(key string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, schedule := range r.schedules {
+		if schedule.TemplateKey() == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryPushScheduleRepository) NextIdentity(ctx context.Context) (valueobject.ScheduleID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+
+	return valueobject.NewScheduleID(id)
+}
@@ -42,6 +42,18 @@ func (r *MemoryPushSubscriptionRepository) FindByID(ctx context.Context, id valu
 	return subscription, nil
 }
 
+func (r *MemoryPushSubscriptionRepository) FindByPublicID(ctx context.Context, publicID valueobject.PublicSubscriptionID) (*model.PushSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, subscription := range r.subscriptions {
+		if subscription.PublicID().Equals(publicID) {
+			return subscription, nil
+		}
+	}
+	return nil, nil
+}
+
 func (r *MemoryPushSubscriptionRepository) FindByEndpoint(ctx context.Context, endpoint valueobject.PushEndpoint) (*model.PushSubscription, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -93,6 +105,37 @@ func (r *MemoryPushSubscriptionRepository) FindValidSubscriptionsByUserID(ctx co
 	return result, nil
 }
 
+func (r *MemoryPushSubscriptionRepository) FindValidSubscriptionsByUserIDs(ctx context.Context, userIDs []valueobject.UserID) ([]*model.PushSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[valueobject.UserID]bool, len(userIDs))
+	for _, userID := range userIDs {
+		wanted[userID] = true
+	}
+
+	var result []*model.PushSubscription
+	for _, subscription := range r.subscriptions {
+		if subscription.UserID() != nil && wanted[*subscription.UserID()] && subscription.IsValid() && !subscription.IsExpired() {
+			result = append(result, subscription)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryPushSubscriptionRepository) CountValidSubscriptionsByUserIDs(ctx context.Context, userIDs []valueobject.UserID) (map[valueobject.UserID]int, error) {
+	subscriptions, err := r.FindValidSubscriptionsByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[valueobject.UserID]int)
+	for _, subscription := range subscriptions {
+		counts[*subscription.UserID()]++
+	}
+	return counts, nil
+}
+
 func (r *MemoryPushSubscriptionRepository) MarkAsInvalid(ctx context.Context, id valueobject.SubscriptionID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -106,17 +149,44 @@ func (r *MemoryPushSubscriptionRepository) MarkAsInvalid(ctx context.Context, id
 	return nil
 }
 
-func (r *MemoryPushSubscriptionRepository) DeleteExpiredSubscriptions(ctx context.Context) error {
+func (r *MemoryPushSubscriptionRepository) MarkActive(ctx context.Context, id valueobject.SubscriptionID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if subscription, exists := r.subscriptions[id]; exists {
+		subscription.MarkActive(at)
+	}
+	return nil
+}
+
+func (r *MemoryPushSubscriptionRepository) DeleteExpiredSubscriptions(ctx context.Context) (int, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	now := time.Now()
+	deleted := 0
 	for id, subscription := range r.subscriptions {
 		if subscription.ExpirationTime() != nil && now.After(*subscription.ExpirationTime()) {
 			delete(r.subscriptions, id)
+			deleted++
 		}
 	}
-	return nil
+	return deleted, nil
+}
+
+func (r *MemoryPushSubscriptionRepository) DeleteInvalidSubscriptions(ctx context.Context, olderThanDays int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := time.Now().Add(-time.Duration(olderThanDays) * 24 * time.Hour)
+	deleted := 0
+	for id, subscription := range r.subscriptions {
+		if !subscription.IsValid() && subscription.UpdatedAt().Before(cutoff) {
+			delete(r.subscriptions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
 }
 
 func (r *MemoryPushSubscriptionRepository) Delete(ctx context.Context, id valueobject.SubscriptionID) error {
@@ -73,6 +73,19 @@ func (r *MemoryUserRepository) FindAll(ctx context.Context) ([]*model.User, erro
 	return users, nil
 }
 
+func (r *MemoryUserRepository) FindByIDs(ctx context.Context, ids []valueobject.UserID) (map[valueobject.UserID]*model.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	users := make(map[valueobject.UserID]*model.User, len(ids))
+	for _, id := range ids {
+		if user, exists := r.users[id.Value()]; exists {
+			users[id] = user
+		}
+	}
+	return users, nil
+}
+
 func (r *MemoryUserRepository) Delete(ctx context.Context, id valueobject.UserID) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
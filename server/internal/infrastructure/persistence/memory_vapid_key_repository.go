@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+type MemoryVAPIDKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[int64]*model.VAPIDKey
+	nextID int64
+}
+
+func NewMemoryVAPIDKeyRepository() *MemoryVAPIDKeyRepository {
+	return &MemoryVAPIDKeyRepository{
+		keys:   make(map[int64]*model.VAPIDKey),
+		nextID: 1,
+	}
+}
+
+func (r *MemoryVAPIDKeyRepository) Save(ctx context.Context, key *model.VAPIDKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.keys {
+		if id != key.ID() && existing.PublicKey() == key.PublicKey() {
+			return fmt.Errorf("VAPID key already exists")
+		}
+	}
+	r.keys[key.ID()] = key
+	if key.ID() >= r.nextID {
+		r.nextID = key.ID() + 1
+	}
+	return nil
+}
+
+func (r *MemoryVAPIDKeyRepository) FindByID(ctx context.Context, id int64) (*model.VAPIDKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, nil
+	}
+	return key, nil
+}
+
+func (r *MemoryVAPIDKeyRepository) FindByPublicKey(ctx context.Context, publicKey string) (*model.VAPIDKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.PublicKey() == publicKey {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryVAPIDKeyRepository) FindActive(ctx context.Context) (*model.VAPIDKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.IsActive() {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryVAPIDKeyRepository) FindAll(ctx context.Context) ([]*model.VAPIDKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.VAPIDKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		result = append(result, key)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID() < result[j].ID()
+	})
+	return result, nil
+}
+
+func (r *MemoryVAPIDKeyRepository) Activate(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, exists := r.keys[id]
+	if !exists {
+		return fmt.Errorf("VAPID key not found")
+	}
+	for _, key := range r.keys {
+		if key.ID() != id && key.IsActive() {
+			key.Deactivate()
+		}
+	}
+	if !target.IsActive() {
+		target.Activate()
+	}
+	return nil
+}
+
+func (r *MemoryVAPIDKeyRepository) NextIdentity(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+	return id, nil
+}
@@ -0,0 +1,108 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+// rowScanner is satisfied by both pgx.Row and pgx.Rows so scan helpers can be
+// shared between single-row and multi-row queries.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// execer is satisfied by both the pool and a transaction so writes can be
+// shared between standalone saves and multi-statement transactions.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// deleteBatchSize bounds the rows removed by one statement of
+// deleteInBatches.
+const deleteBatchSize = 5000
+
+// deleteInBatches repeats a DELETE whose last parameter is the batch size
+// until it removes fewer rows than that, so purging a large backlog does not
+// hold locks in one long statement. It returns the total removed.
+func deleteInBatches(ctx context.Context, pool *pgxpool.Pool, sql string, args ...any) (int, error) {
+	args = append(args, deleteBatchSize)
+	total := 0
+	for {
+		tag, err := pool.Exec(ctx, sql, args...)
+		if err != nil {
+			return total, err
+		}
+		deleted := int(tag.RowsAffected())
+		total += deleted
+		if deleted < deleteBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func NewPostgresPool(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	return pool, nil
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func nullableUserID(id *valueobject.UserID) *int64 {
+	if id == nil {
+		return nil
+	}
+	v := int64(id.Value())
+	return &v
+}
+
+// userIDValues converts IDs for use as an = ANY($n) array parameter.
+func userIDValues(ids []valueobject.UserID) []int64 {
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		values[i] = int64(id.Value())
+	}
+	return values
+}
+
+func userIDFromNullable(v *int64) (*valueobject.UserID, error) {
+	if v == nil {
+		return nil, nil
+	}
+	id, err := valueobject.NewUserID(int(*v))
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+const apiKeyColumns = `id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at`
+
+type PostgresAPIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresAPIKeyRepository(pool *pgxpool.Pool) *PostgresAPIKeyRepository {
+	return &PostgresAPIKeyRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresAPIKeyRepository) Save(ctx context.Context, key *model.APIKey) error {
+	scopes := make([]string, len(key.Scopes()))
+	for i, scope := range key.Scopes() {
+		scopes[i] = string(scope)
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO api_keys (`+apiKeyColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			scopes = EXCLUDED.scopes,
+			last_used_at = EXCLUDED.last_used_at,
+			revoked_at = EXCLUDED.revoked_at`,
+		key.ID(),
+		key.Name(),
+		key.Prefix(),
+		key.SecretHash(),
+		scopes,
+		key.CreatedAt(),
+		key.LastUsedAt(),
+		key.RevokedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresAPIKeyRepository) FindByID(ctx context.Context, id int64) (*model.APIKey, error) {
+	return r.findOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id)
+}
+
+func (r *PostgresAPIKeyRepository) FindBySecretHash(ctx context.Context, secretHash string) (*model.APIKey, error) {
+	return r.findOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE secret_hash = $1`, secretHash)
+}
+
+func (r *PostgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to update API key last use: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresAPIKeyRepository) FindAll(ctx context.Context) ([]*model.APIKey, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*model.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *PostgresAPIKeyRepository) NextIdentity(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('api_keys', 'id'))`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate API key ID: %w", err)
+	}
+	return id, nil
+}
+
+func (r *PostgresAPIKeyRepository) findOne(ctx context.Context, sql string, args ...any) (*model.APIKey, error) {
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, sql, args...))
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+	return key, nil
+}
+
+func scanAPIKey(row rowScanner) (*model.APIKey, error) {
+	var (
+		id         int64
+		name       string
+		prefix     string
+		secretHash string
+		scopeNames []string
+		createdAt  time.Time
+		lastUsedAt *time.Time
+		revokedAt  *time.Time
+	)
+	if err := row.Scan(&id, &name, &prefix, &secretHash, &scopeNames, &createdAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+
+	scopes := make([]model.APIKeyScope, len(scopeNames))
+	for i, scope := range scopeNames {
+		scopes[i] = model.APIKeyScope(scope)
+	}
+	return model.ReconstructAPIKey(id, name, prefix, secretHash, scopes, createdAt, lastUsedAt, revokedAt), nil
+}
@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// jobReadyChannel is the LISTEN/NOTIFY channel; payloads are the ready time
+// in unix milliseconds.
+const jobReadyChannel = "push_job_ready"
+
+// listenRetryDelay is how long Subscribe waits before reconnecting after
+// its listening connection fails.
+const listenRetryDelay = 5 * time.Second
+
+// PostgresJobNotifier implements repository.JobNotifier with LISTEN/NOTIFY,
+// so a job created by one replica wakes the dispatchers of all of them.
+type PostgresJobNotifier struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresJobNotifier(pool *pgxpool.Pool) *PostgresJobNotifier {
+	return &PostgresJobNotifier{pool: pool}
+}
+
+func (n *PostgresJobNotifier) NotifyJobReady(ctx context.Context, readyAt time.Time) error {
+	payload := strconv.FormatInt(readyAt.UnixMilli(), 10)
+	if _, err := n.pool.Exec(ctx, `SELECT pg_notify($1, $2)`, jobReadyChannel, payload); err != nil {
+		return fmt.Errorf("failed to notify job ready: %w", err)
+	}
+	return nil
+}
+
+// Subscribe listens on a connection taken out of the pool. If it fails, it
+// reconnects and delivers the current time, since notifications sent in the
+// meantime were lost.
+func (n *PostgresJobNotifier) Subscribe(ctx context.Context) (<-chan time.Time, error) {
+	conn, err := n.listen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan time.Time, notificationBuffer)
+	go func() {
+		defer close(ch)
+		for {
+			n.receive(ctx, conn, ch)
+			conn.Close(context.Background())
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(listenRetryDelay):
+				}
+				if conn, err = n.listen(ctx); err == nil {
+					break
+				}
+				log.Printf("Failed to listen for job notifications: %v", err)
+			}
+			offer(ch, time.Now())
+		}
+	}()
+	return ch, nil
+}
+
+func (n *PostgresJobNotifier) listen(ctx context.Context) (*pgx.Conn, error) {
+	pooled, err := n.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	conn := pooled.Hijack()
+	if _, err := conn.Exec(ctx, "LISTEN "+jobReadyChannel); err != nil {
+		conn.Close(context.Background())
+		return nil, fmt.Errorf("failed to listen for job notifications: %w", err)
+	}
+	return conn, nil
+}
+
+// receive forwards notifications until ctx is cancelled or the connection
+// fails.
+func (n *PostgresJobNotifier) receive(ctx context.Context, conn *pgx.Conn, ch chan<- time.Time) {
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Lost job notification connection: %v", err)
+			}
+			return
+		}
+
+		readyAt, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			log.Printf("Ignoring malformed job notification %q", notification.Payload)
+			continue
+		}
+		offer(ch, time.UnixMilli(readyAt))
+	}
+}
+
+// offer sends without blocking; a full buffer means the subscriber already
+// has a dispatch pending.
+func offer(ch chan<- time.Time, readyAt time.Time) {
+	select {
+	case ch <- readyAt:
+	default:
+	}
+}
@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockNamespace is the first key of the two-key advisory locks taken by
+// PostgresLocker, keeping them apart from the migrator's single-key lock.
+const lockNamespace int32 = 0x6b6f7474
+
+// PostgresLocker implements repository.Locker with session-level advisory
+// locks, held on a dedicated connection until released. If the process dies
+// the connection closes and Postgres releases the lock.
+type PostgresLocker struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresLocker(pool *pgxpool.Pool) *PostgresLocker {
+	return &PostgresLocker{pool: pool}
+}
+
+func (l *PostgresLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	var acquired bool
+	err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1, hashtext($2))`, lockNamespace, name).Scan(&acquired)
+	if err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("failed to try lock %q: %w", name, err)
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	release := func() {
+		ctx := context.Background()
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1, hashtext($2))`, lockNamespace, name); err != nil {
+			// The session may still hold the lock; closing the connection
+			// releases it instead of returning it to the pool.
+			log.Printf("Failed to release lock %q, closing its connection: %v", name, err)
+			if err := conn.Hijack().Close(ctx); err != nil {
+				log.Printf("Failed to close connection of lock %q: %v", name, err)
+			}
+			return
+		}
+		conn.Release()
+	}
+	return release, true, nil
+}
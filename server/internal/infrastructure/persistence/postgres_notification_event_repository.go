@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type PostgresNotificationEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresNotificationEventRepository(pool *pgxpool.Pool) *PostgresNotificationEventRepository {
+	return &PostgresNotificationEventRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresNotificationEventRepository) Save(ctx context.Context, event *model.NotificationEvent) error {
+	var subscriptionID *int64
+	if event.SubscriptionID() != nil {
+		v := event.SubscriptionID().Value()
+		subscriptionID = &v
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_events (id, job_id, subscription_id, event_type, url, occurred_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING`,
+		event.ID(),
+		event.JobID().Value(),
+		subscriptionID,
+		string(event.Type()),
+		nullableString(event.URL()),
+		event.OccurredAt(),
+		event.CreatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification event: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresNotificationEventRepository) CountByJobID(ctx context.Context, jobID valueobject.JobID) (model.EngagementCounts, error) {
+	// Each subscription counts once per event type. Events whose
+	// subscription has since been deleted are not counted.
+	rows, err := r.pool.Query(ctx, `
+		SELECT event_type, count(DISTINCT subscription_id)
+		FROM notification_events
+		WHERE job_id = $1
+		GROUP BY event_type`, jobID.Value())
+	if err != nil {
+		return model.EngagementCounts{}, fmt.Errorf("failed to count notification events: %w", err)
+	}
+	defer rows.Close()
+
+	var counts model.EngagementCounts
+	for rows.Next() {
+		var eventType string
+		var count int
+		if err := rows.Scan(&eventType, &count); err != nil {
+			return model.EngagementCounts{}, fmt.Errorf("failed to scan notification event count: %w", err)
+		}
+		switch model.NotificationEventType(eventType) {
+		case model.NotificationEventDelivered:
+			counts.Delivered = count
+		case model.NotificationEventDisplayed:
+			counts.Displayed = count
+		case model.NotificationEventClicked:
+			counts.Clicked = count
+		case model.NotificationEventClosed:
+			counts.Closed = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return model.EngagementCounts{}, fmt.Errorf("failed to iterate notification event counts: %w", err)
+	}
+	return counts, nil
+}
+
+func (r *PostgresNotificationEventRepository) NextIdentity(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('notification_events', 'id'))`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate notification event ID: %w", err)
+	}
+	return id, nil
+}
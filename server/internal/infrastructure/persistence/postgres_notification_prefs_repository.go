@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+const notificationPrefsColumns = `user_id, enabled, topics, quiet_hours, updated_at`
+
+// quietHoursJSON is the stored shape of notification_prefs.quiet_hours.
+type quietHoursJSON struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+type PostgresNotificationPrefsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresNotificationPrefsRepository(pool *pgxpool.Pool) *PostgresNotificationPrefsRepository {
+	return &PostgresNotificationPrefsRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresNotificationPrefsRepository) Save(ctx context.Context, prefs *model.NotificationPrefs) error {
+	topics, err := json.Marshal(prefs.Topics())
+	if err != nil {
+		return fmt.Errorf("failed to marshal topics: %w", err)
+	}
+
+	var quietHours []byte
+	if q := prefs.QuietHours(); q != nil {
+		quietHours, err = json.Marshal(quietHoursJSON{Start: q.Start(), End: q.End(), Timezone: q.Timezone()})
+		if err != nil {
+			return fmt.Errorf("failed to marshal quiet hours: %w", err)
+		}
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO notification_prefs (`+notificationPrefsColumns+`)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			topics = EXCLUDED.topics,
+			quiet_hours = EXCLUDED.quiet_hours,
+			updated_at = EXCLUDED.updated_at`,
+		prefs.UserID().Value(),
+		prefs.Enabled(),
+		topics,
+		quietHours,
+		prefs.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification prefs: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresNotificationPrefsRepository) FindByUserID(ctx context.Context, userID valueobject.UserID) (*model.NotificationPrefs, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+notificationPrefsColumns+` FROM notification_prefs WHERE user_id = $1`, userID.Value())
+	prefs, err := scanNotificationPrefs(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification prefs: %w", err)
+	}
+	return prefs, nil
+}
+
+func (r *PostgresNotificationPrefsRepository) FindByUserIDs(ctx context.Context, userIDs []valueobject.UserID) (map[valueobject.UserID]*model.NotificationPrefs, error) {
+	result := make(map[valueobject.UserID]*model.NotificationPrefs)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int64, len(userIDs))
+	for i, userID := range userIDs {
+		ids[i] = int64(userID.Value())
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+notificationPrefsColumns+` FROM notification_prefs WHERE user_id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification prefs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		prefs, err := scanNotificationPrefs(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification prefs: %w", err)
+		}
+		result[prefs.UserID()] = prefs
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification prefs: %w", err)
+	}
+	return result, nil
+}
+
+func scanNotificationPrefs(row rowScanner) (*model.NotificationPrefs, error) {
+	var (
+		userIDValue    int64
+		enabled        bool
+		topicsJSON     []byte
+		quietHoursData []byte
+		updatedAt      time.Time
+	)
+	if err := row.Scan(&userIDValue, &enabled, &topicsJSON, &quietHoursData, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	userID, err := valueobject.NewUserID(int(userIDValue))
+	if err != nil {
+		return nil, err
+	}
+
+	var topics map[string]bool
+	if err := json.Unmarshal(topicsJSON, &topics); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal topics: %w", err)
+	}
+
+	var quietHours *valueobject.QuietHours
+	if len(quietHoursData) > 0 && string(quietHoursData) != "null" {
+		var stored quietHoursJSON
+		if err := json.Unmarshal(quietHoursData, &stored); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal quiet hours: %w", err)
+		}
+		q, err := valueobject.NewQuietHours(stored.Start, stored.End, stored.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		quietHours = &q
+	}
+
+	return model.ReconstructNotificationPrefs(userID, enabled, topics, quietHours, updatedAt), nil
+}
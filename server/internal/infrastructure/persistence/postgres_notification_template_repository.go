@@ -0,0 +1,149 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+const notificationTemplateColumns = `id, key, title, body, url, icon, data, created_at, updated_at`
+
+// PostgreSQL SQLSTATEs for constraint violations.
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+type PostgresNotificationTemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresNotificationTemplateRepository(pool *pgxpool.Pool) *PostgresNotificationTemplateRepository {
+	return &PostgresNotificationTemplateRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresNotificationTemplateRepository) Save(ctx context.Context, template *model.NotificationTemplate) error {
+	var data []byte
+	if template.Data() != nil {
+		var err error
+		data, err = json.Marshal(template.Data())
+		if err != nil {
+			return fmt.Errorf("failed to marshal template data: %w", err)
+		}
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_templates (`+notificationTemplateColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			title = EXCLUDED.title,
+			body = EXCLUDED.body,
+			url = EXCLUDED.url,
+			icon = EXCLUDED.icon,
+			data = EXCLUDED.data,
+			updated_at = EXCLUDED.updated_at`,
+		template.ID(),
+		template.Key(),
+		template.Title(),
+		template.Body(),
+		nullableString(template.URL()),
+		nullableString(template.Icon()),
+		data,
+		template.CreatedAt(),
+		template.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification template: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresNotificationTemplateRepository) FindByKey(ctx context.Context, key string) (*model.NotificationTemplate, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+notificationTemplateColumns+` FROM notification_templates WHERE key = $1`, key)
+	template, err := scanNotificationTemplate(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification template: %w", err)
+	}
+	return template, nil
+}
+
+func (r *PostgresNotificationTemplateRepository) FindAll(ctx context.Context) ([]*model.NotificationTemplate, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+notificationTemplateColumns+` FROM notification_templates ORDER BY key`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]*model.NotificationTemplate, 0)
+	for rows.Next() {
+		template, err := scanNotificationTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *PostgresNotificationTemplateRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM notification_templates WHERE key = $1`, key)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return errors.ErrTemplateInUse
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete notification template: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresNotificationTemplateRepository) NextIdentity(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('notification_templates', 'id'))`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate notification template ID: %w", err)
+	}
+	return id, nil
+}
+
+func scanNotificationTemplate(row rowScanner) (*model.NotificationTemplate, error) {
+	var (
+		id        int64
+		key       string
+		title     string
+		body      string
+		url       *string
+		icon      *string
+		dataJSON  []byte
+		createdAt time.Time
+		updatedAt time.Time
+	)
+	if err := row.Scan(&id, &key, &title, &body, &url, &icon, &dataJSON, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if len(dataJSON) > 0 {
+		if err := json.Unmarshal(dataJSON, &data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal template data: %w", err)
+		}
+	}
+
+	return model.ReconstructNotificationTemplate(id, key, title, body, stringValue(url), stringValue(icon), data, createdAt, updatedAt), nil
+}
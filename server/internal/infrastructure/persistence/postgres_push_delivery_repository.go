@@ -0,0 +1,266 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+const pushDeliveryColumns = `id, job_id, subscription_id, status, attempt_count, next_attempt_at, last_status, last_error, created_at, updated_at`
+
+// unleasedDeliveryCondition matches deliveries no worker is sending.
+const unleasedDeliveryCondition = `(lease_expires_at IS NULL OR lease_expires_at <= now())`
+
+type PostgresPushDeliveryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresPushDeliveryRepository(pool *pgxpool.Pool) *PostgresPushDeliveryRepository {
+	return &PostgresPushDeliveryRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresPushDeliveryRepository) CreateForJob(ctx context.Context, jobID valueobject.JobID, subscriptionIDs []valueobject.SubscriptionID) error {
+	if len(subscriptionIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(subscriptionIDs))
+	for i, id := range subscriptionIDs {
+		ids[i] = id.Value()
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO push_deliveries (job_id, subscription_id)
+		SELECT $1, unnest($2::bigint[])
+		ON CONFLICT (job_id, subscription_id) DO NOTHING`,
+		jobID.Value(), ids)
+	if err != nil {
+		return fmt.Errorf("failed to create push deliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushDeliveryRepository) Save(ctx context.Context, delivery *model.PushDelivery) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE push_deliveries SET
+			status = $2,
+			attempt_count = $3,
+			next_attempt_at = $4,
+			last_status = $5,
+			last_error = $6,
+			updated_at = $7
+		WHERE id = $1`,
+		delivery.ID(),
+		string(delivery.Status()),
+		delivery.AttemptCount(),
+		delivery.NextAttemptAt(),
+		delivery.LastStatus(),
+		nullableString(delivery.LastError()),
+		delivery.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("delivery not found")
+	}
+	return nil
+}
+
+func (r *PostgresPushDeliveryRepository) Hold(ctx context.Context, id int64, until time.Time, reason string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE push_deliveries SET next_attempt_at = $2, last_error = $3, updated_at = now()
+		WHERE id = $1 AND status = 'pending'
+			AND (next_attempt_at IS NULL OR next_attempt_at <= now())
+			AND `+unleasedDeliveryCondition,
+		id, until, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to hold push delivery: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *PostgresPushDeliveryRepository) Claim(ctx context.Context, id int64, owner string, leaseDuration time.Duration) (*model.PushDelivery, error) {
+	deliveries, err := r.query(ctx, `
+		UPDATE push_deliveries SET
+			lease_owner = $2,
+			lease_expires_at = now() + make_interval(secs => $3),
+			updated_at = now()
+		WHERE id = $1 AND status = 'pending' AND `+unleasedDeliveryCondition+`
+		RETURNING `+pushDeliveryColumns,
+		id, owner, leaseDuration.Seconds())
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+	return deliveries[0], nil
+}
+
+func (r *PostgresPushDeliveryRepository) SaveClaimed(ctx context.Context, delivery *model.PushDelivery, owner string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE push_deliveries SET
+			status = $3,
+			attempt_count = $4,
+			next_attempt_at = $5,
+			last_status = $6,
+			last_error = $7,
+			lease_owner = NULL,
+			lease_expires_at = NULL,
+			updated_at = $8
+		WHERE id = $1 AND lease_owner = $2`,
+		delivery.ID(),
+		owner,
+		string(delivery.Status()),
+		delivery.AttemptCount(),
+		delivery.NextAttemptAt(),
+		delivery.LastStatus(),
+		nullableString(delivery.LastError()),
+		delivery.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrDeliveryLeaseLost
+	}
+	return nil
+}
+
+func (r *PostgresPushDeliveryRepository) FindByID(ctx context.Context, id int64) (*model.PushDelivery, error) {
+	deliveries, err := r.query(ctx, `
+		SELECT `+pushDeliveryColumns+` FROM push_deliveries
+		WHERE id = $1`, id)
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+	return deliveries[0], nil
+}
+
+func (r *PostgresPushDeliveryRepository) FindByJobAndSubscription(ctx context.Context, jobID valueobject.JobID, subscriptionID valueobject.SubscriptionID) (*model.PushDelivery, error) {
+	deliveries, err := r.query(ctx, `
+		SELECT `+pushDeliveryColumns+` FROM push_deliveries
+		WHERE job_id = $1 AND subscription_id = $2`, jobID.Value(), subscriptionID.Value())
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+	return deliveries[0], nil
+}
+
+func (r *PostgresPushDeliveryRepository) FindByJobID(ctx context.Context, jobID valueobject.JobID) ([]*model.PushDelivery, error) {
+	return r.query(ctx, `
+		SELECT `+pushDeliveryColumns+` FROM push_deliveries
+		WHERE job_id = $1
+		ORDER BY id`, jobID.Value())
+}
+
+func (r *PostgresPushDeliveryRepository) FindDueByJobID(ctx context.Context, jobID valueobject.JobID, now time.Time) ([]*model.PushDelivery, error) {
+	return r.query(ctx, `
+		SELECT `+pushDeliveryColumns+` FROM push_deliveries
+		WHERE job_id = $1 AND status = 'pending' AND (next_attempt_at IS NULL OR next_attempt_at <= $2)
+			AND (lease_expires_at IS NULL OR lease_expires_at <= $2)
+		ORDER BY id`, jobID.Value(), now)
+}
+
+func (r *PostgresPushDeliveryRepository) CountByJobID(ctx context.Context, jobID valueobject.JobID) (model.DeliveryCounts, error) {
+	var counts model.DeliveryCounts
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			count(*) FILTER (WHERE status = 'pending'),
+			count(*) FILTER (WHERE status = 'succeeded'),
+			count(*) FILTER (WHERE status = 'failed'),
+			count(*) FILTER (WHERE status = 'gone'),
+			count(*) FILTER (WHERE status = 'skipped')
+		FROM push_deliveries
+		WHERE job_id = $1`, jobID.Value()).Scan(&counts.Pending, &counts.Succeeded, &counts.Failed, &counts.Gone, &counts.Skipped)
+	if err != nil {
+		return model.DeliveryCounts{}, fmt.Errorf("failed to count push deliveries: %w", err)
+	}
+	return counts, nil
+}
+
+func (r *PostgresPushDeliveryRepository) NextAttemptAt(ctx context.Context, jobID valueobject.JobID) (*time.Time, error) {
+	var next *time.Time
+	err := r.pool.QueryRow(ctx, `
+		SELECT min(greatest(COALESCE(next_attempt_at, now()), lease_expires_at))
+		FROM push_deliveries
+		WHERE job_id = $1 AND status = 'pending'`, jobID.Value()).Scan(&next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find next delivery attempt: %w", err)
+	}
+	return next, nil
+}
+
+func (r *PostgresPushDeliveryRepository) query(ctx context.Context, sql string, args ...any) ([]*model.PushDelivery, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return collectPushDeliveries(rows)
+}
+
+func collectPushDeliveries(rows pgx.Rows) ([]*model.PushDelivery, error) {
+	var result []*model.PushDelivery
+	for rows.Next() {
+		delivery, err := scanPushDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan push delivery: %w", err)
+		}
+		result = append(result, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate push deliveries: %w", err)
+	}
+	return result, nil
+}
+
+func scanPushDelivery(row rowScanner) (*model.PushDelivery, error) {
+	var (
+		id             int64
+		jobID          int64
+		subscriptionID int64
+		status         string
+		attemptCount   int
+		nextAttemptAt  *time.Time
+		lastStatus     *int
+		lastError      *string
+		createdAt      time.Time
+		updatedAt      time.Time
+	)
+	err := row.Scan(&id, &jobID, &subscriptionID, &status, &attemptCount, &nextAttemptAt,
+		&lastStatus, &lastError, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	jid, err := valueobject.NewJobID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	sid, err := valueobject.NewSubscriptionID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ReconstructPushDelivery(
+		id,
+		jid,
+		sid,
+		model.DeliveryStatus(status),
+		attemptCount,
+		nextAttemptAt,
+		lastStatus,
+		stringValue(lastError),
+		createdAt,
+		updatedAt,
+	), nil
+}
@@ -0,0 +1,653 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+const pushJobColumns = `id, idempotency_key, user_id, topic, urgency, ttl_seconds, payload, template_key, template_vars, segment, recipient_count, schedule_at, status::text, retry_count, last_error, next_attempt_at, lease_owner, lease_expires_at, created_at, updated_at`
+
+// claimableJobCondition matches jobs that are due for delivery plus jobs whose
+// sender stopped renewing its lease.
+const claimableJobCondition = `
+	(status = 'pending' AND (schedule_at IS NULL OR schedule_at <= now())
+		AND (next_attempt_at IS NULL OR next_attempt_at <= now()))
+	OR (status = 'sending' AND (lease_expires_at IS NULL OR lease_expires_at < now()))`
+
+type PostgresPushJobRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresPushJobRepository(pool *pgxpool.Pool) *PostgresPushJobRepository {
+	return &PostgresPushJobRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresPushJobRepository) Save(ctx context.Context, job *model.PushJob) error {
+	return savePushJob(ctx, r.pool, job)
+}
+
+// CreateBatch inserts the job and its recipients in one transaction, so a
+// sender never claims a batch whose recipient list is incomplete. The
+// recipients are streamed with COPY rather than one INSERT per user.
+func (r *PostgresPushJobRepository) CreateBatch(ctx context.Context, job *model.PushJob, recipients []valueobject.UserID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The only unique key the insert can break is idempotency_key; a
+	// concurrent request with the same key got there first.
+	err = savePushJob(ctx, tx, job)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return errors.ErrDuplicateIdempotencyKey
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"push_job_recipients"},
+		[]string{"job_id", "user_id"},
+		pgx.CopyFromSlice(len(recipients), func(i int) ([]any, error) {
+			return []any{job.ID().Value(), int64(recipients[i].Value())}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push job recipients: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresPushJobRepository) FindRecipients(ctx context.Context, id valueobject.JobID) ([]valueobject.UserID, error) {
+	rows, err := r.pool.Query(ctx, `SELECT user_id FROM push_job_recipients WHERE job_id = $1 ORDER BY user_id`, id.Value())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push job recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []valueobject.UserID
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan push job recipient: %w", err)
+		}
+		id, err := valueobject.NewUserID(int(userID))
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate push job recipients: %w", err)
+	}
+	return recipients, nil
+}
+
+func savePushJob(ctx context.Context, db execer, job *model.PushJob) error {
+	payload, err := job.Payload().ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal push job payload: %w", err)
+	}
+
+	var templateVars []byte
+	if job.TemplateVars() != nil {
+		templateVars, err = json.Marshal(job.TemplateVars())
+		if err != nil {
+			return fmt.Errorf("failed to marshal push job template variables: %w", err)
+		}
+	}
+
+	var segment []byte
+	if job.Segment() != nil {
+		segment, err = json.Marshal(toSegmentRecord(job.Segment()))
+		if err != nil {
+			return fmt.Errorf("failed to marshal push job segment: %w", err)
+		}
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO push_jobs (id, idempotency_key, user_id, topic, urgency, ttl_seconds, payload,
+			template_key, template_vars, segment, recipient_count, schedule_at, status, retry_count, last_error,
+			next_attempt_at, lease_owner, lease_expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		ON CONFLICT (id) DO UPDATE SET
+			idempotency_key = EXCLUDED.idempotency_key,
+			user_id = EXCLUDED.user_id,
+			topic = EXCLUDED.topic,
+			urgency = EXCLUDED.urgency,
+			ttl_seconds = EXCLUDED.ttl_seconds,
+			payload = EXCLUDED.payload,
+			template_key = EXCLUDED.template_key,
+			template_vars = EXCLUDED.template_vars,
+			segment = EXCLUDED.segment,
+			recipient_count = EXCLUDED.recipient_count,
+			schedule_at = EXCLUDED.schedule_at,
+			status = EXCLUDED.status,
+			retry_count = EXCLUDED.retry_count,
+			last_error = EXCLUDED.last_error,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			lease_owner = EXCLUDED.lease_owner,
+			lease_expires_at = EXCLUDED.lease_expires_at,
+			updated_at = EXCLUDED.updated_at`,
+		job.ID().Value(),
+		nullableString(job.IdempotencyKey()),
+		nullableUserID(job.UserID()),
+		nullableString(job.Topic()),
+		string(job.Urgency()),
+		job.TTLSeconds(),
+		payload,
+		nullableString(job.TemplateKey()),
+		templateVars,
+		segment,
+		job.RecipientCount(),
+		job.ScheduleAt(),
+		string(job.Status()),
+		job.RetryCount(),
+		nullableString(job.LastError()),
+		job.NextAttemptAt(),
+		nullableString(job.LeaseOwner()),
+		job.LeaseExpiresAt(),
+		job.CreatedAt(),
+		job.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push job: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushJobRepository) FindByID(ctx context.Context, id valueobject.JobID) (*model.PushJob, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+pushJobColumns+` FROM push_jobs WHERE id = $1`, id.Value())
+	job, err := scanPushJob(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *PostgresPushJobRepository) FindByIdempotencyKey(ctx context.Context, key string) (*model.PushJob, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	row := r.pool.QueryRow(ctx, `SELECT `+pushJobColumns+` FROM push_jobs WHERE idempotency_key = $1`, key)
+	job, err := scanPushJob(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push job by idempotency key: %w", err)
+	}
+	return job, nil
+}
+
+func (r *PostgresPushJobRepository) FindPendingJobs(ctx context.Context, limit int) ([]*model.PushJob, error) {
+	return r.query(ctx, `
+		SELECT `+pushJobColumns+` FROM push_jobs
+		WHERE status = 'pending'
+		ORDER BY id
+		LIMIT $1`, limit)
+}
+
+func (r *PostgresPushJobRepository) FindReadyToSendJobs(ctx context.Context, limit int) ([]*model.PushJob, error) {
+	return r.query(ctx, `
+		SELECT `+pushJobColumns+` FROM push_jobs
+		WHERE status = 'pending' AND (schedule_at IS NULL OR schedule_at <= now())
+			AND (next_attempt_at IS NULL OR next_attempt_at <= now())
+		ORDER BY id
+		LIMIT $1`, limit)
+}
+
+func (r *PostgresPushJobRepository) FindFailedJobsForRetry(ctx context.Context, maxRetries int, limit int) ([]*model.PushJob, error) {
+	return r.query(ctx, `
+		SELECT `+pushJobColumns+` FROM push_jobs
+		WHERE status = 'failed' AND retry_count < $1
+		ORDER BY updated_at
+		LIMIT $2`, maxRetries, limit)
+}
+
+func (r *PostgresPushJobRepository) UpdateStatus(ctx context.Context, id valueobject.JobID, status model.JobStatus, lastError string) error {
+	return r.update(ctx, id, func(job *model.PushJob) {
+		switch status {
+		case model.JobStatusSending:
+			job.MarkAsSending()
+		case model.JobStatusSucceeded:
+			job.MarkAsSucceeded()
+		case model.JobStatusFailed:
+			job.MarkAsFailed(lastError)
+		case model.JobStatusCancelled:
+			job.MarkAsCancelled()
+		}
+	})
+}
+
+func (r *PostgresPushJobRepository) IncrementRetryCount(ctx context.Context, id valueobject.JobID) error {
+	return r.update(ctx, id, func(job *model.PushJob) {
+		job.MarkAsFailed(job.LastError())
+	})
+}
+
+func (r *PostgresPushJobRepository) Delete(ctx context.Context, id valueobject.JobID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM push_jobs WHERE id = $1`, id.Value())
+	if err != nil {
+		return fmt.Errorf("failed to delete push job: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushJobRepository) DeleteOldCompletedJobs(ctx context.Context, olderThanDays int) (int, error) {
+	deleted, err := deleteInBatches(ctx, r.pool, `
+		DELETE FROM push_jobs WHERE id IN (
+			SELECT id FROM push_jobs
+			WHERE status IN ('succeeded', 'failed', 'cancelled') AND updated_at < now() - make_interval(days => $1)
+			LIMIT $2)`,
+		olderThanDays)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete old completed push jobs: %w", err)
+	}
+	return deleted, nil
+}
+
+func (r *PostgresPushJobRepository) NextIdentity(ctx context.Context) (valueobject.JobID, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('push_jobs', 'id'))`).Scan(&id)
+	if err != nil {
+		return valueobject.JobID{}, fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return valueobject.NewJobID(id)
+}
+
+func (r *PostgresPushJobRepository) FindJobs(ctx context.Context, filter repository.PushJobFilter) ([]*model.PushJob, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Status != "" {
+		addCondition("status::text = $%d", string(filter.Status))
+	}
+	if filter.UserID != nil {
+		addCondition("user_id = $%d", int64(filter.UserID.Value()))
+	}
+	if filter.Topic != "" {
+		addCondition("topic = $%d", filter.Topic)
+	}
+	if filter.BeforeID != nil {
+		addCondition("id < $%d", filter.BeforeID.Value())
+	}
+
+	sql := `SELECT ` + pushJobColumns + ` FROM push_jobs`
+	if len(conditions) > 0 {
+		sql += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	sql += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		sql += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	return r.query(ctx, sql, args...)
+}
+
+func (r *PostgresPushJobRepository) Cancel(ctx context.Context, id valueobject.JobID) (*model.PushJob, error) {
+	row := r.pool.QueryRow(ctx, `
+		UPDATE push_jobs SET
+			status = 'cancelled',
+			lease_owner = NULL,
+			lease_expires_at = NULL,
+			updated_at = now()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+pushJobColumns,
+		id.Value())
+	job, err := scanPushJob(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel push job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *PostgresPushJobRepository) ClaimReadyJobs(ctx context.Context, owner string, leaseDuration time.Duration, limit int) ([]*model.PushJob, error) {
+	return r.query(ctx, `
+		UPDATE push_jobs SET
+			status = 'sending',
+			lease_owner = $1,
+			lease_expires_at = now() + make_interval(secs => $2),
+			updated_at = now()
+		WHERE id IN (
+			SELECT id FROM push_jobs
+			WHERE `+claimableJobCondition+`
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+pushJobColumns,
+		owner, leaseDuration.Seconds(), limit)
+}
+
+func (r *PostgresPushJobRepository) ClaimJob(ctx context.Context, id valueobject.JobID, owner string, leaseDuration time.Duration, expected model.JobStatus) (*model.PushJob, error) {
+	row := r.pool.QueryRow(ctx, `
+		UPDATE push_jobs SET
+			status = 'sending',
+			lease_owner = $2,
+			lease_expires_at = now() + make_interval(secs => $3),
+			updated_at = now()
+		WHERE id = $1 AND status::text = $4
+		RETURNING `+pushJobColumns,
+		id.Value(), owner, leaseDuration.Seconds(), string(expected))
+	job, err := scanPushJob(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim push job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *PostgresPushJobRepository) RenewLease(ctx context.Context, id valueobject.JobID, owner string, leaseDuration time.Duration) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE push_jobs SET lease_expires_at = now() + make_interval(secs => $3)
+		WHERE id = $1 AND lease_owner = $2 AND status = 'sending'`,
+		id.Value(), owner, leaseDuration.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to renew push job lease: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrJobLeaseLost
+	}
+	return nil
+}
+
+func (r *PostgresPushJobRepository) NextClaimableAt(ctx context.Context) (*time.Time, error) {
+	var next *time.Time
+	err := r.pool.QueryRow(ctx, `
+		SELECT min(at) FROM (
+			SELECT greatest(schedule_at, next_attempt_at) AS at FROM push_jobs WHERE status = 'pending'
+			UNION ALL
+			SELECT lease_expires_at FROM push_jobs WHERE status = 'sending'
+		) claimable
+		WHERE at > now()`).Scan(&next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find next claimable push job: %w", err)
+	}
+	return next, nil
+}
+
+func (r *PostgresPushJobRepository) SaveClaimed(ctx context.Context, job *model.PushJob, owner string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE push_jobs SET status = $3, retry_count = $4, last_error = $5, next_attempt_at = $6,
+			lease_owner = $7, lease_expires_at = $8, updated_at = $9
+		WHERE id = $1 AND lease_owner = $2 AND status = 'sending'`,
+		job.ID().Value(),
+		owner,
+		string(job.Status()),
+		job.RetryCount(),
+		nullableString(job.LastError()),
+		job.NextAttemptAt(),
+		nullableString(job.LeaseOwner()),
+		job.LeaseExpiresAt(),
+		job.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrJobLeaseLost
+	}
+	return nil
+}
+
+func (r *PostgresPushJobRepository) ReleaseLease(ctx context.Context, id valueobject.JobID, owner string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE push_jobs SET
+			status = 'pending',
+			lease_owner = NULL,
+			lease_expires_at = NULL,
+			updated_at = now()
+		WHERE id = $1 AND lease_owner = $2 AND status = 'sending'`,
+		id.Value(), owner)
+	if err != nil {
+		return fmt.Errorf("failed to release push job lease: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrJobLeaseLost
+	}
+	return nil
+}
+
+func (r *PostgresPushJobRepository) AdvanceNextAttempt(ctx context.Context, id valueobject.JobID, at time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE push_jobs SET
+			next_attempt_at = $2,
+			updated_at = now()
+		WHERE id = $1 AND status = 'pending' AND next_attempt_at > $2`,
+		id.Value(), at)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance push job: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// update loads the job under a row lock, applies the domain transition and
+// writes it back so status changes follow the same rules as the model.
+func (r *PostgresPushJobRepository) update(ctx context.Context, id valueobject.JobID, apply func(job *model.PushJob)) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, `SELECT `+pushJobColumns+` FROM push_jobs WHERE id = $1 FOR UPDATE`, id.Value())
+	job, err := scanPushJob(row)
+	if isNoRows(err) {
+		return fmt.Errorf("job not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load push job: %w", err)
+	}
+
+	apply(job)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE push_jobs SET status = $2, retry_count = $3, last_error = $4, next_attempt_at = $5,
+			lease_owner = $6, lease_expires_at = $7, updated_at = $8
+		WHERE id = $1`,
+		job.ID().Value(),
+		string(job.Status()),
+		job.RetryCount(),
+		nullableString(job.LastError()),
+		job.NextAttemptAt(),
+		nullableString(job.LeaseOwner()),
+		job.LeaseExpiresAt(),
+		job.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update push job: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresPushJobRepository) query(ctx context.Context, sql string, args ...any) ([]*model.PushJob, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push jobs: %w", err)
+	}
+	defer rows.Close()
+
+	return collectPushJobs(rows)
+}
+
+func collectPushJobs(rows pgx.Rows) ([]*model.PushJob, error) {
+	var result []*model.PushJob
+	for rows.Next() {
+		job, err := scanPushJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan push job: %w", err)
+		}
+		result = append(result, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate push jobs: %w", err)
+	}
+	return result, nil
+}
+
+func scanPushJob(row rowScanner) (*model.PushJob, error) {
+	var (
+		id             int64
+		idempotencyKey *string
+		userID         *int64
+		topic          *string
+		urgency        *string
+		ttlSeconds     *int
+		payloadJSON    []byte
+		templateKey    *string
+		templateVars   []byte
+		segmentJSON    []byte
+		recipientCount int
+		scheduleAt     *time.Time
+		status         string
+		retryCount     int
+		lastError      *string
+		nextAttemptAt  *time.Time
+		leaseOwner     *string
+		leaseExpiresAt *time.Time
+		createdAt      time.Time
+		updatedAt      time.Time
+	)
+	err := row.Scan(&id, &idempotencyKey, &userID, &topic, &urgency, &ttlSeconds, &payloadJSON,
+		&templateKey, &templateVars, &segmentJSON, &recipientCount, &scheduleAt, &status, &retryCount, &lastError, &nextAttemptAt, &leaseOwner, &leaseExpiresAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	jobID, err := valueobject.NewJobID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := userIDFromNullable(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload model.PushPayload
+	if len(payloadJSON) > 0 {
+		if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal push job payload: %w", err)
+		}
+	}
+
+	var vars map[string]string
+	if len(templateVars) > 0 {
+		if err := json.Unmarshal(templateVars, &vars); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal push job template variables: %w", err)
+		}
+	}
+
+	var segment *model.Segment
+	if len(segmentJSON) > 0 {
+		var record segmentRecord
+		if err := json.Unmarshal(segmentJSON, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal push job segment: %w", err)
+		}
+		segment, err = record.toModel()
+		if err != nil {
+			return nil, fmt.Errorf("invalid push job segment: %w", err)
+		}
+	}
+
+	ttl := 0
+	if ttlSeconds != nil {
+		ttl = *ttlSeconds
+	}
+
+	return model.ReconstructPushJob(
+		jobID,
+		stringValue(idempotencyKey),
+		uid,
+		stringValue(topic),
+		model.Urgency(stringValue(urgency)),
+		ttl,
+		payload,
+		stringValue(templateKey),
+		vars,
+		segment,
+		recipientCount,
+		scheduleAt,
+		model.JobStatus(status),
+		retryCount,
+		stringValue(lastError),
+		nextAttemptAt,
+		stringValue(leaseOwner),
+		leaseExpiresAt,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+// segmentRecord is the JSON stored in push_jobs.segment.
+type segmentRecord struct {
+	Topics           []string          `json:"topics,omitempty"`
+	Attributes       map[string]string `json:"attributes,omitempty"`
+	Browsers         []string          `json:"browsers,omitempty"`
+	Locales          []string          `json:"locales,omitempty"`
+	ActiveWithinDays int               `json:"activeWithinDays,omitempty"`
+	InactiveForDays  int               `json:"inactiveForDays,omitempty"`
+}
+
+func toSegmentRecord(segment *model.Segment) segmentRecord {
+	browsers := make([]string, len(segment.Browsers()))
+	for i, browser := range segment.Browsers() {
+		browsers[i] = browser.String()
+	}
+	return segmentRecord{
+		Topics:           segment.Topics(),
+		Attributes:       segment.Attributes(),
+		Browsers:         browsers,
+		Locales:          segment.Locales(),
+		ActiveWithinDays: segment.ActiveWithinDays(),
+		InactiveForDays:  segment.InactiveForDays(),
+	}
+}
+
+func (r segmentRecord) toModel() (*model.Segment, error) {
+	browsers := make([]valueobject.Browser, len(r.Browsers))
+	for i, value := range r.Browsers {
+		browser, err := valueobject.NewBrowser(value)
+		if err != nil {
+			return nil, err
+		}
+		browsers[i] = browser
+	}
+	return model.NewSegment(r.Topics, r.Attributes, browsers, r.Locales, r.ActiveWithinDays, r.InactiveForDays)
+}
@@ -0,0 +1,232 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/repository"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+const pushLogColumns = `id, job_id, subscription_id, response_status, response_headers, error, created_at`
+
+type PostgresPushLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresPushLogRepository(pool *pgxpool.Pool) *PostgresPushLogRepository {
+	return &PostgresPushLogRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresPushLogRepository) Save(ctx context.Context, log *model.PushLog) error {
+	headers, err := log.ResponseHeadersJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal response headers: %w", err)
+	}
+
+	var jobID, subscriptionID *int64
+	if log.JobID() != nil {
+		v := log.JobID().Value()
+		jobID = &v
+	}
+	if log.SubscriptionID() != nil {
+		v := log.SubscriptionID().Value()
+		subscriptionID = &v
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO push_logs (`+pushLogColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			job_id = EXCLUDED.job_id,
+			subscription_id = EXCLUDED.subscription_id,
+			response_status = EXCLUDED.response_status,
+			response_headers = EXCLUDED.response_headers,
+			error = EXCLUDED.error`,
+		log.ID(),
+		jobID,
+		subscriptionID,
+		log.ResponseStatus(),
+		headers,
+		nullableString(log.ErrorMessage()),
+		log.CreatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push log: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushLogRepository) FindByJobID(ctx context.Context, jobID valueobject.JobID) ([]*model.PushLog, error) {
+	return r.query(ctx, `
+		SELECT `+pushLogColumns+` FROM push_logs
+		WHERE job_id = $1
+		ORDER BY id`, jobID.Value())
+}
+
+func (r *PostgresPushLogRepository) FindBySubscriptionID(ctx context.Context, subscriptionID valueobject.SubscriptionID) ([]*model.PushLog, error) {
+	return r.query(ctx, `
+		SELECT `+pushLogColumns+` FROM push_logs
+		WHERE subscription_id = $1
+		ORDER BY id`, subscriptionID.Value())
+}
+
+func (r *PostgresPushLogRepository) FindLogs(ctx context.Context, filter repository.PushLogFilter) ([]*model.PushLog, error) {
+	args := []any{filter.AfterID}
+	conditions := []string{"id > $1"}
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.JobID != nil {
+		addCondition("job_id = $%d", filter.JobID.Value())
+	}
+	if filter.SubscriptionID != nil {
+		addCondition("subscription_id = $%d", filter.SubscriptionID.Value())
+	}
+	if filter.Since != nil {
+		addCondition("created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCondition("created_at < $%d", *filter.Until)
+	}
+
+	sql := `SELECT ` + pushLogColumns + ` FROM push_logs WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		sql += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	return r.query(ctx, sql, args...)
+}
+
+func (r *PostgresPushLogRepository) DeleteOldLogs(ctx context.Context, olderThanDays int) (int, error) {
+	deleted, err := deleteInBatches(ctx, r.pool, `
+		DELETE FROM push_logs WHERE id IN (
+			SELECT id FROM push_logs
+			WHERE created_at < now() - make_interval(days => $1)
+			LIMIT $2)`, olderThanDays)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete old push logs: %w", err)
+	}
+	return deleted, nil
+}
+
+func (r *PostgresPushLogRepository) CountSuccessByJobID(ctx context.Context, jobID valueobject.JobID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT count(*) FROM push_logs
+		WHERE job_id = $1 AND response_status BETWEEN 200 AND 299`, jobID.Value()).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count successful push logs: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PostgresPushLogRepository) CountFailuresByJobID(ctx context.Context, jobID valueobject.JobID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT count(*) FROM push_logs
+		WHERE job_id = $1
+			AND (response_status IS NULL OR response_status NOT BETWEEN 200 AND 299)
+			AND COALESCE(error, '') <> ''`, jobID.Value()).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count failed push logs: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PostgresPushLogRepository) NextIdentity(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('push_logs', 'id'))`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate push log ID: %w", err)
+	}
+	return id, nil
+}
+
+func (r *PostgresPushLogRepository) query(ctx context.Context, sql string, args ...any) ([]*model.PushLog, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push logs: %w", err)
+	}
+	defer rows.Close()
+
+	return collectPushLogs(rows)
+}
+
+func collectPushLogs(rows pgx.Rows) ([]*model.PushLog, error) {
+	var result []*model.PushLog
+	for rows.Next() {
+		log, err := scanPushLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan push log: %w", err)
+		}
+		result = append(result, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate push logs: %w", err)
+	}
+	return result, nil
+}
+
+func scanPushLog(row rowScanner) (*model.PushLog, error) {
+	var (
+		id             int64
+		jobID          *int64
+		subscriptionID *int64
+		responseStatus *int
+		headersJSON    []byte
+		errorMessage   *string
+		createdAt      time.Time
+	)
+	err := row.Scan(&id, &jobID, &subscriptionID, &responseStatus, &headersJSON, &errorMessage, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var jid *valueobject.JobID
+	if jobID != nil {
+		v, err := valueobject.NewJobID(*jobID)
+		if err != nil {
+			return nil, err
+		}
+		jid = &v
+	}
+
+	var sid *valueobject.SubscriptionID
+	if subscriptionID != nil {
+		v, err := valueobject.NewSubscriptionID(*subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		sid = &v
+	}
+
+	var headers map[string]string
+	if len(headersJSON) > 0 {
+		if err := json.Unmarshal(headersJSON, &headers); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response headers: %w", err)
+		}
+	}
+
+	return model.ReconstructPushLog(
+		id,
+		jid,
+		sid,
+		responseStatus,
+		headers,
+		stringValue(errorMessage),
+		createdAt,
+	), nil
+}
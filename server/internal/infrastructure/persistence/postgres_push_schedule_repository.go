@@ -0,0 +1,303 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+const pushScheduleColumns = `id, name, cron_expression, timezone, user_id, topic, urgency, ttl_seconds, payload, template_key, template_vars, segment, status::text, next_run_at, last_run_at, last_job_id, created_at, updated_at`
+
+type PostgresPushScheduleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresPushScheduleRepository(pool *pgxpool.Pool) *PostgresPushScheduleRepository {
+	return &PostgresPushScheduleRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresPushScheduleRepository) Save(ctx context.Context, schedule *model.PushSchedule) error {
+	return savePushSchedule(ctx, r.pool, schedule)
+}
+
+func savePushSchedule(ctx context.Context, db execer, schedule *model.PushSchedule) error {
+	payload, err := schedule.Payload().ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal push schedule payload: %w", err)
+	}
+
+	var templateVars []byte
+	if schedule.TemplateVars() != nil {
+		templateVars, err = json.Marshal(schedule.TemplateVars())
+		if err != nil {
+			return fmt.Errorf("failed to marshal push schedule template variables: %w", err)
+		}
+	}
+
+	var segment []byte
+	if schedule.Segment() != nil {
+		segment, err = json.Marshal(toSegmentRecord(schedule.Segment()))
+		if err != nil {
+			return fmt.Errorf("failed to marshal push schedule segment: %w", err)
+		}
+	}
+
+	var lastJobID *int64
+	if schedule.LastJobID() != nil {
+		id := schedule.LastJobID().Value()
+		lastJobID = &id
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO push_schedules (id, name, cron_expression, timezone, user_id, topic, urgency, ttl_seconds,
+			payload, template_key, template_vars, segment, status, next_run_at, last_run_at, last_job_id,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			cron_expression = EXCLUDED.cron_expression,
+			timezone = EXCLUDED.timezone,
+			user_id = EXCLUDED.user_id,
+			topic = EXCLUDED.topic,
+			urgency = EXCLUDED.urgency,
+			ttl_seconds = EXCLUDED.ttl_seconds,
+			payload = EXCLUDED.payload,
+			template_key = EXCLUDED.template_key,
+			template_vars = EXCLUDED.template_vars,
+			segment = EXCLUDED.segment,
+			status = EXCLUDED.status,
+			next_run_at = EXCLUDED.next_run_at,
+			last_run_at = EXCLUDED.last_run_at,
+			last_job_id = EXCLUDED.last_job_id,
+			updated_at = EXCLUDED.updated_at`,
+		schedule.ID().Value(),
+		schedule.Name(),
+		schedule.Cron().Expression(),
+		schedule.Cron().Timezone(),
+		nullableUserID(schedule.UserID()),
+		nullableString(schedule.Topic()),
+		string(schedule.Urgency()),
+		schedule.TTLSeconds(),
+		payload,
+		nullableString(schedule.TemplateKey()),
+		templateVars,
+		segment,
+		string(schedule.Status()),
+		schedule.NextRunAt(),
+		schedule.LastRunAt(),
+		lastJobID,
+		schedule.CreatedAt(),
+		schedule.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushScheduleRepository) FindByID(ctx context.Context, id valueobject.ScheduleID) (*model.PushSchedule, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+pushScheduleColumns+` FROM push_schedules WHERE id = $1`, id.Value())
+	schedule, err := scanPushSchedule(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+func (r *PostgresPushScheduleRepository) FindAll(ctx context.Context) ([]*model.PushSchedule, error) {
+	return r.query(ctx, `SELECT `+pushScheduleColumns+` FROM push_schedules ORDER BY id`)
+}
+
+func (r *PostgresPushScheduleRepository) FindDueSchedules(ctx context.Context, now time.Time, limit int) ([]*model.PushSchedule, error) {
+	return r.query(ctx, `
+		SELECT `+pushScheduleColumns+` FROM push_schedules
+		WHERE status = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2`, now, limit)
+}
+
+// RecordRun locks the schedule row and only proceeds if it still expects
+// occurrence, so replicas racing for the same occurrence are serialised and
+// all but the first find the row already advanced. The spawned job's
+// idempotency key is unique as a second line of defence.
+func (r *PostgresPushScheduleRepository) RecordRun(ctx context.Context, schedule *model.PushSchedule, occurrence time.Time, job *model.PushJob) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked int64
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM push_schedules
+		WHERE id = $1 AND status = 'active' AND next_run_at = $2
+		FOR UPDATE`,
+		schedule.ID().Value(), occurrence).Scan(&locked)
+	if isNoRows(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock push schedule: %w", err)
+	}
+
+	if job != nil {
+		if err := savePushJob(ctx, tx, job); err != nil {
+			return false, err
+		}
+	}
+	if err := savePushSchedule(ctx, tx, schedule); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit push schedule run: %w", err)
+	}
+	return true, nil
+}
+
+func (r *PostgresPushScheduleRepository) Delete(ctx context.Context, id valueobject.ScheduleID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM push_schedules WHERE id = $1`, id.Value())
+	if err != nil {
+		return fmt.Errorf("failed to delete push schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushScheduleRepository) NextIdentity(ctx context.Context) (valueobject.ScheduleID, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('push_schedules', 'id'))`).Scan(&id)
+	if err != nil {
+		return valueobject.ScheduleID{}, fmt.Errorf("failed to generate schedule ID: %w", err)
+	}
+	return valueobject.NewScheduleID(id)
+}
+
+func (r *PostgresPushScheduleRepository) query(ctx context.Context, sql string, args ...any) ([]*model.PushSchedule, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*model.PushSchedule
+	for rows.Next() {
+		schedule, err := scanPushSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan push schedule: %w", err)
+		}
+		result = append(result, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate push schedules: %w", err)
+	}
+	return result, nil
+}
+
+func scanPushSchedule(row rowScanner) (*model.PushSchedule, error) {
+	var (
+		id           int64
+		name         string
+		expression   string
+		timezone     string
+		userID       *int64
+		topic        *string
+		urgency      string
+		ttlSeconds   int
+		payloadJSON  []byte
+		templateKey  *string
+		templateVars []byte
+		segmentJSON  []byte
+		status       string
+		nextRunAt    *time.Time
+		lastRunAt    *time.Time
+		lastJobID    *int64
+		createdAt    time.Time
+		updatedAt    time.Time
+	)
+	err := row.Scan(&id, &name, &expression, &timezone, &userID, &topic, &urgency, &ttlSeconds, &payloadJSON,
+		&templateKey, &templateVars, &segmentJSON, &status, &nextRunAt, &lastRunAt, &lastJobID, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleID, err := valueobject.NewScheduleID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cron, err := valueobject.NewCronSchedule(expression, timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid push schedule cron: %w", err)
+	}
+
+	uid, err := userIDFromNullable(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload model.PushPayload
+	if len(payloadJSON) > 0 {
+		if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal push schedule payload: %w", err)
+		}
+	}
+
+	var vars map[string]string
+	if len(templateVars) > 0 {
+		if err := json.Unmarshal(templateVars, &vars); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal push schedule template variables: %w", err)
+		}
+	}
+
+	var segment *model.Segment
+	if len(segmentJSON) > 0 {
+		var record segmentRecord
+		if err := json.Unmarshal(segmentJSON, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal push schedule segment: %w", err)
+		}
+		segment, err = record.toModel()
+		if err != nil {
+			return nil, fmt.Errorf("invalid push schedule segment: %w", err)
+		}
+	}
+
+	var jobID *valueobject.JobID
+	if lastJobID != nil {
+		id, err := valueobject.NewJobID(*lastJobID)
+		if err != nil {
+			return nil, err
+		}
+		jobID = &id
+	}
+
+	return model.ReconstructPushSchedule(
+		scheduleID,
+		name,
+		cron,
+		uid,
+		stringValue(topic),
+		model.Urgency(urgency),
+		ttlSeconds,
+		payload,
+		stringValue(templateKey),
+		vars,
+		segment,
+		model.ScheduleStatus(status),
+		nextRunAt,
+		lastRunAt,
+		jobID,
+		createdAt,
+		updatedAt,
+	), nil
+}
@@ -0,0 +1,317 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+const pushSubscriptionColumns = `id, public_id, user_id, endpoint, p256dh, auth, ua, expiration_time, vapid_key_id, is_valid, last_active_at, created_at, updated_at`
+
+type PostgresPushSubscriptionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresPushSubscriptionRepository(pool *pgxpool.Pool) *PostgresPushSubscriptionRepository {
+	return &PostgresPushSubscriptionRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresPushSubscriptionRepository) Save(ctx context.Context, subscription *model.PushSubscription) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO push_subscriptions (`+pushSubscriptionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			endpoint = EXCLUDED.endpoint,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth,
+			ua = EXCLUDED.ua,
+			expiration_time = EXCLUDED.expiration_time,
+			vapid_key_id = EXCLUDED.vapid_key_id,
+			is_valid = EXCLUDED.is_valid,
+			last_active_at = EXCLUDED.last_active_at,
+			updated_at = EXCLUDED.updated_at`,
+		subscription.ID().Value(),
+		subscription.PublicID().Value(),
+		nullableUserID(subscription.UserID()),
+		subscription.Endpoint().Value(),
+		subscription.Keys().P256dh().Value(),
+		subscription.Keys().Auth().Value(),
+		nullableString(subscription.UserAgent()),
+		subscription.ExpirationTime(),
+		subscription.VAPIDKeyID(),
+		subscription.IsValid(),
+		subscription.LastActiveAt(),
+		subscription.CreatedAt(),
+		subscription.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushSubscriptionRepository) FindByID(ctx context.Context, id valueobject.SubscriptionID) (*model.PushSubscription, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+pushSubscriptionColumns+` FROM push_subscriptions WHERE id = $1`, id.Value())
+	subscription, err := scanPushSubscription(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (r *PostgresPushSubscriptionRepository) FindByPublicID(ctx context.Context, publicID valueobject.PublicSubscriptionID) (*model.PushSubscription, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+pushSubscriptionColumns+` FROM push_subscriptions WHERE public_id = $1`, publicID.Value())
+	subscription, err := scanPushSubscription(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push subscription by public ID: %w", err)
+	}
+	return subscription, nil
+}
+
+func (r *PostgresPushSubscriptionRepository) FindByEndpoint(ctx context.Context, endpoint valueobject.PushEndpoint) (*model.PushSubscription, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+pushSubscriptionColumns+` FROM push_subscriptions WHERE endpoint = $1`, endpoint.Value())
+	subscription, err := scanPushSubscription(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push subscription by endpoint: %w", err)
+	}
+	return subscription, nil
+}
+
+func (r *PostgresPushSubscriptionRepository) FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*model.PushSubscription, error) {
+	return r.query(ctx, `
+		SELECT `+pushSubscriptionColumns+` FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY id`, userID.Value())
+}
+
+func (r *PostgresPushSubscriptionRepository) FindValidSubscriptions(ctx context.Context) ([]*model.PushSubscription, error) {
+	return r.query(ctx, `
+		SELECT `+pushSubscriptionColumns+` FROM push_subscriptions
+		WHERE is_valid AND (expiration_time IS NULL OR expiration_time > now())
+		ORDER BY id`)
+}
+
+func (r *PostgresPushSubscriptionRepository) FindValidSubscriptionsByUserID(ctx context.Context, userID valueobject.UserID) ([]*model.PushSubscription, error) {
+	return r.query(ctx, `
+		SELECT `+pushSubscriptionColumns+` FROM push_subscriptions
+		WHERE user_id = $1 AND is_valid AND (expiration_time IS NULL OR expiration_time > now())
+		ORDER BY id`, userID.Value())
+}
+
+func (r *PostgresPushSubscriptionRepository) FindValidSubscriptionsByUserIDs(ctx context.Context, userIDs []valueobject.UserID) ([]*model.PushSubscription, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return r.query(ctx, `
+		SELECT `+pushSubscriptionColumns+` FROM push_subscriptions
+		WHERE user_id = ANY($1) AND is_valid AND (expiration_time IS NULL OR expiration_time > now())
+		ORDER BY id`, userIDValues(userIDs))
+}
+
+func (r *PostgresPushSubscriptionRepository) CountValidSubscriptionsByUserIDs(ctx context.Context, userIDs []valueobject.UserID) (map[valueobject.UserID]int, error) {
+	counts := make(map[valueobject.UserID]int)
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, count(*) FROM push_subscriptions
+		WHERE user_id = ANY($1) AND is_valid AND (expiration_time IS NULL OR expiration_time > now())
+		GROUP BY user_id`, userIDValues(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID int64
+			count  int
+		)
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription count: %w", err)
+		}
+		id, err := valueobject.NewUserID(int(userID))
+		if err != nil {
+			return nil, err
+		}
+		counts[id] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate push subscription counts: %w", err)
+	}
+	return counts, nil
+}
+
+func (r *PostgresPushSubscriptionRepository) MarkAsInvalid(ctx context.Context, id valueobject.SubscriptionID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE push_subscriptions SET is_valid = FALSE, updated_at = now()
+		WHERE id = $1`, id.Value())
+	if err != nil {
+		return fmt.Errorf("failed to mark push subscription as invalid: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+	return nil
+}
+
+func (r *PostgresPushSubscriptionRepository) MarkActive(ctx context.Context, id valueobject.SubscriptionID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE push_subscriptions SET last_active_at = greatest(last_active_at, $2)
+		WHERE id = $1`, id.Value(), at)
+	if err != nil {
+		return fmt.Errorf("failed to mark push subscription as active: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushSubscriptionRepository) DeleteExpiredSubscriptions(ctx context.Context) (int, error) {
+	deleted, err := deleteInBatches(ctx, r.pool, `
+		DELETE FROM push_subscriptions WHERE id IN (
+			SELECT id FROM push_subscriptions
+			WHERE expiration_time IS NOT NULL AND expiration_time < now()
+			LIMIT $1)`)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete expired push subscriptions: %w", err)
+	}
+	return deleted, nil
+}
+
+func (r *PostgresPushSubscriptionRepository) DeleteInvalidSubscriptions(ctx context.Context, olderThanDays int) (int, error) {
+	deleted, err := deleteInBatches(ctx, r.pool, `
+		DELETE FROM push_subscriptions WHERE id IN (
+			SELECT id FROM push_subscriptions
+			WHERE is_valid = FALSE AND updated_at < now() - make_interval(days => $1)
+			LIMIT $2)`, olderThanDays)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete invalid push subscriptions: %w", err)
+	}
+	return deleted, nil
+}
+
+func (r *PostgresPushSubscriptionRepository) Delete(ctx context.Context, id valueobject.SubscriptionID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM push_subscriptions WHERE id = $1`, id.Value())
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPushSubscriptionRepository) NextIdentity(ctx context.Context) (valueobject.SubscriptionID, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('push_subscriptions', 'id'))`).Scan(&id)
+	if err != nil {
+		return valueobject.SubscriptionID{}, fmt.Errorf("failed to generate subscription ID: %w", err)
+	}
+	return valueobject.NewSubscriptionID(id)
+}
+
+func (r *PostgresPushSubscriptionRepository) query(ctx context.Context, sql string, args ...any) ([]*model.PushSubscription, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	return collectPushSubscriptions(rows)
+}
+
+func collectPushSubscriptions(rows pgx.Rows) ([]*model.PushSubscription, error) {
+	var result []*model.PushSubscription
+	for rows.Next() {
+		subscription, err := scanPushSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		result = append(result, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate push subscriptions: %w", err)
+	}
+	return result, nil
+}
+
+func scanPushSubscription(row rowScanner) (*model.PushSubscription, error) {
+	var (
+		id             int64
+		publicIDStr    string
+		userID         *int64
+		endpointStr    string
+		p256dhStr      string
+		authStr        string
+		userAgent      *string
+		expirationTime *time.Time
+		vapidKeyID     *int64
+		isValid        bool
+		lastActiveAt   time.Time
+		createdAt      time.Time
+		updatedAt      time.Time
+	)
+	err := row.Scan(&id, &publicIDStr, &userID, &endpointStr, &p256dhStr, &authStr, &userAgent,
+		&expirationTime, &vapidKeyID, &isValid, &lastActiveAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionID, err := valueobject.NewSubscriptionID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	publicID, err := valueobject.PublicSubscriptionIDFromString(publicIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := userIDFromNullable(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := valueobject.NewPushEndpoint(endpointStr)
+	if err != nil {
+		return nil, err
+	}
+	p256dh, err := valueobject.NewP256dhKey(p256dhStr)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := valueobject.NewAuthKey(authStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ReconstructPushSubscription(
+		subscriptionID,
+		publicID,
+		uid,
+		endpoint,
+		valueobject.NewPushKeys(p256dh, auth),
+		stringValue(userAgent),
+		expirationTime,
+		vapidKeyID,
+		isValid,
+		lastActiveAt,
+		createdAt,
+		updatedAt,
+	), nil
+}
@@ -0,0 +1,183 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+const userColumns = `id, name, email, locale, attributes, created_at, updated_at`
+
+type PostgresUserRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresUserRepository(pool *pgxpool.Pool) *PostgresUserRepository {
+	return &PostgresUserRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresUserRepository) Save(ctx context.Context, user *model.User) error {
+	attributes, err := json.Marshal(user.Attributes())
+	if err != nil {
+		return fmt.Errorf("failed to marshal user attributes: %w", err)
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO users (id, name, email, locale, attributes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			email = EXCLUDED.email,
+			locale = EXCLUDED.locale,
+			attributes = EXCLUDED.attributes,
+			updated_at = EXCLUDED.updated_at`,
+		user.ID().Value(),
+		user.Name(),
+		user.Email().Value(),
+		nullableString(user.Locale()),
+		attributes,
+		user.CreatedAt(),
+		user.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id valueobject.UserID) (*model.User, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id.Value())
+	user, err := scanUser(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return user, nil
+}
+
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email valueobject.Email) (*model.User, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email.Value())
+	user, err := scanUser(row)
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user by email: %w", err)
+	}
+	return user, nil
+}
+
+func (r *PostgresUserRepository) FindAll(ctx context.Context) ([]*model.User, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*model.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+	return users, nil
+}
+
+func (r *PostgresUserRepository) FindByIDs(ctx context.Context, ids []valueobject.UserID) (map[valueobject.UserID]*model.User, error) {
+	users := make(map[valueobject.UserID]*model.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		values[i] = int64(id.Value())
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+userColumns+` FROM users WHERE id = ANY($1)`, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users[user.ID()] = user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+	return users, nil
+}
+
+func (r *PostgresUserRepository) Delete(ctx context.Context, id valueobject.UserID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, id.Value())
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) NextIdentity(ctx context.Context) (valueobject.UserID, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('users', 'id'))`).Scan(&id)
+	if err != nil {
+		return valueobject.UserID{}, fmt.Errorf("failed to generate user ID: %w", err)
+	}
+	return valueobject.NewUserID(int(id))
+}
+
+func scanUser(row rowScanner) (*model.User, error) {
+	var (
+		id             int64
+		name           string
+		emailStr       string
+		locale         *string
+		attributesJSON []byte
+		createdAt      time.Time
+		updatedAt      time.Time
+	)
+	if err := row.Scan(&id, &name, &emailStr, &locale, &attributesJSON, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	userID, err := valueobject.NewUserID(int(id))
+	if err != nil {
+		return nil, err
+	}
+	email, err := valueobject.NewEmail(emailStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var attributes map[string]string
+	if len(attributesJSON) > 0 {
+		if err := json.Unmarshal(attributesJSON, &attributes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal user attributes: %w", err)
+		}
+	}
+
+	return model.ReconstructUser(userID, name, email, stringValue(locale), attributes, createdAt, updatedAt), nil
+}
@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+const vapidKeyColumns = `id, public_key, private_key, subject, is_active, created_at, activated_at`
+
+type PostgresVAPIDKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresVAPIDKeyRepository(pool *pgxpool.Pool) *PostgresVAPIDKeyRepository {
+	return &PostgresVAPIDKeyRepository{
+		pool: pool,
+	}
+}
+
+func (r *PostgresVAPIDKeyRepository) Save(ctx context.Context, key *model.VAPIDKey) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO vapid_keys (`+vapidKeyColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			subject = EXCLUDED.subject,
+			is_active = EXCLUDED.is_active,
+			activated_at = EXCLUDED.activated_at`,
+		key.ID(),
+		key.PublicKey(),
+		key.PrivateKey(),
+		nullableString(key.Subject().Value()),
+		key.IsActive(),
+		key.CreatedAt(),
+		key.ActivatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save VAPID key: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresVAPIDKeyRepository) FindByID(ctx context.Context, id int64) (*model.VAPIDKey, error) {
+	return r.findOne(ctx, `SELECT `+vapidKeyColumns+` FROM vapid_keys WHERE id = $1`, id)
+}
+
+func (r *PostgresVAPIDKeyRepository) FindByPublicKey(ctx context.Context, publicKey string) (*model.VAPIDKey, error) {
+	return r.findOne(ctx, `SELECT `+vapidKeyColumns+` FROM vapid_keys WHERE public_key = $1`, publicKey)
+}
+
+func (r *PostgresVAPIDKeyRepository) FindActive(ctx context.Context) (*model.VAPIDKey, error) {
+	return r.findOne(ctx, `SELECT `+vapidKeyColumns+` FROM vapid_keys WHERE is_active`)
+}
+
+func (r *PostgresVAPIDKeyRepository) FindAll(ctx context.Context) ([]*model.VAPIDKey, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+vapidKeyColumns+` FROM vapid_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query VAPID keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*model.VAPIDKey, 0)
+	for rows.Next() {
+		key, err := scanVAPIDKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan VAPID key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate VAPID keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *PostgresVAPIDKeyRepository) Activate(ctx context.Context, id int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE vapid_keys SET is_active = FALSE WHERE is_active AND id <> $1`, id); err != nil {
+		return fmt.Errorf("failed to deactivate VAPID keys: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE vapid_keys SET
+			is_active = TRUE,
+			activated_at = CASE WHEN is_active THEN activated_at ELSE now() END
+		WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to activate VAPID key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("VAPID key not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresVAPIDKeyRepository) NextIdentity(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('vapid_keys', 'id'))`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate VAPID key ID: %w", err)
+	}
+	return id, nil
+}
+
+func (r *PostgresVAPIDKeyRepository) findOne(ctx context.Context, sql string, args ...any) (*model.VAPIDKey, error) {
+	key, err := scanVAPIDKey(r.pool.QueryRow(ctx, sql, args...))
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find VAPID key: %w", err)
+	}
+	return key, nil
+}
+
+func scanVAPIDKey(row rowScanner) (*model.VAPIDKey, error) {
+	var (
+		id          int64
+		publicKey   string
+		privateKey  string
+		subjectStr  *string
+		isActive    bool
+		createdAt   time.Time
+		activatedAt *time.Time
+	)
+	if err := row.Scan(&id, &publicKey, &privateKey, &subjectStr, &isActive, &createdAt, &activatedAt); err != nil {
+		return nil, err
+	}
+
+	var subject valueobject.VAPIDSubject
+	if subjectStr != nil {
+		var err error
+		subject, err = valueobject.NewVAPIDSubject(*subjectStr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return model.ReconstructVAPIDKey(id, publicKey, privateKey, subject, isActive, createdAt, activatedAt), nil
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+const apiKeyUsage = `usage: apikey [list | create <name> --scopes=<send,read-logs,admin> | revoke <id>]`
+
+// RunAPIKeyCommand implements the `apikey` subcommand of the server binary.
+func RunAPIKeyCommand(ctx context.Context, apiKeyUseCase *usecase.APIKeyUseCase, args []string, out io.Writer) error {
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "list":
+		keys, err := apiKeyUseCase.ListKeys(ctx)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			printAPIKey(out, key)
+		}
+		return nil
+
+	case "create":
+		var name string
+		var scopes []model.APIKeyScope
+		for _, arg := range args[1:] {
+			switch {
+			case strings.HasPrefix(arg, "--scopes="):
+				for _, scope := range strings.Split(strings.TrimPrefix(arg, "--scopes="), ",") {
+					scopes = append(scopes, model.APIKeyScope(strings.TrimSpace(scope)))
+				}
+			case strings.HasPrefix(arg, "--"):
+				return fmt.Errorf("unknown flag %q\n%s", arg, apiKeyUsage)
+			case name == "":
+				name = arg
+			default:
+				return fmt.Errorf("unexpected argument %q\n%s", arg, apiKeyUsage)
+			}
+		}
+		key, secret, err := apiKeyUseCase.CreateKey(ctx, name, scopes)
+		if err != nil {
+			return err
+		}
+		printAPIKey(out, key)
+		fmt.Fprintf(out, "key: %s\n", secret)
+		return nil
+
+	case "revoke":
+		if len(args) != 2 {
+			return fmt.Errorf("missing key ID\n%s", apiKeyUsage)
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key ID %q\n%s", args[1], apiKeyUsage)
+		}
+		key, err := apiKeyUseCase.RevokeKey(ctx, id)
+		if err != nil {
+			return err
+		}
+		printAPIKey(out, key)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown apikey action %q\n%s", action, apiKeyUsage)
+	}
+}
+
+func printAPIKey(out io.Writer, key *model.APIKey) {
+	state := "active"
+	if key.IsRevoked() {
+		state = "revoked"
+	}
+	scopes := make([]string, len(key.Scopes()))
+	for i, scope := range key.Scopes() {
+		scopes[i] = string(scope)
+	}
+	fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\t%s\n", key.ID(), state, key.CreatedAt().Format("2006-01-02 15:04:05 MST"),
+		key.Prefix(), strings.Join(scopes, ","), key.Name())
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+const vapidUsage = `usage: vapid [list | generate [--activate] [--subject=<mailto:|https: URI>] | activate <id>]`
+
+// RunVAPIDCommand implements the `vapid` subcommand of the server binary.
+func RunVAPIDCommand(ctx context.Context, vapidUseCase *usecase.VAPIDUseCase, args []string, out io.Writer) error {
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "list":
+		keys, err := vapidUseCase.ListKeys(ctx)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			printVAPIDKey(out, key)
+		}
+		return nil
+
+	case "generate":
+		activate := false
+		subject := ""
+		for _, arg := range args[1:] {
+			switch {
+			case arg == "--activate":
+				activate = true
+			case strings.HasPrefix(arg, "--subject="):
+				subject = strings.TrimPrefix(arg, "--subject=")
+			default:
+				return fmt.Errorf("unknown flag %q\n%s", arg, vapidUsage)
+			}
+		}
+		key, err := vapidUseCase.GenerateKey(ctx, activate, subject)
+		if err != nil {
+			return err
+		}
+		printVAPIDKey(out, key)
+		return nil
+
+	case "activate":
+		if len(args) != 2 {
+			return fmt.Errorf("missing key ID\n%s", vapidUsage)
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key ID %q\n%s", args[1], vapidUsage)
+		}
+		key, err := vapidUseCase.ActivateKey(ctx, id)
+		if err != nil {
+			return err
+		}
+		printVAPIDKey(out, key)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown vapid action %q\n%s", action, vapidUsage)
+	}
+}
+
+func printVAPIDKey(out io.Writer, key *model.VAPIDKey) {
+	state := "retired"
+	if key.IsActive() {
+		state = "active"
+	}
+	fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\n", key.ID(), state, key.CreatedAt().Format("2006-01-02 15:04:05 MST"),
+		key.PublicKey(), key.Subject().Value())
+}
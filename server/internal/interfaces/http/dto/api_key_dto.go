@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// APIKeyResponse never includes the secret or its hash.
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreateAPIKeyResponse carries the secret, which is only shown once.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type APIKeysResponse struct {
+	Keys  []APIKeyResponse `json:"keys"`
+	Count int              `json:"count"`
+}
+
+func ToAPIKeyResponse(key *model.APIKey) APIKeyResponse {
+	scopes := make([]string, len(key.Scopes()))
+	for i, scope := range key.Scopes() {
+		scopes[i] = string(scope)
+	}
+
+	return APIKeyResponse{
+		ID:         strconv.FormatInt(key.ID(), 10),
+		Name:       key.Name(),
+		Prefix:     key.Prefix(),
+		Scopes:     scopes,
+		CreatedAt:  key.CreatedAt(),
+		LastUsedAt: key.LastUsedAt(),
+		RevokedAt:  key.RevokedAt(),
+	}
+}
+
+func ToAPIKeysResponse(keys []*model.APIKey) APIKeysResponse {
+	responses := make([]APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = ToAPIKeyResponse(key)
+	}
+
+	return APIKeysResponse{
+		Keys:  responses,
+		Count: len(responses),
+	}
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type EngagementCountsResponse struct {
+	Delivered int `json:"delivered"`
+	Displayed int `json:"displayed"`
+	Clicked   int `json:"clicked"`
+	Closed    int `json:"closed"`
+}
+
+type JobEngagementResponse struct {
+	JobID string `json:"jobId"`
+	// Sent is the number of subscriptions the push service accepted.
+	Sent             int                      `json:"sent"`
+	Events           EngagementCountsResponse `json:"events"`
+	DisplayRate      float64                  `json:"displayRate"`
+	ClickThroughRate float64                  `json:"clickThroughRate"`
+}
+
+func ToJobEngagementResponse(jobID valueobject.JobID, sent int, counts model.EngagementCounts, displayRate, clickThroughRate float64) JobEngagementResponse {
+	return JobEngagementResponse{
+		JobID: jobID.String(),
+		Sent:  sent,
+		Events: EngagementCountsResponse{
+			Delivered: counts.Delivered,
+			Displayed: counts.Displayed,
+			Clicked:   counts.Clicked,
+			Closed:    counts.Closed,
+		},
+		DisplayRate:      displayRate,
+		ClickThroughRate: clickThroughRate,
+	}
+}
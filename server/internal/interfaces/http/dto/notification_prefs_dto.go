@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+type QuietHoursDTO struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+// UpdateNotificationPrefsRequest replaces the user's preferences. Omitting
+// enabled keeps notifications enabled; omitting quiet_hours clears them.
+type UpdateNotificationPrefsRequest struct {
+	Enabled    *bool           `json:"enabled,omitempty"`
+	Topics     map[string]bool `json:"topics,omitempty"`
+	QuietHours *QuietHoursDTO  `json:"quiet_hours,omitempty"`
+}
+
+type NotificationPrefsResponse struct {
+	UserID     int             `json:"user_id"`
+	Enabled    bool            `json:"enabled"`
+	Topics     map[string]bool `json:"topics"`
+	QuietHours *QuietHoursDTO  `json:"quiet_hours"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+func ToNotificationPrefsResponse(prefs *model.NotificationPrefs) NotificationPrefsResponse {
+	response := NotificationPrefsResponse{
+		UserID:    prefs.UserID().Value(),
+		Enabled:   prefs.Enabled(),
+		Topics:    prefs.Topics(),
+		UpdatedAt: prefs.UpdatedAt(),
+	}
+	if q := prefs.QuietHours(); q != nil {
+		response.QuietHours = &QuietHoursDTO{
+			Start:    q.Start(),
+			End:      q.End(),
+			Timezone: q.Timezone(),
+		}
+	}
+	return response
+}
@@ -7,6 +7,9 @@ type SubscribeRequest struct {
 	Keys           PushKeys `json:"keys" validate:"required"`
 	UserAgent      string   `json:"ua,omitempty"`
 	ExpirationTime *int64   `json:"expirationTime,omitempty"`
+	// ApplicationServerKey is the VAPID public key the browser subscribed
+	// with. The active key is assumed when omitted.
+	ApplicationServerKey string `json:"applicationServerKey,omitempty"`
 }
 
 type PushKeys struct {
@@ -20,8 +23,12 @@ type SubscribeResponse struct {
 	Message string `json:"message"`
 }
 
+// UnsubscribeRequest is the optional body of DELETE /api/push/subscriptions/{id}.
+// Callers without the owner's session prove ownership with either field of
+// the browser's PushSubscription.
 type UnsubscribeRequest struct {
-	ID string `json:"id" validate:"required"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Auth     string `json:"auth,omitempty"`
 }
 
 type UnsubscribeResponse struct {
@@ -35,7 +42,10 @@ type SendNotificationRequest struct {
 	Topic          string                 `json:"topic,omitempty"`
 	Urgency        string                 `json:"urgency,omitempty"`
 	TTL            int                    `json:"ttl,omitempty"`
-	Payload        map[string]interface{} `json:"payload" validate:"required"`
+	Payload        map[string]interface{} `json:"payload,omitempty"`
+	TemplateKey    string                 `json:"templateKey,omitempty"`
+	Variables      map[string]string      `json:"variables,omitempty"`
+	Segment        *SegmentDTO            `json:"segment,omitempty"`
 	ScheduleAt     *time.Time             `json:"scheduleAt,omitempty"`
 }
 
@@ -50,15 +60,27 @@ type SendBatchNotificationRequest struct {
 	Topic          string                 `json:"topic,omitempty"`
 	Urgency        string                 `json:"urgency,omitempty"`
 	TTL            int                    `json:"ttl,omitempty"`
-	Payload        map[string]interface{} `json:"payload" validate:"required"`
+	Payload        map[string]interface{} `json:"payload,omitempty"`
+	TemplateKey    string                 `json:"templateKey,omitempty"`
+	Variables      map[string]string      `json:"variables,omitempty"`
 	ScheduleAt     *time.Time             `json:"scheduleAt,omitempty"`
 	IdempotencyKey string                 `json:"idempotencyKey,omitempty"`
 }
 
+// SendBatchNotificationResponse describes the single job created for the
+// batch. Skipped lists the users left out and why (no_subscriptions,
+// opted_out).
 type SendBatchNotificationResponse struct {
-	JobIDs  []string `json:"jobIds"`
-	Success bool     `json:"success"`
-	Message string   `json:"message"`
+	JobID      string                     `json:"jobId,omitempty"`
+	Recipients int                        `json:"recipients"`
+	Skipped    []SkippedRecipientResponse `json:"skipped"`
+	Success    bool                       `json:"success"`
+	Message    string                     `json:"message"`
+}
+
+type SkippedRecipientResponse struct {
+	UserID string `json:"userId"`
+	Reason string `json:"reason"`
 }
 
 type VAPIDPublicKeyResponse struct {
@@ -67,9 +89,13 @@ type VAPIDPublicKeyResponse struct {
 	Message   string `json:"message"`
 }
 
+// ClickTrackingRequest is posted by the Service Worker to POST /api/push/events.
+// Event defaults to "clicked" for clients that predate the field.
 type ClickTrackingRequest struct {
+	Event          string `json:"event,omitempty"`
 	SubscriptionID string `json:"subscriptionId,omitempty"`
 	JobID          string `json:"jobId,omitempty"`
 	URL            string `json:"url,omitempty"`
-	Timestamp      int64  `json:"timestamp"`
+	// Timestamp is milliseconds since the Unix epoch.
+	Timestamp int64 `json:"timestamp"`
 }
@@ -0,0 +1,114 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+)
+
+type PushJobResponse struct {
+	ID             string      `json:"id"`
+	Status         string      `json:"status"`
+	IdempotencyKey string      `json:"idempotencyKey,omitempty"`
+	UserID         *string     `json:"userId,omitempty"`
+	Topic          string      `json:"topic,omitempty"`
+	Urgency        string      `json:"urgency"`
+	TTL            int         `json:"ttl"`
+	TemplateKey    string      `json:"templateKey,omitempty"`
+	Segment        *SegmentDTO `json:"segment,omitempty"`
+	Recipients     int         `json:"recipients,omitempty"`
+	ScheduleAt     *time.Time  `json:"scheduleAt,omitempty"`
+	RetryCount     int         `json:"retryCount"`
+	LastError      string      `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time  `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	UpdatedAt      time.Time   `json:"updatedAt"`
+}
+
+type DeliveryCountsResponse struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Gone      int `json:"gone"`
+	Skipped   int `json:"skipped"`
+}
+
+type AttemptCountsResponse struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+type PushJobDetailsResponse struct {
+	PushJobResponse
+	// Deliveries counts subscriptions by delivery state; Attempts counts
+	// individual requests to push services, including retries.
+	Deliveries DeliveryCountsResponse `json:"deliveries"`
+	Attempts   AttemptCountsResponse  `json:"attempts"`
+}
+
+type PushJobsResponse struct {
+	Jobs       []PushJobResponse `json:"jobs"`
+	Count      int               `json:"count"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+func ToPushJobResponse(job *model.PushJob) PushJobResponse {
+	response := PushJobResponse{
+		ID:             job.ID().String(),
+		Status:         string(job.Status()),
+		IdempotencyKey: job.IdempotencyKey(),
+		Topic:          job.Topic(),
+		Urgency:        string(job.Urgency()),
+		TTL:            job.TTLSeconds(),
+		TemplateKey:    job.TemplateKey(),
+		Segment:        ToSegmentDTO(job.Segment()),
+		Recipients:     job.RecipientCount(),
+		ScheduleAt:     job.ScheduleAt(),
+		RetryCount:     job.RetryCount(),
+		LastError:      job.LastError(),
+		NextAttemptAt:  job.NextAttemptAt(),
+		CreatedAt:      job.CreatedAt(),
+		UpdatedAt:      job.UpdatedAt(),
+	}
+	if job.UserID() != nil {
+		userID := job.UserID().String()
+		response.UserID = &userID
+	}
+	return response
+}
+
+func ToPushJobDetailsResponse(job *model.PushJob, counts model.DeliveryCounts, succeededAttempts, failedAttempts int) PushJobDetailsResponse {
+	return PushJobDetailsResponse{
+		PushJobResponse: ToPushJobResponse(job),
+		Deliveries: DeliveryCountsResponse{
+			Total:     counts.Total(),
+			Pending:   counts.Pending,
+			Succeeded: counts.Succeeded,
+			Failed:    counts.Failed,
+			Gone:      counts.Gone,
+			Skipped:   counts.Skipped,
+		},
+		Attempts: AttemptCountsResponse{
+			Succeeded: succeededAttempts,
+			Failed:    failedAttempts,
+		},
+	}
+}
+
+func ToPushJobsResponse(pushJobs []*model.PushJob, nextCursor *valueobject.JobID) PushJobsResponse {
+	jobs := make([]PushJobResponse, len(pushJobs))
+	for i, job := range pushJobs {
+		jobs[i] = ToPushJobResponse(job)
+	}
+
+	response := PushJobsResponse{
+		Jobs:  jobs,
+		Count: len(jobs),
+	}
+	if nextCursor != nil {
+		response.NextCursor = nextCursor.String()
+	}
+	return response
+}
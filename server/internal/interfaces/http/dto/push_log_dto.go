@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+type PushLogResponse struct {
+	ID              string            `json:"id"`
+	JobID           *string           `json:"jobId,omitempty"`
+	SubscriptionID  *string           `json:"subscriptionId,omitempty"`
+	StatusCode      *int              `json:"statusCode,omitempty"`
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	CreatedAt       time.Time         `json:"createdAt"`
+}
+
+type PushLogsResponse struct {
+	Logs       []PushLogResponse `json:"logs"`
+	Count      int               `json:"count"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+func ToPushLogResponse(log *model.PushLog) PushLogResponse {
+	response := PushLogResponse{
+		ID:              strconv.FormatInt(log.ID(), 10),
+		StatusCode:      log.ResponseStatus(),
+		ResponseHeaders: log.ResponseHeaders(),
+		Error:           log.ErrorMessage(),
+		CreatedAt:       log.CreatedAt(),
+	}
+	if log.JobID() != nil {
+		jobID := log.JobID().String()
+		response.JobID = &jobID
+	}
+	if log.SubscriptionID() != nil {
+		subscriptionID := log.SubscriptionID().String()
+		response.SubscriptionID = &subscriptionID
+	}
+	return response
+}
+
+func ToPushLogsResponse(pushLogs []*model.PushLog, nextCursor int64) PushLogsResponse {
+	logs := make([]PushLogResponse, len(pushLogs))
+	for i, log := range pushLogs {
+		logs[i] = ToPushLogResponse(log)
+	}
+
+	response := PushLogsResponse{
+		Logs:  logs,
+		Count: len(logs),
+	}
+	if nextCursor > 0 {
+		response.NextCursor = strconv.FormatInt(nextCursor, 10)
+	}
+	return response
+}
@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+// CreatePushScheduleRequest takes the body of POST /api/push/send, minus
+// idempotencyKey and scheduleAt, plus the recurrence. Cron is a five-field
+// expression or a descriptor such as @daily, evaluated in Timezone.
+type CreatePushScheduleRequest struct {
+	Name        string                 `json:"name"`
+	Cron        string                 `json:"cron"`
+	Timezone    string                 `json:"timezone,omitempty"`
+	UserID      *string                `json:"userId,omitempty"`
+	Topic       string                 `json:"topic,omitempty"`
+	Urgency     string                 `json:"urgency,omitempty"`
+	TTL         int                    `json:"ttl,omitempty"`
+	Payload     map[string]interface{} `json:"payload,omitempty"`
+	TemplateKey string                 `json:"templateKey,omitempty"`
+	Variables   map[string]string      `json:"variables,omitempty"`
+	Segment     *SegmentDTO            `json:"segment,omitempty"`
+}
+
+type PushScheduleResponse struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Cron        string      `json:"cron"`
+	Timezone    string      `json:"timezone"`
+	Status      string      `json:"status"`
+	UserID      *string     `json:"userId,omitempty"`
+	Topic       string      `json:"topic,omitempty"`
+	Urgency     string      `json:"urgency"`
+	TTL         int         `json:"ttl"`
+	TemplateKey string      `json:"templateKey,omitempty"`
+	Segment     *SegmentDTO `json:"segment,omitempty"`
+	NextRunAt   *time.Time  `json:"nextRunAt,omitempty"`
+	LastRunAt   *time.Time  `json:"lastRunAt,omitempty"`
+	LastJobID   string      `json:"lastJobId,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+type PushSchedulesResponse struct {
+	Schedules []PushScheduleResponse `json:"schedules"`
+	Count     int                    `json:"count"`
+}
+
+func ToPushScheduleResponse(schedule *model.PushSchedule) PushScheduleResponse {
+	response := PushScheduleResponse{
+		ID:          schedule.ID().String(),
+		Name:        schedule.Name(),
+		Cron:        schedule.Cron().Expression(),
+		Timezone:    schedule.Cron().Timezone(),
+		Status:      string(schedule.Status()),
+		Topic:       schedule.Topic(),
+		Urgency:     string(schedule.Urgency()),
+		TTL:         schedule.TTLSeconds(),
+		TemplateKey: schedule.TemplateKey(),
+		Segment:     ToSegmentDTO(schedule.Segment()),
+		NextRunAt:   schedule.NextRunAt(),
+		LastRunAt:   schedule.LastRunAt(),
+		CreatedAt:   schedule.CreatedAt(),
+		UpdatedAt:   schedule.UpdatedAt(),
+	}
+	if schedule.UserID() != nil {
+		userID := schedule.UserID().String()
+		response.UserID = &userID
+	}
+	if schedule.LastJobID() != nil {
+		response.LastJobID = schedule.LastJobID().String()
+	}
+	return response
+}
+
+func ToPushSchedulesResponse(schedules []*model.PushSchedule) PushSchedulesResponse {
+	responses := make([]PushScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		responses[i] = ToPushScheduleResponse(schedule)
+	}
+
+	return PushSchedulesResponse{
+		Schedules: responses,
+		Count:     len(responses),
+	}
+}
@@ -0,0 +1,48 @@
+package dto
+
+import "github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+
+// SegmentDTO narrows a job without userId down to the matching
+// subscriptions. Every field that is set must match.
+type SegmentDTO struct {
+	// Topics the user must have explicitly opted in to.
+	Topics     []string          `json:"topics,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Browsers are chrome, edge, firefox, safari, opera or other.
+	Browsers []string `json:"browsers,omitempty"`
+	// Locales match by language: "ja" matches "ja-JP".
+	Locales          []string `json:"locales,omitempty"`
+	ActiveWithinDays int      `json:"activeWithinDays,omitempty"`
+	InactiveForDays  int      `json:"inactiveForDays,omitempty"`
+}
+
+type AudienceResponse struct {
+	Subscriptions int `json:"subscriptions"`
+	Users         int `json:"users"`
+	Anonymous     int `json:"anonymous"`
+	OptedOut      int `json:"optedOut"`
+}
+
+type PreviewAudienceResponse struct {
+	Audience *AudienceResponse `json:"audience,omitempty"`
+	Success  bool              `json:"success"`
+	Message  string            `json:"message"`
+}
+
+func ToSegmentDTO(segment *model.Segment) *SegmentDTO {
+	if segment == nil {
+		return nil
+	}
+	browsers := make([]string, len(segment.Browsers()))
+	for i, browser := range segment.Browsers() {
+		browsers[i] = browser.String()
+	}
+	return &SegmentDTO{
+		Topics:           segment.Topics(),
+		Attributes:       segment.Attributes(),
+		Browsers:         browsers,
+		Locales:          segment.Locales(),
+		ActiveWithinDays: segment.ActiveWithinDays(),
+		InactiveForDays:  segment.InactiveForDays(),
+	}
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+// NotificationTemplateRequest is used to create (with Key) and update
+// (Key comes from the path) templates. Title, body, url, icon and string
+// values in data may contain {{variable}} placeholders.
+type NotificationTemplateRequest struct {
+	Key   string                 `json:"key,omitempty"`
+	Title string                 `json:"title"`
+	Body  string                 `json:"body"`
+	URL   string                 `json:"url,omitempty"`
+	Icon  string                 `json:"icon,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+type NotificationTemplateResponse struct {
+	Key       string                 `json:"key"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body"`
+	URL       string                 `json:"url,omitempty"`
+	Icon      string                 `json:"icon,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+type NotificationTemplatesResponse struct {
+	Templates []NotificationTemplateResponse `json:"templates"`
+	Count     int                            `json:"count"`
+}
+
+func ToNotificationTemplateResponse(template *model.NotificationTemplate) NotificationTemplateResponse {
+	return NotificationTemplateResponse{
+		Key:       template.Key(),
+		Title:     template.Title(),
+		Body:      template.Body(),
+		URL:       template.URL(),
+		Icon:      template.Icon(),
+		Data:      template.Data(),
+		CreatedAt: template.CreatedAt(),
+		UpdatedAt: template.UpdatedAt(),
+	}
+}
+
+func ToNotificationTemplatesResponse(templates []*model.NotificationTemplate) NotificationTemplatesResponse {
+	responses := make([]NotificationTemplateResponse, len(templates))
+	for i, template := range templates {
+		responses[i] = ToNotificationTemplateResponse(template)
+	}
+
+	return NotificationTemplatesResponse{
+		Templates: responses,
+		Count:     len(responses),
+	}
+}
@@ -7,11 +7,13 @@ import (
 )
 
 type UserResponse struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         int               `json:"id"`
+	Name       string            `json:"name"`
+	Email      string            `json:"email"`
+	Locale     string            `json:"locale,omitempty"`
+	Attributes map[string]string `json:"attributes"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
 }
 
 type CreateUserRequest struct {
@@ -19,6 +21,14 @@ type CreateUserRequest struct {
 	Email string `json:"email"`
 }
 
+// UpdateUserRequest changes the fields that are present; attributes
+// replace the existing ones as a whole.
+type UpdateUserRequest struct {
+	Name       *string           `json:"name,omitempty"`
+	Locale     *string           `json:"locale,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
 type UsersResponse struct {
 	Users []UserResponse `json:"users"`
 	Count int            `json:"count"`
@@ -26,11 +36,13 @@ type UsersResponse struct {
 
 func ToUserResponse(user *model.User) UserResponse {
 	return UserResponse{
-		ID:        user.ID().Value(),
-		Name:      user.Name(),
-		Email:     user.Email().Value(),
-		CreatedAt: user.CreatedAt(),
-		UpdatedAt: user.UpdatedAt(),
+		ID:         user.ID().Value(),
+		Name:       user.Name(),
+		Email:      user.Email().Value(),
+		Locale:     user.Locale(),
+		Attributes: user.Attributes(),
+		CreatedAt:  user.CreatedAt(),
+		UpdatedAt:  user.UpdatedAt(),
 	}
 }
 
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+// UserSubscriptionResponse describes one of a user's browsers. The endpoint
+// itself is a capability and is not returned.
+type UserSubscriptionResponse struct {
+	ID             string     `json:"id"`
+	PushService    string     `json:"push_service"`
+	UserAgent      string     `json:"user_agent,omitempty"`
+	ExpirationTime *time.Time `json:"expiration_time,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type UserSubscriptionsResponse struct {
+	Subscriptions []UserSubscriptionResponse `json:"subscriptions"`
+	Count         int                        `json:"count"`
+}
+
+func ToUserSubscriptionsResponse(subscriptions []*model.PushSubscription) UserSubscriptionsResponse {
+	responses := make([]UserSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		responses[i] = UserSubscriptionResponse{
+			ID:             subscription.ID().String(),
+			PushService:    subscription.Endpoint().Host(),
+			UserAgent:      subscription.UserAgent(),
+			ExpirationTime: subscription.ExpirationTime(),
+			CreatedAt:      subscription.CreatedAt(),
+			UpdatedAt:      subscription.UpdatedAt(),
+		}
+	}
+
+	return UserSubscriptionsResponse{
+		Subscriptions: responses,
+		Count:         len(responses),
+	}
+}
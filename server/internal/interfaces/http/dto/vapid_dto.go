@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+)
+
+type GenerateVAPIDKeyRequest struct {
+	Activate bool `json:"activate,omitempty"`
+	// Subject overrides the server's VAPID subject for this key.
+	Subject string `json:"subject,omitempty"`
+}
+
+// VAPIDKeyResponse never includes the private key.
+type VAPIDKeyResponse struct {
+	ID          string     `json:"id"`
+	PublicKey   string     `json:"publicKey"`
+	Subject     string     `json:"subject,omitempty"`
+	Active      bool       `json:"active"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ActivatedAt *time.Time `json:"activatedAt,omitempty"`
+}
+
+type VAPIDKeysResponse struct {
+	Keys  []VAPIDKeyResponse `json:"keys"`
+	Count int                `json:"count"`
+}
+
+func ToVAPIDKeyResponse(key *model.VAPIDKey) VAPIDKeyResponse {
+	return VAPIDKeyResponse{
+		ID:          strconv.FormatInt(key.ID(), 10),
+		PublicKey:   key.PublicKey(),
+		Subject:     key.Subject().Value(),
+		Active:      key.IsActive(),
+		CreatedAt:   key.CreatedAt(),
+		ActivatedAt: key.ActivatedAt(),
+	}
+}
+
+func ToVAPIDKeysResponse(keys []*model.VAPIDKey) VAPIDKeysResponse {
+	responses := make([]VAPIDKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = ToVAPIDKeyResponse(key)
+	}
+
+	return VAPIDKeysResponse{
+		Keys:  responses,
+		Count: len(responses),
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/dto"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type NotificationEventHandler struct {
+	eventUseCase *usecase.NotificationEventUseCase
+}
+
+func NewNotificationEventHandler(eventUseCase *usecase.NotificationEventUseCase) *NotificationEventHandler {
+	return &NotificationEventHandler{
+		eventUseCase: eventUseCase,
+	}
+}
+
+// RecordEvent is called by the Service Worker, so it answers 204 without a
+// body on success.
+func (h *NotificationEventHandler) RecordEvent(w http.ResponseWriter, r *http.Request) {
+	var req dto.ClickTrackingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	jobID, err := valueobject.JobIDFromString(req.JobID)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	eventType := model.NotificationEventType(req.Event)
+	if req.Event == "" {
+		eventType = model.NotificationEventClicked
+	}
+
+	useCaseReq := usecase.RecordNotificationEventRequest{
+		Type:  eventType,
+		JobID: jobID,
+		URL:   req.URL,
+	}
+	if req.SubscriptionID != "" {
+		subscriptionID, err := valueobject.PublicSubscriptionIDFromString(req.SubscriptionID)
+		if err != nil {
+			http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
+			return
+		}
+		useCaseReq.SubscriptionID = &subscriptionID
+	}
+	if req.Timestamp > 0 {
+		useCaseReq.OccurredAt = time.UnixMilli(req.Timestamp)
+	}
+
+	if err := h.eventUseCase.RecordEvent(r.Context(), useCaseReq); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationEventHandler) GetEngagement(w http.ResponseWriter, r *http.Request) {
+	jobID, err := valueobject.JobIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	engagement, err := h.eventUseCase.GetEngagement(r.Context(), jobID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response := dto.ToJobEngagementResponse(engagement.JobID, engagement.Sent, engagement.Counts, engagement.DisplayRate, engagement.ClickThroughRate)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *NotificationEventHandler) handleError(w http.ResponseWriter, err error) {
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var statusCode int
+	switch domainErr.Code {
+	case errors.ErrJobNotFound.Code, errors.ErrSubscriptionNotFound.Code:
+		statusCode = http.StatusNotFound
+	case errors.ErrInvalidNotificationEvent.Code:
+		statusCode = http.StatusBadRequest
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": domainErr.Message,
+		"code":  domainErr.Code,
+	})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/dto"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type NotificationPrefsHandler struct {
+	prefsUseCase *usecase.NotificationPrefsUseCase
+}
+
+func NewNotificationPrefsHandler(prefsUseCase *usecase.NotificationPrefsUseCase) *NotificationPrefsHandler {
+	return &NotificationPrefsHandler{
+		prefsUseCase: prefsUseCase,
+	}
+}
+
+func (h *NotificationPrefsHandler) GetPrefs(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	prefs, err := h.prefsUseCase.GetPrefs(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToNotificationPrefsResponse(prefs))
+}
+
+func (h *NotificationPrefsHandler) UpdatePrefs(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.UpdateNotificationPrefsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	input := usecase.UpdateNotificationPrefsInput{
+		Enabled: req.Enabled == nil || *req.Enabled,
+		Topics:  req.Topics,
+	}
+	if req.QuietHours != nil {
+		input.QuietHours = &usecase.QuietHoursInput{
+			Start:    req.QuietHours.Start,
+			End:      req.QuietHours.End,
+			Timezone: req.QuietHours.Timezone,
+		}
+	}
+
+	prefs, err := h.prefsUseCase.UpdatePrefs(r.Context(), id, input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToNotificationPrefsResponse(prefs))
+}
+
+func (h *NotificationPrefsHandler) handleError(w http.ResponseWriter, err error) {
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var statusCode int
+	switch domainErr.Code {
+	case errors.ErrUserNotFound.Code:
+		statusCode = http.StatusNotFound
+	case errors.ErrInvalidUserID.Code, errors.ErrInvalidQuietHours.Code:
+		statusCode = http.StatusBadRequest
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": domainErr.Message,
+		"code":  domainErr.Code,
+	})
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/dto"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type NotificationTemplateHandler struct {
+	templateUseCase *usecase.NotificationTemplateUseCase
+}
+
+func NewNotificationTemplateHandler(templateUseCase *usecase.NotificationTemplateUseCase) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		templateUseCase: templateUseCase,
+	}
+}
+
+func (h *NotificationTemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.templateUseCase.ListTemplates(r.Context())
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToNotificationTemplatesResponse(templates))
+}
+
+func (h *NotificationTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := h.templateUseCase.GetTemplate(r.Context(), r.PathValue("key"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToNotificationTemplateResponse(template))
+}
+
+func (h *NotificationTemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req dto.NotificationTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Key == "" {
+		http.Error(w, "Template key is required", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.templateUseCase.CreateTemplate(r.Context(), req.Key, toTemplateInput(req))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.ToNotificationTemplateResponse(template))
+}
+
+func (h *NotificationTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req dto.NotificationTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.templateUseCase.UpdateTemplate(r.Context(), r.PathValue("key"), toTemplateInput(req))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToNotificationTemplateResponse(template))
+}
+
+func (h *NotificationTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := h.templateUseCase.DeleteTemplate(r.Context(), r.PathValue("key")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toTemplateInput(req dto.NotificationTemplateRequest) usecase.NotificationTemplateInput {
+	return usecase.NotificationTemplateInput{
+		Title: req.Title,
+		Body:  req.Body,
+		URL:   req.URL,
+		Icon:  req.Icon,
+		Data:  req.Data,
+	}
+}
+
+func (h *NotificationTemplateHandler) handleError(w http.ResponseWriter, err error) {
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var statusCode int
+	switch domainErr.Code {
+	case errors.ErrTemplateNotFound.Code:
+		statusCode = http.StatusNotFound
+	case errors.ErrTemplateExists.Code, errors.ErrTemplateInUse.Code:
+		statusCode = http.StatusConflict
+	case errors.ErrInvalidTemplate.Code:
+		statusCode = http.StatusBadRequest
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": domainErr.Message,
+		"code":  domainErr.Code,
+	})
+}
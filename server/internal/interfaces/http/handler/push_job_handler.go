@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/dto"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type PushJobHandler struct {
+	jobUseCase *usecase.PushJobUseCase
+}
+
+func NewPushJobHandler(jobUseCase *usecase.PushJobUseCase) *PushJobHandler {
+	return &PushJobHandler{
+		jobUseCase: jobUseCase,
+	}
+}
+
+func (h *PushJobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := valueobject.JobIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	details, err := h.jobUseCase.GetJob(r.Context(), jobID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response := dto.ToPushJobDetailsResponse(details.Job, details.Deliveries, details.SucceededAttempts, details.FailedAttempts)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListJobs supports ?status=, ?userId=, ?topic=, ?limit= and ?cursor= (the
+// nextCursor of the previous page).
+func (h *PushJobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := usecase.ListPushJobsRequest{
+		Status: model.JobStatus(query.Get("status")),
+		Topic:  query.Get("topic"),
+	}
+
+	if req.Status != "" && !req.Status.IsValid() {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	if userIDStr := query.Get("userId"); userIDStr != "" {
+		userID, err := valueobject.UserIDFromString(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		req.UserID = &userID
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		jobID, err := valueobject.JobIDFromString(cursor)
+		if err != nil {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		req.Cursor = &jobID
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		req.Limit = limit
+	}
+
+	result, err := h.jobUseCase.ListJobs(r.Context(), req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToPushJobsResponse(result.Jobs, result.NextCursor))
+}
+
+func (h *PushJobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := valueobject.JobIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobUseCase.CancelJob(r.Context(), jobID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToPushJobResponse(job))
+}
+
+func (h *PushJobHandler) handleError(w http.ResponseWriter, err error) {
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var statusCode int
+	switch domainErr.Code {
+	case errors.ErrJobNotFound.Code:
+		statusCode = http.StatusNotFound
+	case errors.ErrJobNotCancellable.Code:
+		statusCode = http.StatusConflict
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": domainErr.Message,
+		"code":  domainErr.Code,
+	})
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/dto"
+)
+
+type PushLogHandler struct {
+	logUseCase *usecase.PushLogUseCase
+}
+
+func NewPushLogHandler(logUseCase *usecase.PushLogUseCase) *PushLogHandler {
+	return &PushLogHandler{
+		logUseCase: logUseCase,
+	}
+}
+
+func (h *PushLogHandler) JobLogs(w http.ResponseWriter, r *http.Request) {
+	jobID, err := valueobject.JobIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	req, err := parseLogQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.JobID = &jobID
+
+	h.writeLogs(w, r, req)
+}
+
+// SubscriptionLogs accepts the public subscription ID clients see as well as
+// the internal ID reported in log entries.
+func (h *PushLogHandler) SubscriptionLogs(w http.ResponseWriter, r *http.Request) {
+	req, err := parseLogQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if publicID, err := valueobject.PublicSubscriptionIDFromString(r.PathValue("id")); err == nil {
+		req.PublicSubscriptionID = &publicID
+	} else {
+		subscriptionID, err := valueobject.SubscriptionIDFromString(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
+			return
+		}
+		req.SubscriptionID = &subscriptionID
+	}
+
+	h.writeLogs(w, r, req)
+}
+
+func (h *PushLogHandler) writeLogs(w http.ResponseWriter, r *http.Request, req usecase.QueryPushLogsRequest) {
+	result, err := h.logUseCase.QueryLogs(r.Context(), req)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToPushLogsResponse(result.Logs, result.NextCursor))
+}
+
+// parseLogQuery reads ?from= and ?to= (RFC 3339), ?cursor= and ?limit=.
+func parseLogQuery(query url.Values) (usecase.QueryPushLogsRequest, error) {
+	var req usecase.QueryPushLogsRequest
+
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"from", &req.Since},
+		{"to", &req.Until},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return req, fmt.Errorf("%s must be an RFC 3339 timestamp", param.name)
+		}
+		*param.target = &t
+	}
+	if req.Since != nil && req.Until != nil && !req.Since.Before(*req.Until) {
+		return req, fmt.Errorf("from must be before to")
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		value, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || value <= 0 {
+			return req, fmt.Errorf("invalid cursor")
+		}
+		req.Cursor = value
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return req, fmt.Errorf("invalid limit")
+		}
+		req.Limit = limit
+	}
+
+	return req, nil
+}
@@ -37,6 +37,12 @@ func (pnh *PushNotificationHandler) SendNotification(w http.ResponseWriter, r *h
 		userID = &parsedUserID
 	}
 
+	segment, err := parseSegment(req.Segment)
+	if err != nil {
+		http.Error(w, "Invalid segment: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	urgency := model.Urgency(req.Urgency)
 	if urgency == "" {
 		urgency = model.UrgencyNormal
@@ -54,6 +60,9 @@ func (pnh *PushNotificationHandler) SendNotification(w http.ResponseWriter, r *h
 		Urgency:        urgency,
 		TTLSeconds:     ttl,
 		Payload:        req.Payload,
+		TemplateKey:    req.TemplateKey,
+		TemplateVars:   req.Variables,
+		Segment:        segment,
 		ScheduleAt:     req.ScheduleAt,
 	}
 
@@ -86,7 +95,12 @@ func (pnh *PushNotificationHandler) SendBatchNotification(w http.ResponseWriter,
 		return
 	}
 
-	var userIDs []valueobject.UserID
+	if len(req.UserIDs) == 0 {
+		http.Error(w, "userIds is required", http.StatusBadRequest)
+		return
+	}
+
+	userIDs := make([]valueobject.UserID, 0, len(req.UserIDs))
 	for _, userIDStr := range req.UserIDs {
 		userID, err := valueobject.UserIDFromString(userIDStr)
 		if err != nil {
@@ -112,6 +126,8 @@ func (pnh *PushNotificationHandler) SendBatchNotification(w http.ResponseWriter,
 		Urgency:        urgency,
 		TTLSeconds:     ttl,
 		Payload:        req.Payload,
+		TemplateKey:    req.TemplateKey,
+		TemplateVars:   req.Variables,
 		ScheduleAt:     req.ScheduleAt,
 		IdempotencyKey: req.IdempotencyKey,
 	}
@@ -122,15 +138,20 @@ func (pnh *PushNotificationHandler) SendBatchNotification(w http.ResponseWriter,
 		return
 	}
 
-	jobIDStrings := make([]string, len(result.JobIDs))
-	for i, jobID := range result.JobIDs {
-		jobIDStrings[i] = jobID.String()
-	}
-
 	response := dto.SendBatchNotificationResponse{
-		JobIDs:  jobIDStrings,
-		Success: result.Success,
-		Message: result.Message,
+		Recipients: result.Recipients,
+		Skipped:    make([]dto.SkippedRecipientResponse, len(result.Skipped)),
+		Success:    result.Success,
+		Message:    result.Message,
+	}
+	if result.Success {
+		response.JobID = result.JobID.String()
+	}
+	for i, skipped := range result.Skipped {
+		response.Skipped[i] = dto.SkippedRecipientResponse{
+			UserID: skipped.UserID.String(),
+			Reason: string(skipped.Reason),
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -142,3 +163,75 @@ func (pnh *PushNotificationHandler) SendBatchNotification(w http.ResponseWriter,
 
 	json.NewEncoder(w).Encode(response)
 }
+
+// PreviewAudience takes the body of POST /api/push/send and reports how many
+// subscriptions the job would reach if it were sent now, without creating it.
+func (pnh *PushNotificationHandler) PreviewAudience(w http.ResponseWriter, r *http.Request) {
+	var req dto.SendNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var userID *valueobject.UserID
+	if req.UserID != nil && *req.UserID != "" {
+		parsedUserID, err := valueobject.UserIDFromString(*req.UserID)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		userID = &parsedUserID
+	}
+
+	segment, err := parseSegment(req.Segment)
+	if err != nil {
+		http.Error(w, "Invalid segment: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := pnh.notificationUseCase.PreviewAudience(r.Context(), usecase.PreviewAudienceRequest{
+		UserID:  userID,
+		Segment: segment,
+		Topic:   req.Topic,
+	})
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := dto.PreviewAudienceResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}
+	if result.Estimate != nil {
+		response.Audience = &dto.AudienceResponse{
+			Subscriptions: result.Estimate.Subscriptions,
+			Users:         result.Estimate.Users,
+			Anonymous:     result.Estimate.Anonymous,
+			OptedOut:      result.Estimate.OptedOut,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !result.Success {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func parseSegment(req *dto.SegmentDTO) (*model.Segment, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	browsers := make([]valueobject.Browser, len(req.Browsers))
+	for i, value := range req.Browsers {
+		browser, err := valueobject.NewBrowser(value)
+		if err != nil {
+			return nil, err
+		}
+		browsers[i] = browser
+	}
+
+	return model.NewSegment(req.Topics, req.Attributes, browsers, req.Locales, req.ActiveWithinDays, req.InactiveForDays)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/dto"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+type PushScheduleHandler struct {
+	scheduleUseCase *usecase.PushScheduleUseCase
+}
+
+func NewPushScheduleHandler(scheduleUseCase *usecase.PushScheduleUseCase) *PushScheduleHandler {
+	return &PushScheduleHandler{
+		scheduleUseCase: scheduleUseCase,
+	}
+}
+
+func (h *PushScheduleHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.scheduleUseCase.ListSchedules(r.Context())
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToPushSchedulesResponse(schedules))
+}
+
+func (h *PushScheduleHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID, err := valueobject.ScheduleIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.scheduleUseCase.GetSchedule(r.Context(), scheduleID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToPushScheduleResponse(schedule))
+}
+
+func (h *PushScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreatePushScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var userID *valueobject.UserID
+	if req.UserID != nil && *req.UserID != "" {
+		parsedUserID, err := valueobject.UserIDFromString(*req.UserID)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		userID = &parsedUserID
+	}
+
+	segment, err := parseSegment(req.Segment)
+	if err != nil {
+		http.Error(w, "Invalid segment: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.scheduleUseCase.CreateSchedule(r.Context(), usecase.CreatePushScheduleRequest{
+		Name:         req.Name,
+		Cron:         req.Cron,
+		Timezone:     req.Timezone,
+		UserID:       userID,
+		Topic:        req.Topic,
+		Urgency:      model.Urgency(req.Urgency),
+		TTLSeconds:   req.TTL,
+		Payload:      req.Payload,
+		TemplateKey:  req.TemplateKey,
+		TemplateVars: req.Variables,
+		Segment:      segment,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.ToPushScheduleResponse(schedule))
+}
+
+func (h *PushScheduleHandler) PauseSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID, err := valueobject.ScheduleIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.scheduleUseCase.PauseSchedule(r.Context(), scheduleID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToPushScheduleResponse(schedule))
+}
+
+func (h *PushScheduleHandler) ResumeSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID, err := valueobject.ScheduleIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.scheduleUseCase.ResumeSchedule(r.Context(), scheduleID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToPushScheduleResponse(schedule))
+}
+
+func (h *PushScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID, err := valueobject.ScheduleIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.scheduleUseCase.DeleteSchedule(r.Context(), scheduleID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PushScheduleHandler) handleError(w http.ResponseWriter, err error) {
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var statusCode int
+	switch domainErr.Code {
+	case errors.ErrScheduleNotFound.Code:
+		statusCode = http.StatusNotFound
+	case errors.ErrInvalidSchedule.Code:
+		statusCode = http.StatusBadRequest
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": domainErr.Message,
+		"code":  domainErr.Code,
+	})
+}
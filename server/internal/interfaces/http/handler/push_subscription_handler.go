@@ -7,6 +7,8 @@ import (
 	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
 	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
 	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/dto"
+	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/middleware"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
 )
 
 type PushSubscriptionHandler struct {
@@ -26,16 +28,14 @@ func (psh *PushSubscriptionHandler) Subscribe(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// TODO: Extract user ID from authentication context if needed
-	var userID *valueobject.UserID
-
 	useCaseReq := usecase.SubscribePushRequest{
-		UserID:         userID,
+		UserID:         middleware.UserIDFromContext(r.Context()),
 		Endpoint:       req.Endpoint,
 		P256dhKey:      req.Keys.P256dh,
 		AuthKey:        req.Keys.Auth,
 		UserAgent:      req.UserAgent,
 		ExpirationTime: req.ExpirationTime,
+		VAPIDPublicKey: req.ApplicationServerKey,
 	}
 
 	result, err := psh.subscriptionUseCase.Subscribe(r.Context(), useCaseReq)
@@ -67,19 +67,30 @@ func (psh *PushSubscriptionHandler) Unsubscribe(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	subscriptionID, err := valueobject.SubscriptionIDFromString(subscriptionIDStr)
+	subscriptionID, err := valueobject.PublicSubscriptionIDFromString(subscriptionIDStr)
 	if err != nil {
 		http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
 		return
 	}
 
+	var req dto.UnsubscribeRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
 	useCaseReq := usecase.UnsubscribePushRequest{
 		SubscriptionID: subscriptionID,
+		UserID:         middleware.UserIDFromContext(r.Context()),
+		Endpoint:       req.Endpoint,
+		AuthSecret:     req.Auth,
 	}
 
 	result, err := psh.subscriptionUseCase.Unsubscribe(r.Context(), useCaseReq)
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		psh.handleError(w, err)
 		return
 	}
 
@@ -89,11 +100,68 @@ func (psh *PushSubscriptionHandler) Unsubscribe(w http.ResponseWriter, r *http.R
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if result.Success {
-		w.WriteHeader(http.StatusOK)
-	} else {
-		w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (psh *PushSubscriptionHandler) ListUserSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, err := valueobject.UserIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
 	}
 
-	json.NewEncoder(w).Encode(response)
+	subscriptions, err := psh.subscriptionUseCase.ListUserSubscriptions(r.Context(), userID)
+	if err != nil {
+		psh.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToUserSubscriptionsResponse(subscriptions))
+}
+
+func (psh *PushSubscriptionHandler) RevokeUserSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, err := valueobject.UserIDFromString(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	subscriptionID, err := valueobject.PublicSubscriptionIDFromString(r.PathValue("subscriptionId"))
+	if err != nil {
+		http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := psh.subscriptionUseCase.RevokeUserSubscription(r.Context(), userID, subscriptionID); err != nil {
+		psh.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (psh *PushSubscriptionHandler) handleError(w http.ResponseWriter, err error) {
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var statusCode int
+	switch domainErr.Code {
+	case errors.ErrUserNotFound.Code, errors.ErrSubscriptionNotFound.Code:
+		statusCode = http.StatusNotFound
+	case errors.ErrSubscriptionOwnership.Code:
+		statusCode = http.StatusForbidden
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": domainErr.Message,
+		"code":  domainErr.Code,
+	})
 }
@@ -75,6 +75,35 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userUseCase.UpdateUser(r.Context(), id, usecase.UpdateUserRequest{
+		Name:       req.Name,
+		Locale:     req.Locale,
+		Attributes: req.Attributes,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response := dto.ToUserResponse(user)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
@@ -104,7 +133,7 @@ func (h *UserHandler) handleError(w http.ResponseWriter, err error) {
 		statusCode = http.StatusNotFound
 	case errors.ErrEmailAlreadyExist.Code:
 		statusCode = http.StatusConflict
-	case errors.ErrInvalidUserID.Code, errors.ErrInvalidEmail.Code:
+	case errors.ErrInvalidUserID.Code, errors.ErrInvalidEmail.Code, errors.ErrInvalidUserProfile.Code:
 		statusCode = http.StatusBadRequest
 	default:
 		statusCode = http.StatusInternalServerError
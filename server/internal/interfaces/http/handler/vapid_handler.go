@@ -3,9 +3,11 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/K-Kizuku/kotti-he-oide/internal/application/usecase"
 	"github.com/K-Kizuku/kotti-he-oide/internal/interfaces/http/dto"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
 )
 
 type VAPIDHandler struct {
@@ -19,7 +21,11 @@ func NewVAPIDHandler(vapidUseCase *usecase.VAPIDUseCase) *VAPIDHandler {
 }
 
 func (vh *VAPIDHandler) GetPublicKey(w http.ResponseWriter, r *http.Request) {
-	result := vh.vapidUseCase.GetPublicKey()
+	result, err := vh.vapidUseCase.GetPublicKey(r.Context())
+	if err != nil {
+		vh.handleError(w, err)
+		return
+	}
 
 	response := dto.VAPIDPublicKeyResponse{
 		PublicKey: result.PublicKey,
@@ -32,3 +38,78 @@ func (vh *VAPIDHandler) GetPublicKey(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(response)
 }
+
+func (vh *VAPIDHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := vh.vapidUseCase.ListKeys(r.Context())
+	if err != nil {
+		vh.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToVAPIDKeysResponse(keys))
+}
+
+func (vh *VAPIDHandler) GenerateKey(w http.ResponseWriter, r *http.Request) {
+	var req dto.GenerateVAPIDKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	key, err := vh.vapidUseCase.GenerateKey(r.Context(), req.Activate, req.Subject)
+	if err != nil {
+		vh.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.ToVAPIDKeyResponse(key))
+}
+
+func (vh *VAPIDHandler) ActivateKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid VAPID key ID", http.StatusBadRequest)
+		return
+	}
+
+	key, err := vh.vapidUseCase.ActivateKey(r.Context(), id)
+	if err != nil {
+		vh.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ToVAPIDKeyResponse(key))
+}
+
+func (vh *VAPIDHandler) handleError(w http.ResponseWriter, err error) {
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var statusCode int
+	switch domainErr.Code {
+	case errors.ErrVAPIDKeyNotFound.Code:
+		statusCode = http.StatusNotFound
+	case errors.ErrInvalidVAPIDSubject.Code:
+		statusCode = http.StatusBadRequest
+	case errors.ErrNoActiveVAPIDKey.Code:
+		statusCode = http.StatusServiceUnavailable
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": domainErr.Message,
+		"code":  domainErr.Code,
+	})
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	stderrors "errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/valueobject"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+// Principal is the authenticated caller of a request. Exactly one of APIKey
+// (server-to-server) and UserID (browser session) is set.
+type Principal struct {
+	APIKey *model.APIKey
+	UserID *valueobject.UserID
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the caller attached by an Authenticator
+// middleware, or nil for anonymous requests.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// UserIDFromContext returns the signed-in user, or nil.
+func UserIDFromContext(ctx context.Context) *valueobject.UserID {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.UserID
+	}
+	return nil
+}
+
+// APIKeyAuthenticator resolves bearer secrets to API keys. It returns
+// errors.ErrUnauthenticated for unknown or revoked keys.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (*model.APIKey, error)
+}
+
+// Authenticator accepts two kinds of bearer tokens: API keys for
+// server-to-server callers and HS256 JWTs whose "sub" is a user ID for
+// browser sessions.
+type Authenticator struct {
+	apiKeys   APIKeyAuthenticator
+	jwtSecret []byte
+}
+
+// NewAuthenticator creates an Authenticator. An empty jwtSecret rejects
+// every user token.
+func NewAuthenticator(apiKeys APIKeyAuthenticator, jwtSecret string) *Authenticator {
+	return &Authenticator{
+		apiKeys:   apiKeys,
+		jwtSecret: []byte(jwtSecret),
+	}
+}
+
+var errInvalidCredentials = stderrors.New("invalid credentials")
+
+// RequireScope only lets through API keys granting scope.
+func (a *Authenticator) RequireScope(scope model.APIKeyScope, next http.HandlerFunc) http.HandlerFunc {
+	return a.guard(next, func(r *http.Request, principal *Principal) bool {
+		return principal.APIKey != nil && principal.APIKey.HasScope(scope)
+	})
+}
+
+// RequireUser only lets through signed-in users.
+func (a *Authenticator) RequireUser(next http.HandlerFunc) http.HandlerFunc {
+	return a.guard(next, func(r *http.Request, principal *Principal) bool {
+		return principal.UserID != nil
+	})
+}
+
+// RequireSelfOrScope lets through the user named by the {id} path value, or
+// API keys granting scope.
+func (a *Authenticator) RequireSelfOrScope(scope model.APIKeyScope, next http.HandlerFunc) http.HandlerFunc {
+	return a.guard(next, func(r *http.Request, principal *Principal) bool {
+		if principal.APIKey != nil {
+			return principal.APIKey.HasScope(scope)
+		}
+		return principal.UserID != nil && principal.UserID.String() == r.PathValue("id")
+	})
+}
+
+// OptionalUser attaches the signed-in user when a token is sent and serves
+// anonymous requests as before. Invalid tokens are still rejected.
+func (a *Authenticator) OptionalUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			next(w, r)
+			return
+		}
+		if !looksLikeJWT(token) {
+			unauthorized(w)
+			return
+		}
+		userID, err := a.verifyUserToken(token)
+		if err != nil {
+			unauthorized(w)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, &Principal{UserID: &userID})))
+	}
+}
+
+func (a *Authenticator) guard(next http.HandlerFunc, allowed func(r *http.Request, principal *Principal) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.authenticate(r)
+		if stderrors.Is(err, errInvalidCredentials) {
+			unauthorized(w)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to authenticate request: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !allowed(r, principal) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	}
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (*Principal, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, errInvalidCredentials
+	}
+
+	if looksLikeJWT(token) {
+		userID, err := a.verifyUserToken(token)
+		if err != nil {
+			return nil, errInvalidCredentials
+		}
+		return &Principal{UserID: &userID}, nil
+	}
+
+	key, err := a.apiKeys.Authenticate(r.Context(), token)
+	if stderrors.Is(err, errors.ErrUnauthenticated) {
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{APIKey: key}, nil
+}
+
+func (a *Authenticator) verifyUserToken(token string) (valueobject.UserID, error) {
+	if len(a.jwtSecret) == 0 {
+		return valueobject.UserID{}, errInvalidCredentials
+	}
+
+	parsed, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, func(*jwt.Token) (any, error) {
+		return a.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return valueobject.UserID{}, err
+	}
+
+	subject, err := parsed.Claims.GetSubject()
+	if err != nil {
+		return valueobject.UserID{}, err
+	}
+	return valueobject.UserIDFromString(subject)
+}
+
+// looksLikeJWT tells user tokens (header.payload.signature) apart from API
+// keys, which never contain dots.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/K-Kizuku/kotti-he-oide/internal/domain/model"
+	"github.com/K-Kizuku/kotti-he-oide/pkg/errors"
+)
+
+const testJWTSecret = "test-secret"
+
+type fakeAPIKeys map[string]*model.APIKey
+
+func (f fakeAPIKeys) Authenticate(ctx context.Context, secret string) (*model.APIKey, error) {
+	key, ok := f[secret]
+	if !ok {
+		return nil, errors.ErrUnauthenticated
+	}
+	if key.IsRevoked() {
+		// Wrapped like a use case adding context would.
+		return nil, fmt.Errorf("api key %d is revoked: %w", key.ID(), errors.ErrUnauthenticated)
+	}
+	return key, nil
+}
+
+func newTestAPIKey(t *testing.T, secret string, scopes ...model.APIKeyScope) *model.APIKey {
+	t.Helper()
+	key, err := model.NewAPIKey(1, "test", secret, scopes)
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+	return key
+}
+
+func userToken(t *testing.T, secret, subject string, expiresAt time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   subject,
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return token
+}
+
+func TestAuthenticatorRequireScope(t *testing.T) {
+	revoked := newTestAPIKey(t, "khk_revoked-secret", model.APIKeyScopeSend)
+	revoked.Revoke()
+	auth := NewAuthenticator(fakeAPIKeys{
+		"khk_send-secret":    newTestAPIKey(t, "khk_send-secret", model.APIKeyScopeSend),
+		"khk_admin-secret":   newTestAPIKey(t, "khk_admin-secret", model.APIKeyScopeAdmin),
+		"khk_revoked-secret": revoked,
+	}, testJWTSecret)
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		if PrincipalFromContext(r.Context()) == nil {
+			t.Error("principal missing from context")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name   string
+		scope  model.APIKeyScope
+		header string
+		want   int
+	}{
+		{"scoped key", model.APIKeyScopeSend, "Bearer khk_send-secret", http.StatusNoContent},
+		{"scheme is case-insensitive", model.APIKeyScopeSend, "bearer khk_send-secret", http.StatusNoContent},
+		{"admin grants every scope", model.APIKeyScopeReadLogs, "Bearer khk_admin-secret", http.StatusNoContent},
+		{"missing scope", model.APIKeyScopeReadLogs, "Bearer khk_send-secret", http.StatusForbidden},
+		{"revoked key", model.APIKeyScopeSend, "Bearer khk_revoked-secret", http.StatusUnauthorized},
+		{"unknown key", model.APIKeyScopeSend, "Bearer khk_nope", http.StatusUnauthorized},
+		{"missing header", model.APIKeyScopeSend, "", http.StatusUnauthorized},
+		{"basic auth", model.APIKeyScopeSend, "Basic khk_send-secret", http.StatusUnauthorized},
+		{"user token", model.APIKeyScopeSend, "Bearer " + userToken(t, testJWTSecret, "1", time.Now().Add(time.Hour)), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/push/send", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			auth.RequireScope(tt.scope, ok)(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorUserTokens(t *testing.T) {
+	auth := NewAuthenticator(fakeAPIKeys{
+		"khk_admin-secret": newTestAPIKey(t, "khk_admin-secret", model.APIKeyScopeAdmin),
+	}, testJWTSecret)
+	valid := userToken(t, testJWTSecret, "7", time.Now().Add(time.Hour))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/users/{id}", auth.RequireSelfOrScope(model.APIKeyScopeAdmin, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc("POST /api/push/subscribe", auth.OptionalUser(func(w http.ResponseWriter, r *http.Request) {
+		if userID := UserIDFromContext(r.Context()); userID != nil {
+			w.Header().Set("X-User", userID.String())
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		want     int
+		wantUser string
+	}{
+		{"own user", http.MethodGet, "/api/users/7", valid, http.StatusNoContent, ""},
+		{"other user", http.MethodGet, "/api/users/8", valid, http.StatusForbidden, ""},
+		{"admin key", http.MethodGet, "/api/users/8", "khk_admin-secret", http.StatusNoContent, ""},
+		{"expired token", http.MethodGet, "/api/users/7", userToken(t, testJWTSecret, "7", time.Now().Add(-time.Minute)), http.StatusUnauthorized, ""},
+		{"wrong secret", http.MethodGet, "/api/users/7", userToken(t, "other", "7", time.Now().Add(time.Hour)), http.StatusUnauthorized, ""},
+		{"anonymous subscribe", http.MethodPost, "/api/push/subscribe", "", http.StatusNoContent, ""},
+		{"signed-in subscribe", http.MethodPost, "/api/push/subscribe", valid, http.StatusNoContent, "7"},
+		{"invalid token on subscribe", http.MethodPost, "/api/push/subscribe", "not.a.jwt", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Header().Get("X-User"); got != tt.wantUser {
+				t.Errorf("user = %q, want %q", got, tt.wantUser)
+			}
+		})
+	}
+}
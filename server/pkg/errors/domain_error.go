@@ -31,8 +31,30 @@ func WrapDomainError(code, message string, cause error) *DomainError {
 }
 
 var (
-	ErrUserNotFound      = NewDomainError("USER_NOT_FOUND", "User not found")
-	ErrEmailAlreadyExist = NewDomainError("EMAIL_ALREADY_EXISTS", "Email already exists")
-	ErrInvalidUserID     = NewDomainError("INVALID_USER_ID", "Invalid user ID")
-	ErrInvalidEmail      = NewDomainError("INVALID_EMAIL", "Invalid email format")
+	ErrUserNotFound             = NewDomainError("USER_NOT_FOUND", "User not found")
+	ErrEmailAlreadyExist        = NewDomainError("EMAIL_ALREADY_EXISTS", "Email already exists")
+	ErrInvalidUserID            = NewDomainError("INVALID_USER_ID", "Invalid user ID")
+	ErrInvalidEmail             = NewDomainError("INVALID_EMAIL", "Invalid email format")
+	ErrInvalidUserProfile       = NewDomainError("INVALID_USER_PROFILE", "Invalid user profile")
+	ErrJobLeaseLost             = NewDomainError("JOB_LEASE_LOST", "Push job lease is no longer held")
+	ErrDeliveryLeaseLost        = NewDomainError("DELIVERY_LEASE_LOST", "Push delivery lease is no longer held")
+	ErrVAPIDKeyNotFound         = NewDomainError("VAPID_KEY_NOT_FOUND", "VAPID key not found")
+	ErrNoActiveVAPIDKey         = NewDomainError("NO_ACTIVE_VAPID_KEY", "No active VAPID key")
+	ErrInvalidVAPIDSubject      = NewDomainError("INVALID_VAPID_SUBJECT", "VAPID subject must be a mailto: or https: URI")
+	ErrTemplateNotFound         = NewDomainError("TEMPLATE_NOT_FOUND", "Notification template not found")
+	ErrTemplateExists           = NewDomainError("TEMPLATE_ALREADY_EXISTS", "Notification template already exists")
+	ErrTemplateInUse            = NewDomainError("TEMPLATE_IN_USE", "Notification template is referenced by push jobs or schedules")
+	ErrInvalidTemplate          = NewDomainError("INVALID_TEMPLATE", "Invalid notification template")
+	ErrInvalidQuietHours        = NewDomainError("INVALID_QUIET_HOURS", "Invalid quiet hours")
+	ErrJobNotFound              = NewDomainError("JOB_NOT_FOUND", "Push job not found")
+	ErrJobNotCancellable        = NewDomainError("JOB_NOT_CANCELLABLE", "Push job is already being sent or has finished")
+	ErrDuplicateIdempotencyKey  = NewDomainError("DUPLICATE_IDEMPOTENCY_KEY", "A push job with this idempotency key already exists")
+	ErrInvalidNotificationEvent = NewDomainError("INVALID_NOTIFICATION_EVENT", "Invalid notification event")
+	ErrAPIKeyNotFound           = NewDomainError("API_KEY_NOT_FOUND", "API key not found")
+	ErrInvalidAPIKey            = NewDomainError("INVALID_API_KEY", "API keys need a name and at least one of the scopes send, read-logs, admin")
+	ErrUnauthenticated          = NewDomainError("UNAUTHENTICATED", "Missing or invalid credentials")
+	ErrSubscriptionNotFound     = NewDomainError("SUBSCRIPTION_NOT_FOUND", "Push subscription not found")
+	ErrSubscriptionOwnership    = NewDomainError("SUBSCRIPTION_OWNERSHIP_REQUIRED", "Unsubscribing requires the owner's session, the endpoint or the auth secret")
+	ErrScheduleNotFound         = NewDomainError("SCHEDULE_NOT_FOUND", "Push schedule not found")
+	ErrInvalidSchedule          = NewDomainError("INVALID_SCHEDULE", "Invalid push schedule")
 )